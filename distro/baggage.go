@@ -0,0 +1,170 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// Limits of the W3C Baggage specification
+// (https://www.w3.org/TR/baggage/#limits).
+const (
+	// MaxBaggageMembers is the maximum number of members of a baggage.
+	MaxBaggageMembers = 64
+	// MaxBaggageBytes is the maximum size in bytes of an encoded baggage.
+	MaxBaggageBytes = 8192
+)
+
+// TenantIDBaggageKey is the key of the baggage member holding the ID of the
+// tenant a request is handled for.
+const TenantIDBaggageKey = "tenant.id"
+
+var (
+	// ErrInvalidBaggage is returned by SetBaggage if the baggage member is
+	// not valid according to the W3C Baggage specification.
+	ErrInvalidBaggage = errors.New("invalid baggage member")
+	// ErrBaggageTooLarge is returned by SetBaggage if the baggage would
+	// exceed its size limits.
+	ErrBaggageTooLarge = errors.New("baggage too large")
+)
+
+type baggageConfig struct {
+	MaxBytes int
+	Drop     bool
+}
+
+// BaggageOption configures SetBaggage.
+type BaggageOption interface {
+	apply(*baggageConfig)
+}
+
+type baggageOptionFunc func(*baggageConfig)
+
+func (fn baggageOptionFunc) apply(c *baggageConfig) {
+	fn(c)
+}
+
+// WithMaxBaggageBytes returns a BaggageOption that limits the size of the
+// encoded baggage (i.e. the value of the baggage header) to n bytes. It is
+// useful if downstream services or proxies limit the size of the request
+// headers. The limit cannot exceed MaxBaggageBytes, a larger or non-positive
+// n is ignored.
+//
+// By default, the baggage is limited to MaxBaggageBytes.
+func WithMaxBaggageBytes(n int) BaggageOption {
+	return baggageOptionFunc(func(c *baggageConfig) {
+		if n > 0 && n < MaxBaggageBytes {
+			c.MaxBytes = n
+		}
+	})
+}
+
+// WithBaggageOverflowDrop returns a BaggageOption that makes SetBaggage drop
+// the member that would make the baggage exceed its size limits instead of
+// returning ErrBaggageTooLarge. The baggage of the returned context is left
+// unchanged in that case.
+func WithBaggageOverflowDrop() BaggageOption {
+	return baggageOptionFunc(func(c *baggageConfig) {
+		c.Drop = true
+	})
+}
+
+// SetBaggage returns a copy of ctx with the baggage member key=value set in
+// its baggage, replacing any member with the same key. The baggage is
+// propagated to all downstream services by the configured propagators.
+//
+// The key has to be a token as defined by RFC 7230 (e.g. "tenant.id"). The
+// value can only contain the baggage-octet characters of the W3C Baggage
+// specification: printable ASCII characters except space, '"', ',', ';', and
+// '\'. Other characters would have to be percent-encoded, which
+// OpenTelemetry implementations do not consistently decode (e.g. this SDK
+// drops such members when extracting the baggage). An error wrapping
+// ErrInvalidBaggage is returned if the member is not valid.
+//
+// The baggage is limited to MaxBaggageMembers members and, once encoded, to
+// MaxBaggageBytes bytes (see WithMaxBaggageBytes). An error wrapping
+// ErrBaggageTooLarge is returned if the member would make the baggage exceed
+// these limits, unless WithBaggageOverflowDrop is used. ctx is returned if an
+// error is returned or the member is dropped.
+func SetBaggage(ctx context.Context, key, value string, opts ...BaggageOption) (context.Context, error) {
+	c := baggageConfig{MaxBytes: MaxBaggageBytes}
+	for _, o := range opts {
+		o.apply(&c)
+	}
+
+	for _, r := range value {
+		if !baggageOctet(r) {
+			return ctx, fmt.Errorf("%w: value of %q contains %q", ErrInvalidBaggage, key, r)
+		}
+	}
+	// The value passed to NewMember has to be encoded, it is decoded with
+	// url.QueryUnescape.
+	m, err := baggage.NewMember(key, url.QueryEscape(value))
+	if err != nil {
+		return ctx, fmt.Errorf("%w: %v", ErrInvalidBaggage, err)
+	}
+
+	b, err := baggage.FromContext(ctx).SetMember(m)
+	if err != nil {
+		return ctx, fmt.Errorf("%w: %v", ErrInvalidBaggage, err)
+	}
+
+	if err := checkBaggageSize(b, c.MaxBytes); err != nil {
+		if c.Drop {
+			return ctx, nil
+		}
+		return ctx, err
+	}
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
+
+// baggageOctet returns true if r is a baggage-octet as defined by the W3C
+// Baggage specification.
+func baggageOctet(r rune) bool {
+	return r == 0x21 ||
+		(r >= 0x23 && r <= 0x2B) ||
+		(r >= 0x2D && r <= 0x3A) ||
+		(r >= 0x3C && r <= 0x5B) ||
+		(r >= 0x5D && r <= 0x7E)
+}
+
+// checkBaggageSize returns an error wrapping ErrBaggageTooLarge if b has more
+// than MaxBaggageMembers members or if it is larger than maxBytes once
+// encoded.
+func checkBaggageSize(b baggage.Baggage, maxBytes int) error {
+	if n := b.Len(); n > MaxBaggageMembers {
+		return fmt.Errorf("%w: %d members, the limit is %d", ErrBaggageTooLarge, n, MaxBaggageMembers)
+	}
+	if n := len(b.String()); n > maxBytes {
+		return fmt.Errorf("%w: %d bytes, the limit is %d", ErrBaggageTooLarge, n, maxBytes)
+	}
+	return nil
+}
+
+// BaggageValue returns the value of the baggage member of ctx with the key
+// and true, or an empty string and false if the baggage of ctx does not
+// have such a member.
+func BaggageValue(ctx context.Context, key string) (string, bool) {
+	m := baggage.FromContext(ctx).Member(key)
+	if m.Key() == "" {
+		return "", false
+	}
+	return m.Value(), true
+}
@@ -0,0 +1,187 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+func TestSetBaggage(t *testing.T) {
+	ctx, err := distro.SetBaggage(context.Background(), distro.TenantIDBaggageKey, "tenant-1")
+	require.NoError(t, err)
+	ctx, err = distro.SetBaggage(ctx, "other", "value")
+	require.NoError(t, err)
+
+	got, ok := distro.BaggageValue(ctx, distro.TenantIDBaggageKey)
+	assert.True(t, ok)
+	assert.Equal(t, "tenant-1", got)
+
+	// Replace the member.
+	ctx, err = distro.SetBaggage(ctx, distro.TenantIDBaggageKey, "tenant-2")
+	require.NoError(t, err)
+	got, ok = distro.BaggageValue(ctx, distro.TenantIDBaggageKey)
+	assert.True(t, ok)
+	assert.Equal(t, "tenant-2", got)
+	assert.Equal(t, 2, baggage.FromContext(ctx).Len())
+
+	got, ok = distro.BaggageValue(ctx, "missing")
+	assert.False(t, ok)
+	assert.Empty(t, got)
+}
+
+func TestSetBaggagePropagation(t *testing.T) {
+	values := []string{
+		"",
+		"plain",
+		"with+plus",
+		"percent%20sign",
+		"key=value",
+		"symbols!#$&'()*/:<>?@[]^_`{|}~",
+	}
+	for _, v := range values {
+		t.Run(v, func(t *testing.T) {
+			ctx, err := distro.SetBaggage(context.Background(), "key", v)
+			require.NoError(t, err)
+
+			// The value needs to be received as is by the downstream services.
+			header := http.Header{}
+			propagation.Baggage{}.Inject(ctx, propagation.HeaderCarrier(header))
+			ctx = propagation.Baggage{}.Extract(context.Background(), propagation.HeaderCarrier(header))
+
+			got, ok := distro.BaggageValue(ctx, "key")
+			assert.True(t, ok, "baggage header: %q", header.Get("baggage"))
+			assert.Equal(t, v, got)
+		})
+	}
+}
+
+func TestSetBaggageInvalid(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		key   string
+		value string
+	}{
+		{desc: "empty key", key: "", value: "value"},
+		{desc: "key with space", key: "tenant id", value: "value"},
+		{desc: "key with separator", key: "tenant,id", value: "value"},
+		{desc: "key with equal sign", key: "tenant=id", value: "value"},
+		{desc: "non-ASCII key", key: "ténant", value: "value"},
+		{desc: "value with space", key: "key", value: "a b"},
+		{desc: "value with comma", key: "key", value: "a,b"},
+		{desc: "value with semicolon", key: "key", value: "a;b"},
+		{desc: "value with double quote", key: "key", value: `a"b`},
+		{desc: "value with backslash", key: "key", value: `a\b`},
+		{desc: "value with control character", key: "key", value: "a\nb"},
+		{desc: "non-ASCII value", key: "key", value: "✓"},
+		{desc: "invalid UTF-8 value", key: "key", value: "\xff"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			got, err := distro.SetBaggage(ctx, tc.key, tc.value)
+			assert.ErrorIs(t, err, distro.ErrInvalidBaggage)
+			assert.Equal(t, ctx, got)
+		})
+	}
+}
+
+func TestSetBaggageTooLarge(t *testing.T) {
+	ctx := context.Background()
+	large := strings.Repeat("a", distro.MaxBaggageBytes)
+
+	got, err := distro.SetBaggage(ctx, "key", large)
+	assert.ErrorIs(t, err, distro.ErrBaggageTooLarge)
+	assert.Equal(t, ctx, got)
+
+	got, err = distro.SetBaggage(ctx, "key", large, distro.WithBaggageOverflowDrop())
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, got)
+}
+
+func TestSetBaggageTooLargeEncoded(t *testing.T) {
+	// The limit applies to the encoded baggage: each "%" is encoded as "%25".
+	value := strings.Repeat("%", 100)
+
+	_, err := distro.SetBaggage(context.Background(), "key", value, distro.WithMaxBaggageBytes(110))
+	assert.ErrorIs(t, err, distro.ErrBaggageTooLarge)
+
+	_, err = distro.SetBaggage(context.Background(), "key", value, distro.WithMaxBaggageBytes(304))
+	assert.NoError(t, err)
+}
+
+func TestSetBaggageMaxBytes(t *testing.T) {
+	opt := distro.WithMaxBaggageBytes(32)
+
+	ctx, err := distro.SetBaggage(context.Background(), distro.TenantIDBaggageKey, "tenant-1", opt)
+	require.NoError(t, err)
+
+	// The baggage already has tenant.id=tenant-1 (18 bytes).
+	_, err = distro.SetBaggage(ctx, "user", "1234567890", opt)
+	assert.ErrorIs(t, err, distro.ErrBaggageTooLarge)
+
+	got, err := distro.SetBaggage(ctx, "user", "1234567890", opt, distro.WithBaggageOverflowDrop())
+	require.NoError(t, err)
+	assert.Equal(t, ctx, got, "member needs to be dropped")
+	_, ok := distro.BaggageValue(got, "user")
+	assert.False(t, ok)
+
+	got, err = distro.SetBaggage(ctx, "user", "1", opt)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(baggage.FromContext(got).String()), 32)
+}
+
+func TestSetBaggageMaxBytesIgnored(t *testing.T) {
+	value := strings.Repeat("a", 100)
+	for _, n := range []int{0, -1, distro.MaxBaggageBytes + 1} {
+		_, err := distro.SetBaggage(context.Background(), "key", value, distro.WithMaxBaggageBytes(n))
+		assert.NoError(t, err, "limit: %d", n)
+	}
+
+	large := strings.Repeat("a", distro.MaxBaggageBytes)
+	_, err := distro.SetBaggage(context.Background(), "key", large, distro.WithMaxBaggageBytes(distro.MaxBaggageBytes+len(large)))
+	assert.ErrorIs(t, err, distro.ErrBaggageTooLarge, "limit cannot exceed MaxBaggageBytes")
+}
+
+func TestSetBaggageTooManyMembers(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < distro.MaxBaggageMembers; i++ {
+		var err error
+		ctx, err = distro.SetBaggage(ctx, fmt.Sprintf("key%d", i), "v")
+		require.NoError(t, err)
+	}
+
+	// Replacing a member does not add one.
+	_, err := distro.SetBaggage(ctx, "key0", "replaced")
+	assert.NoError(t, err)
+
+	got, err := distro.SetBaggage(ctx, "extra", "v")
+	assert.ErrorIs(t, err, distro.ErrBaggageTooLarge)
+	assert.Equal(t, ctx, got)
+
+	got, err = distro.SetBaggage(ctx, "extra", "v", distro.WithBaggageOverflowDrop())
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, got)
+}
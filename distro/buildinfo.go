@@ -0,0 +1,67 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// Resource attribute keys of the version control information of the build.
+const (
+	vcsRevisionKey = attribute.Key("vcs.revision")
+	vcsTimeKey     = attribute.Key("vcs.time")
+	vcsModifiedKey = attribute.Key("vcs.modified")
+)
+
+// readBuildInfo returns the build information of the running binary. It is
+// replaced in the tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// buildInfoResource returns the resource describing the build of the running
+// binary read by read: the version of the main module as service.version and
+// the version control settings embedded by the go command (e.g. the
+// vcs.revision commit hash). An empty resource is returned if the build
+// information is unavailable.
+func buildInfoResource(read func() (*debug.BuildInfo, bool)) *resource.Resource {
+	info, ok := read()
+	if !ok || info == nil {
+		return resource.Empty()
+	}
+
+	var attrs []attribute.KeyValue
+	// The main module version is "(devel)" if it is not built from a
+	// versioned module (e.g. go run in the module directory).
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		attrs = append(attrs, semconv.ServiceVersionKey.String(v))
+	}
+	for _, s := range info.Settings {
+		if s.Value == "" {
+			continue
+		}
+		switch s.Key {
+		case "vcs.revision":
+			attrs = append(attrs, vcsRevisionKey.String(s.Value))
+		case "vcs.time":
+			attrs = append(attrs, vcsTimeKey.String(s.Value))
+		case "vcs.modified":
+			attrs = append(attrs, vcsModifiedKey.Bool(s.Value == "true"))
+		}
+	}
+	return resource.NewSchemaless(attrs...)
+}
@@ -0,0 +1,151 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func fakeBuildInfo(info *debug.BuildInfo) func() (*debug.BuildInfo, bool) {
+	return func() (*debug.BuildInfo, bool) {
+		return info, info != nil
+	}
+}
+
+func TestBuildInfoResource(t *testing.T) {
+	testCases := []struct {
+		desc string
+		info *debug.BuildInfo
+		want []attribute.KeyValue
+	}{
+		{
+			desc: "full",
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "example.com/app", Version: "v1.2.3"},
+				Settings: []debug.BuildSetting{
+					{Key: "-ldflags", Value: "-X main.version=1.2.3"},
+					{Key: "vcs", Value: "git"},
+					{Key: "vcs.revision", Value: "0123456789abcdef0123456789abcdef01234567"},
+					{Key: "vcs.time", Value: "2023-06-01T10:00:00Z"},
+					{Key: "vcs.modified", Value: "true"},
+				},
+			},
+			want: []attribute.KeyValue{
+				attribute.String("service.version", "v1.2.3"),
+				attribute.String("vcs.revision", "0123456789abcdef0123456789abcdef01234567"),
+				attribute.String("vcs.time", "2023-06-01T10:00:00Z"),
+				attribute.Bool("vcs.modified", true),
+			},
+		},
+		{
+			desc: "devel",
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "example.com/app", Version: "(devel)"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "abc"},
+					{Key: "vcs.modified", Value: "false"},
+				},
+			},
+			want: []attribute.KeyValue{
+				attribute.String("vcs.revision", "abc"),
+				attribute.Bool("vcs.modified", false),
+			},
+		},
+		{
+			desc: "no vcs",
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "example.com/app", Version: "v0.1.0"},
+			},
+			want: []attribute.KeyValue{
+				attribute.String("service.version", "v0.1.0"),
+			},
+		},
+		{
+			desc: "empty",
+			info: &debug.BuildInfo{},
+		},
+		{
+			desc: "unavailable",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res := buildInfoResource(fakeBuildInfo(tc.info))
+			assert.ElementsMatch(t, tc.want, res.Attributes())
+		})
+	}
+}
+
+func TestNewResourceWithBuildInfo(t *testing.T) {
+	orig := readBuildInfo
+	t.Cleanup(func() { readBuildInfo = orig })
+	readBuildInfo = fakeBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app", Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc"},
+		},
+	})
+
+	newRes := func(t *testing.T, opts ...Option) *resource.Resource {
+		res, err := newResource(context.Background(), newTestConfig(t, opts...))
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		attrs := newRes(t, WithBuildInfo()).Set()
+		v, ok := attrs.Value("service.version")
+		assert.True(t, ok)
+		assert.Equal(t, "v1.2.3", v.AsString())
+		v, ok = attrs.Value("vcs.revision")
+		assert.True(t, ok)
+		assert.Equal(t, "abc", v.AsString())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		attrs := newRes(t).Set()
+		assert.False(t, attrs.HasValue("service.version"))
+		assert.False(t, attrs.HasValue("vcs.revision"))
+	})
+
+	t.Run("env precedence", func(t *testing.T) {
+		t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "service.version=2.0.0")
+		attrs := newRes(t, WithBuildInfo()).Set()
+		v, _ := attrs.Value("service.version")
+		assert.Equal(t, "2.0.0", v.AsString())
+		v, _ = attrs.Value("vcs.revision")
+		assert.Equal(t, "abc", v.AsString())
+	})
+
+	t.Run("WithResource precedence", func(t *testing.T) {
+		res := resource.NewSchemaless(attribute.String("service.version", "3.0.0"))
+		attrs := newRes(t, WithBuildInfo(), WithResource(res)).Set()
+		v, _ := attrs.Value("service.version")
+		assert.Equal(t, "3.0.0", v.AsString())
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		readBuildInfo = fakeBuildInfo(nil)
+		attrs := newRes(t, WithBuildInfo()).Set()
+		assert.False(t, attrs.HasValue("vcs.revision"))
+	})
+}
@@ -16,12 +16,27 @@ package distro
 
 import (
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/detectors/aws/ec2"
+	"go.opentelemetry.io/contrib/detectors/aws/ecs"
 	"go.opentelemetry.io/contrib/propagators/autoprop"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 // Environment variable keys that set values of the configuration.
@@ -29,12 +44,27 @@ const (
 	// Access token added to exported data.
 	accessTokenKey = "SPLUNK_ACCESS_TOKEN"
 
+	// Disable the SDK when set to "true".
+	otelSDKDisabledKey = "OTEL_SDK_DISABLED"
+
+	// Filter of the measurements recorded as metric exemplars. This is not
+	// supported: go.opentelemetry.io/otel/sdk/metric v0.39.0 does not record
+	// exemplars.
+	otelMetricsExemplarFilterKey = "OTEL_METRICS_EXEMPLAR_FILTER"
+
 	// OpenTelemetry TextMapPropagator to set as global.
 	otelPropagatorsKey = "OTEL_PROPAGATORS"
 
+	// Resource detectors to enable.
+	otelResourceDetectorsKey = "OTEL_RESOURCE_DETECTORS"
+
+	// Comma-separated names of the instrumentations not to start.
+	otelGoDisabledInstrumentationsKey = "OTEL_GO_DISABLED_INSTRUMENTATIONS"
+
 	// OpenTelemetry exporter to use.
 	otelTracesExporterKey  = "OTEL_TRACES_EXPORTER"
 	otelMetricsExporterKey = "OTEL_METRICS_EXPORTER"
+	otelLogsExporterKey    = "OTEL_LOGS_EXPORTER"
 
 	// OpenTelemetry exporter endpoints.
 	otelExporterJaegerEndpointKey      = "OTEL_EXPORTER_JAEGER_ENDPOINT"
@@ -42,6 +72,26 @@ const (
 	otelExporterOTLPTracesEndpointKey  = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
 	otelExporterOTLPMetricsEndpointKey = "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"
 
+	// Disable TLS for the OTLP gRPC exporters when set to "true", if the
+	// endpoint has no scheme.
+	otelExporterOTLPInsecureKey = "OTEL_EXPORTER_OTLP_INSECURE"
+
+	// OpenTelemetry OTLP exporter headers.
+	otelExporterOTLPHeadersKey        = "OTEL_EXPORTER_OTLP_HEADERS"
+	otelExporterOTLPTracesHeadersKey  = "OTEL_EXPORTER_OTLP_TRACES_HEADERS"
+	otelExporterOTLPMetricsHeadersKey = "OTEL_EXPORTER_OTLP_METRICS_HEADERS"
+
+	// OpenTelemetry OTLP exporter compression.
+	otelExporterOTLPCompressionKey        = "OTEL_EXPORTER_OTLP_COMPRESSION"
+	otelExporterOTLPTracesCompressionKey  = "OTEL_EXPORTER_OTLP_TRACES_COMPRESSION"
+	otelExporterOTLPMetricsCompressionKey = "OTEL_EXPORTER_OTLP_METRICS_COMPRESSION"
+
+	// OpenTelemetry OTLP exporter transport protocol.
+	otelExporterOTLPProtocolKey = "OTEL_EXPORTER_OTLP_PROTOCOL"
+
+	// OpenTelemetry OTLP metrics exporter temporality preference.
+	otelExporterOTLPMetricsTemporalityKey = "OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE"
+
 	// Logging level to set when using the default logger.
 	otelLogLevelKey = "OTEL_LOG_LEVEL"
 
@@ -49,8 +99,24 @@ const (
 	// are sent. This is not currently supported.
 	splunkMetricsEndpointKey = "SPLUNK_METRICS_ENDPOINT"
 
+	// splunkProfilerEnabledKey enables the AlwaysOn Profiling correlation
+	// when set to "true".
+	splunkProfilerEnabledKey = "SPLUNK_PROFILER_ENABLED"
+
+	// splunkProfilerLogsEndpointKey defines the endpoint the profiling data
+	// is sent to.
+	splunkProfilerLogsEndpointKey = "SPLUNK_PROFILER_LOGS_ENDPOINT"
+
+	// splunkConsolePrettyPrintKey disables indentation of the console
+	// exporter output when set to "false".
+	splunkConsolePrettyPrintKey = "SPLUNK_CONSOLE_PRETTY_PRINT_ENABLED"
+
 	// splunkRealmKey defines the Splunk realm to build an endpoint from.
 	splunkRealmKey = "SPLUNK_REALM"
+
+	// splunkRuntimeMetricsEnabledKey disables the collection of the Go
+	// runtime metrics when set to "false".
+	splunkRuntimeMetricsEnabledKey = "SPLUNK_RUNTIME_METRICS_ENABLED"
 )
 
 // Default configuration values.
@@ -58,46 +124,756 @@ const (
 	defaultAccessToken     = ""
 	defaultTraceExporter   = "otlp"
 	defaultMetricsExporter = "otlp"
-	defaultLogLevel        = "info"
+	defaultLogsExporter    = "none"
+	defaultOTLPProtocol    = otlpProtocolGRPC
+
+	// Splunk Observability Cloud expects delta temporality. This deviates
+	// from the OpenTelemetry specification default of cumulative.
+	defaultMetricsTemporality = temporalityDelta
+
+	defaultShutdownTimeout = 30 * time.Second
+
+	defaultResourceDetectionTimeout = 5 * time.Second
+
+	// A connection with an export in flight is pinged after this much
+	// inactivity and closed if the ping is not acknowledged in time. Closed
+	// connections are re-dialed, which re-resolves the endpoint host. gRPC
+	// servers (including the OpenTelemetry Collector) reject pings sent more
+	// often than every 5 minutes by default, closing the connection with a
+	// "too_many_pings" GOAWAY, so the interval must not be lower.
+	defaultGRPCKeepaliveTime    = 5 * time.Minute
+	defaultGRPCKeepaliveTimeout = 20 * time.Second
 
 	defaultJaegerEndpoint = "http://127.0.0.1:9080/v1/trace"
 
 	realmEndpointFormat     = "https://ingest.%s.signalfx.com/v2/trace"
 	otlpRealmEndpointFormat = "ingest.%s.signalfx.com:443"
+
+	otlpHTTPRealmHostFormat  = "ingest.%s.signalfx.com"
+	otlpHTTPRealmTracesPath  = "/v2/trace/otlp"
+	otlpHTTPRealmMetricsPath = "/v2/datapoint/otlp"
+)
+
+// Names of the instrumentations started by Run.
+const (
+	// instrumentationRuntime is the Go runtime metrics instrumentation.
+	instrumentationRuntime = "runtime"
+	// instrumentationHost is the host metrics instrumentation. It is not
+	// started by Run, but is accepted as other OpenTelemetry Go
+	// distributions start it, so OTEL_GO_DISABLED_INSTRUMENTATIONS can be
+	// shared with them. Disabling it has no effect.
+	instrumentationHost = "host"
+)
+
+// OTLP exporter transport protocols.
+const (
+	otlpProtocolGRPC = "grpc"
+	otlpProtocolHTTP = "http/protobuf"
+)
+
+// OTLP exporter compressions.
+const (
+	compressionGzip = "gzip"
+	compressionNone = "none"
+)
+
+// OTLP metrics exporter temporality preferences.
+const (
+	temporalityCumulative = "cumulative"
+	temporalityDelta      = "delta"
+	temporalityLowMemory  = "lowmemory"
 )
 
 type exporterConfig struct {
-	AccessToken string
-	TLSConfig   *tls.Config
+	Endpoint           string
+	TracesEndpoint     string
+	MetricsEndpoint    string
+	Realm              string
+	AccessToken        string
+	Headers            map[string]string
+	TLSConfig          *tls.Config
+	OTLPProtocol       string
+	Compression        string
+	MetricsTemporality string
+	RetryConfig        *RetryConfig
+	GRPCDialOptions    []grpc.DialOption
+
+	// GRPCKeepalive are the keepalive parameters of the OTLP gRPC
+	// connections. Keepalive is disabled if Time is not positive.
+	GRPCKeepalive keepalive.ClientParameters
+
+	// signalEndpoint is true if Endpoint is the endpoint of a signal (i.e.
+	// TracesEndpoint or MetricsEndpoint). Its URL path is used as is by the
+	// OTLP HTTP exporters.
+	signalEndpoint bool
+}
+
+// signalConfig returns a copy of c using the passed signal specific endpoint
+// as Endpoint, or c if endpoint is empty.
+func (c *exporterConfig) signalConfig(endpoint string) *exporterConfig {
+	if endpoint == "" {
+		return c
+	}
+	cp := *c
+	cp.Endpoint = endpoint
+	cp.signalEndpoint = true
+	return &cp
 }
 
 // config is the configuration used to create and operate an SDK.
 type config struct {
-	Logger     logr.Logger
-	Propagator propagation.TextMapPropagator
-	SpanLimits *trace.SpanLimits
+	Logger       logr.Logger
+	ErrorHandler otel.ErrorHandler
+	Propagator   propagation.TextMapPropagator
+	SpanLimits   *trace.SpanLimits
+	Resource     *resource.Resource
+	Sampler      trace.Sampler
+	BSPOptions   []trace.BatchSpanProcessorOption
+
+	// SpanProcessors are the span processors passed with
+	// WithAdditionalSpanProcessor.
+	SpanProcessors []trace.SpanProcessor
+
+	// SpanStartHooks are the hooks passed with WithSpanStartHook.
+	SpanStartHooks []SpanStartHook
+
+	// SpanExporters are the span exporters passed with
+	// WithAdditionalExporter.
+	SpanExporters []trace.SpanExporter
+
+	// PrometheusRegistry is the registry passed with WithPrometheusExporter.
+	// The metrics are not exposed to Prometheus if it is nil.
+	PrometheusRegistry *prometheus.Registry
+
+	// HECLogs is the configuration of the logs exporter passed with
+	// WithHECLogsExporter. The logs are not exported if it is nil.
+	HECLogs *hecConfig
+
+	// AttributeScrubbers are the functions passed with WithAttributeScrubber.
+	AttributeScrubbers []func(attribute.KeyValue) (attribute.KeyValue, bool)
+
+	// TruncationMarker is whether the attribute values truncated to the
+	// AttributeValueLengthLimit of SpanLimits are marked with their original
+	// length, as set by WithAttributeTruncationMarker.
+	TruncationMarker bool
+
+	// DynamicSampling is whether the ratio passed with
+	// WithDynamicSamplingRatio is used. DynamicSampler is the root sampler
+	// of Sampler created for it, updated by SDK.SetSamplingRatio.
+	// SamplingRatioLoad is the function passed with
+	// WithSamplingRatioReloadOnSIGHUP.
+	DynamicSampling      bool
+	DynamicSamplingRatio float64
+	DynamicSampler       *dynamicRatioSampler
+	SamplingRatioLoad    func() (float64, error)
+
+	// MaxSpanDuration is the duration after which the active spans are
+	// ended. The spans are not ended if it is zero.
+	MaxSpanDuration time.Duration
+
+	// ServiceName is the service name passed with WithServiceName.
+	ServiceName string
+
+	// ServiceNamespace is the service namespace passed with
+	// WithServiceNamespace.
+	ServiceNamespace string
+
+	// ConfigFile is the path of the YAML configuration file passed with
+	// WithConfigFile.
+	ConfigFile string
+	// FileResourceAttributes are the resource attributes of ConfigFile. They
+	// have a lower precedence than the detected attributes.
+	FileResourceAttributes map[string]string
+
+	// TransportFallback is true if WithTransportFallback is used.
+	TransportFallback bool
+
+	// SchemaURL is the schema URL passed with WithSchemaURL. The schema URL
+	// of the detected resource is used if it is empty.
+	SchemaURL string
+
+	// Environment is the deployment environment passed with
+	// WithEnvironment.
+	Environment string
+
+	// ResourceAttributes are the attributes passed with
+	// WithResourceAttributes.
+	ResourceAttributes map[string]string
+
+	// PropagatorNames are the names of the propagators composing Propagator,
+	// or "custom" if it was passed with WithPropagator.
+	PropagatorNames []string
+
+	HostDetection            bool
+	ContainerDetection       bool
+	BuildInfo                bool
+	ResourceDetectors        []resource.Detector
+	ResourceDetectionTimeout time.Duration
+
+	// RuntimeMetrics is whether the Go runtime metrics are collected.
+	// RuntimeMetricsInterval is the minimum interval between two reads of
+	// the Go memory statistics, the default of the runtime instrumentation
+	// is used if it is zero.
+	RuntimeMetrics         bool
+	RuntimeMetricsInterval time.Duration
+
+	// HTTPServerDurationBoundaries are the bucket boundaries (in
+	// milliseconds) of the http.server.duration histogram recorded by the
+	// splunkhttp instrumentation. The Splunk defaults are used if nil.
+	HTTPServerDurationBoundaries []float64
+
+	// Profiler is whether the resource is set up for the correlation of the
+	// Splunk AlwaysOn Profiling profiles with the spans.
+	// ProfilerLogsEndpoint is the endpoint passed with
+	// WithProfilerLogsEndpoint.
+	Profiler             bool
+	ProfilerLogsEndpoint string
+
+	// DisabledInstrumentations are the names of the instrumentations not
+	// to start, passed with WithDisabledInstrumentations or set by the
+	// OTEL_GO_DISABLED_INSTRUMENTATIONS environment variable.
+	DisabledInstrumentations []string
+
+	// AllowNoToken is whether the telemetry can be exported to a Splunk
+	// ingest endpoint without an access token, passed with
+	// WithAllowNoToken.
+	AllowNoToken bool
+
+	ShutdownTimeout    time.Duration
+	GlobalRegistration bool
 
 	ExportConfig        *exporterConfig
+	TracesExporter      string
 	TracesExporterFunc  traceExporterFunc
+	MetricsExporter     string
 	MetricsExporterFunc metricsExporterFunc
 }
 
 // newConfig returns a validated config with Splunk defaults.
-func newConfig(opts ...Option) *config {
+func newConfig(opts ...Option) (*config, error) {
+	host, container, err := resourceDetectors()
+	if err != nil {
+		return nil, err
+	}
+	disabled, err := disabledInstrumentations()
+	if err != nil {
+		return nil, err
+	}
+
 	c := &config{
-		Logger:     logger(zapConfig(envOr(otelLogLevelKey, defaultLogLevel))),
-		Propagator: autoprop.NewTextMapPropagator(),
+		Logger:     defaultLogger(),
 		SpanLimits: newSpanLimits(),
 		ExportConfig: &exporterConfig{
-			AccessToken: envOr(accessTokenKey, defaultAccessToken),
+			AccessToken:  envOr(accessTokenKey, defaultAccessToken),
+			OTLPProtocol: envOr(otelExporterOTLPProtocolKey, defaultOTLPProtocol),
+			MetricsTemporality: strings.ToLower(
+				envOr(otelExporterOTLPMetricsTemporalityKey, defaultMetricsTemporality),
+			),
+			GRPCKeepalive: keepalive.ClientParameters{
+				Time:    defaultGRPCKeepaliveTime,
+				Timeout: defaultGRPCKeepaliveTimeout,
+			},
 		},
+		HostDetection:            host,
+		ContainerDetection:       container,
+		DisabledInstrumentations: disabled,
+		RuntimeMetrics:           !strings.EqualFold(strings.TrimSpace(os.Getenv(splunkRuntimeMetricsEnabledKey)), "false"),
+		ResourceDetectionTimeout: defaultResourceDetectionTimeout,
+		ShutdownTimeout:          defaultShutdownTimeout,
+		GlobalRegistration:       true,
+		Profiler:                 profilerEnabled(),
 	}
 	for _, o := range opts {
 		o.apply(c)
 	}
-	c.TracesExporterFunc = tracesExporter(c.Logger)
-	c.MetricsExporterFunc = metricsExporter(c.Logger)
-	return c
+
+	if c.ConfigFile != "" {
+		fc, err := loadConfigFile(c.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.applyConfigFile(fc); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.ErrorHandler == nil {
+		l := c.Logger
+		c.ErrorHandler = otel.ErrorHandlerFunc(func(err error) {
+			l.Error(err, "OpenTelemetry error")
+		})
+	}
+
+	if c.Propagator == nil {
+		if c.Propagator, c.PropagatorNames, err = propagator(); err != nil {
+			return nil, err
+		}
+	} else if c.PropagatorNames == nil {
+		// Set with WithPropagator.
+		c.PropagatorNames = []string{"custom"}
+	}
+	c.TracesExporter, c.TracesExporterFunc, err = tracesExporter(c.Logger)
+	if err != nil {
+		return nil, err
+	}
+	c.MetricsExporter, c.MetricsExporterFunc, err = metricsExporter(c.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	if c.DynamicSampling {
+		c.DynamicSampler = newDynamicRatioSampler(c.DynamicSamplingRatio)
+		c.Sampler = trace.ParentBased(c.DynamicSampler)
+	}
+	return c, nil
+}
+
+// logKeysAndValues returns the effective configuration as key-value pairs to
+// log. Secrets (e.g. the access token) are not included.
+func (c *config) logKeysAndValues() []interface{} {
+	kv := []interface{}{
+		"tracesExporter", c.TracesExporter,
+		"metricsExporter", c.MetricsExporter,
+		"otlpProtocol", c.ExportConfig.OTLPProtocol,
+		"metricsTemporality", c.ExportConfig.MetricsTemporality,
+		"propagators", c.PropagatorNames,
+		"propagatorFields", c.Propagator.Fields(),
+		"sampler", c.samplerName(),
+		"accessTokenSet", c.ExportConfig.AccessToken != "",
+	}
+	kv = append(kv, "runtimeMetrics", c.runtimeMetricsEnabled())
+	if c.PrometheusRegistry != nil {
+		kv = append(kv, "prometheusExporter", true)
+	}
+	if c.HECLogs != nil {
+		kv = append(kv, "logsExporter", "hec")
+	}
+	if c.TransportFallback {
+		kv = append(kv, "transportFallback", true)
+	}
+	if c.Profiler {
+		kv = append(kv, "profiler", true)
+	}
+	if len(c.DisabledInstrumentations) > 0 {
+		kv = append(kv, "disabledInstrumentations", c.DisabledInstrumentations)
+	}
+	if c.ExportConfig.Endpoint != "" {
+		// The endpoint was validated before, the error is always nil.
+		u, _ := parseEndpoint(c.ExportConfig.Endpoint)
+		kv = append(kv, "endpoint", u.Redacted())
+	}
+	if notNone(c.ExportConfig.Realm) {
+		kv = append(kv, "realm", c.ExportConfig.Realm)
+	}
+	if host := c.tracesEndpointHost(); host != "" {
+		kv = append(kv, "tracesEndpointHost", host)
+	}
+	if host := c.metricsEndpointHost(); host != "" {
+		kv = append(kv, "metricsEndpointHost", host)
+	}
+	return kv
+}
+
+// samplerName returns the description of the sampler passed with WithSampler,
+// the sampler set by the OTEL_TRACES_SAMPLER environment variable, or the
+// description of the default sampler.
+func (c *config) samplerName() string {
+	if c.Sampler != nil {
+		return c.Sampler.Description()
+	}
+	if v, ok := os.LookupEnv(tracesSamplerKey); ok {
+		if arg, ok := os.LookupEnv(tracesSamplerArgKey); ok {
+			return v + ":" + arg
+		}
+		return v
+	}
+	return trace.AlwaysSample().Description()
+}
+
+// tracesEndpointHost returns the host and port the traces are exported to,
+// or an empty string if the traces are not exported to an endpoint. Only the
+// host is returned so no credentials can be included.
+func (c *config) tracesEndpointHost() string {
+	e := c.ExportConfig.signalConfig(c.ExportConfig.TracesEndpoint)
+	switch c.TracesExporter {
+	case "otlp":
+		return otlpEndpointHost(e, otelExporterOTLPTracesEndpointKey)
+	case "jaeger-thrift-splunk":
+		if e.Endpoint != "" {
+			return endpointHost(e.Endpoint)
+		}
+		realm := e.Realm
+		if !notNone(realm) {
+			if v := os.Getenv(otelExporterJaegerEndpointKey); v != "" {
+				return endpointHost(v)
+			}
+			realm = os.Getenv(splunkRealmKey)
+		}
+		if notNone(realm) {
+			return endpointHost(fmt.Sprintf(realmEndpointFormat, realm))
+		}
+		return endpointHost(defaultJaegerEndpoint)
+	}
+	return ""
+}
+
+// metricsEndpointHost returns the host and port the metrics are exported to,
+// or an empty string if the metrics are not exported to an endpoint.
+func (c *config) metricsEndpointHost() string {
+	if c.MetricsExporter == "otlp" {
+		e := c.ExportConfig.signalConfig(c.ExportConfig.MetricsEndpoint)
+		return otlpEndpointHost(e, otelExporterOTLPMetricsEndpointKey)
+	}
+	return ""
+}
+
+// otlpEndpointHost returns the host and port used by an OTLP exporter. The
+// endpoint is resolved from the WithEndpoint and WithRealm options, the
+// signalEndpointKey and OTEL_EXPORTER_OTLP_ENDPOINT environment variables,
+// and the SPLUNK_REALM environment variable, in order.
+func otlpEndpointHost(e *exporterConfig, signalEndpointKey string) string {
+	if e.Endpoint != "" {
+		return endpointHost(e.Endpoint)
+	}
+	realm := e.Realm
+	if !notNone(realm) {
+		for _, key := range []string{signalEndpointKey, otelExporterOTLPEndpointKey} {
+			if v := os.Getenv(key); v != "" {
+				return endpointHost(v)
+			}
+		}
+		realm = os.Getenv(splunkRealmKey)
+	}
+	if e.OTLPProtocol == otlpProtocolHTTP {
+		if notNone(realm) {
+			return fmt.Sprintf(otlpHTTPRealmHostFormat, realm) + ":443"
+		}
+		return "localhost:4318"
+	}
+	if notNone(realm) {
+		return fmt.Sprintf(otlpRealmEndpointFormat, realm)
+	}
+	return "localhost:4317"
+}
+
+// checkAccessToken returns an error if the traces or metrics are exported to
+// a Splunk ingest endpoint without an access token (i.e. neither set with
+// WithAccessToken or SPLUNK_ACCESS_TOKEN nor as X-Sf-Token header of the OTLP
+// exporters), unless WithAllowNoToken is used.
+func (c *config) checkAccessToken() error {
+	if c.AllowNoToken || c.ExportConfig.AccessToken != "" {
+		return nil
+	}
+
+	signals := []struct {
+		name       string
+		host       string
+		otlp       bool
+		headersKey string
+	}{
+		{
+			name:       "traces",
+			host:       c.tracesEndpointHost(),
+			otlp:       c.TracesExporter == "otlp",
+			headersKey: otelExporterOTLPTracesHeadersKey,
+		},
+		{
+			name:       "metrics",
+			host:       c.metricsEndpointHost(),
+			otlp:       c.MetricsExporter == "otlp",
+			headersKey: otelExporterOTLPMetricsHeadersKey,
+		},
+	}
+	for _, s := range signals {
+		if !isSplunkIngestHost(s.host) {
+			continue
+		}
+		if s.otlp {
+			// Invalid headers are reported when the exporter is created.
+			h, err := otlpHeaders(c.ExportConfig, s.headersKey)
+			if err != nil || h["X-Sf-Token"] != "" {
+				continue
+			}
+		}
+		return fmt.Errorf("%s are exported to the Splunk ingest endpoint %q without an access token: set %s or use WithAccessToken (use WithAllowNoToken if a proxy adds the token)", s.name, s.host, accessTokenKey)
+	}
+	return nil
+}
+
+// isSplunkIngestHost returns true if hostport is the host, with an optional
+// port, of a Splunk Observability Cloud ingest endpoint (i.e.
+// ingest.<realm>.signalfx.com).
+func isSplunkIngestHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	const prefix, suffix = "ingest.", ".signalfx.com"
+	if !strings.HasPrefix(host, prefix) || !strings.HasSuffix(host, suffix) {
+		return false
+	}
+	realm := strings.TrimSuffix(strings.TrimPrefix(host, prefix), suffix)
+	return realm != "" && !strings.Contains(realm, ".")
+}
+
+// endpointHost returns the host and port of endpoint. The endpoint can be a
+// URL or, as accepted by the gRPC exporters, a host and port.
+func endpointHost(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		if u, err := url.Parse(endpoint); err == nil {
+			return u.Host
+		}
+		return ""
+	}
+	host, _, _ := strings.Cut(endpoint, "/")
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	return host
+}
+
+// resourceDetectors returns if the host and container resource detectors are
+// enabled by the comma-separated list of the OTEL_RESOURCE_DETECTORS
+// environment variable. An error is returned if an unknown detector is
+// listed.
+func resourceDetectors() (host, container bool, err error) {
+	v, ok := os.LookupEnv(otelResourceDetectorsKey)
+	if !ok {
+		return false, false, nil
+	}
+
+	var unknown []string
+	for _, name := range strings.Split(v, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "host":
+			host = true
+		case "container":
+			container = true
+		case "", "none":
+		default:
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return false, false, fmt.Errorf("invalid %s: unknown resource detectors: %s", otelResourceDetectorsKey, strings.Join(unknown, ", "))
+	}
+	return host, container, nil
+}
+
+// disabledInstrumentations returns the names of the instrumentations listed
+// in the OTEL_GO_DISABLED_INSTRUMENTATIONS environment variable. An error
+// naming the unknown values is returned if any of the listed instrumentations
+// is not known.
+func disabledInstrumentations() ([]string, error) {
+	v, ok := os.LookupEnv(otelGoDisabledInstrumentationsKey)
+	if !ok {
+		return nil, nil
+	}
+
+	var names []string
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	if unknown := unknownInstrumentations(names); len(unknown) > 0 {
+		return nil, fmt.Errorf("invalid %s: unknown instrumentations: %s", otelGoDisabledInstrumentationsKey, strings.Join(unknown, ", "))
+	}
+	return names, nil
+}
+
+// unknownInstrumentations returns the names that are not the name of an
+// instrumentation started by Run.
+func unknownInstrumentations(names []string) []string {
+	var unknown []string
+	for _, name := range names {
+		switch name {
+		case instrumentationRuntime, instrumentationHost:
+		default:
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// runtimeMetricsEnabled returns if the Go runtime metrics are collected.
+func (c *config) runtimeMetricsEnabled() bool {
+	return c.RuntimeMetrics && c.instrumentationEnabled(instrumentationRuntime)
+}
+
+// instrumentationEnabled returns if the instrumentation with the passed name
+// is not disabled.
+func (c *config) instrumentationEnabled(name string) bool {
+	for _, n := range c.DisabledInstrumentations {
+		if n == name {
+			return false
+		}
+	}
+	return true
+}
+
+// propagator returns the TextMapPropagator composed from the propagators
+// listed in the OTEL_PROPAGATORS environment variable and their names. The
+// W3C tracecontext and baggage propagators are used if it is not set. An
+// error naming the unknown values is returned if any of the listed
+// propagators is not known.
+func propagator() (propagation.TextMapPropagator, []string, error) {
+	v, ok := os.LookupEnv(otelPropagatorsKey)
+	if !ok {
+		return autoprop.NewTextMapPropagator(), []string{"tracecontext", "baggage"}, nil
+	}
+
+	names := strings.Split(v, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	p, err := autoprop.TextMapPropagator(names...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", otelPropagatorsKey, err)
+	}
+	return p, names, nil
+}
+
+// validate returns an error if c contains an invalid setting value.
+func (c *config) validate() error {
+	switch c.ExportConfig.OTLPProtocol {
+	case otlpProtocolGRPC, otlpProtocolHTTP:
+	default:
+		return fmt.Errorf("invalid OTLP protocol %q: must be %q or %q", c.ExportConfig.OTLPProtocol, otlpProtocolGRPC, otlpProtocolHTTP)
+	}
+
+	switch c.ExportConfig.MetricsTemporality {
+	case temporalityCumulative, temporalityDelta, temporalityLowMemory:
+	default:
+		return fmt.Errorf("invalid metrics temporality preference %q: must be %q, %q, or %q", c.ExportConfig.MetricsTemporality, temporalityCumulative, temporalityDelta, temporalityLowMemory)
+	}
+
+	for _, endpoint := range []string{c.ExportConfig.TracesEndpoint, c.ExportConfig.MetricsEndpoint} {
+		if endpoint == "" {
+			continue
+		}
+		if _, err := parseEndpoint(endpoint); err != nil {
+			return err
+		}
+	}
+
+	if c.ExportConfig.Endpoint != "" {
+		if _, err := parseEndpoint(c.ExportConfig.Endpoint); err != nil {
+			return err
+		}
+	} else if notNone(c.ExportConfig.Realm) && c.ExportConfig.AccessToken == "" && !c.AllowNoToken {
+		return fmt.Errorf("realm %q requires an access token: use WithAccessToken or %s", c.ExportConfig.Realm, accessTokenKey)
+	}
+
+	if err := c.checkAccessToken(); err != nil {
+		return err
+	}
+
+	if c.ExportConfig.Compression != "" {
+		if err := validateCompression(c.ExportConfig.Compression); err != nil {
+			return err
+		}
+	}
+
+	if c.HECLogs != nil {
+		if err := c.HECLogs.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.DynamicSampling && math.IsNaN(c.DynamicSamplingRatio) {
+		return errors.New("invalid dynamic sampling ratio: NaN")
+	}
+	if c.SamplingRatioLoad != nil && !c.DynamicSampling {
+		return errors.New("sampling ratio reload requires a dynamic sampling ratio: use WithDynamicSamplingRatio")
+	}
+
+	if c.MaxSpanDuration < 0 {
+		return fmt.Errorf("invalid max span duration %s: must not be negative", c.MaxSpanDuration)
+	}
+
+	if c.RuntimeMetricsInterval < 0 {
+		return fmt.Errorf("invalid runtime metrics interval %s: must not be negative", c.RuntimeMetricsInterval)
+	}
+
+	if c.ProfilerLogsEndpoint != "" {
+		if _, err := parseEndpoint(c.ProfilerLogsEndpoint); err != nil {
+			return fmt.Errorf("invalid profiler logs endpoint: %w", err)
+		}
+	}
+
+	if c.HTTPServerDurationBoundaries != nil {
+		if err := validateBoundaries(c.HTTPServerDurationBoundaries); err != nil {
+			return fmt.Errorf("invalid HTTP server duration boundaries: %w", err)
+		}
+	}
+
+	if unknown := unknownInstrumentations(c.DisabledInstrumentations); len(unknown) > 0 {
+		return fmt.Errorf("invalid disabled instrumentations: unknown instrumentations: %s", strings.Join(unknown, ", "))
+	}
+
+	if c.SchemaURL != "" {
+		if err := validateSchemaURL(c.SchemaURL); err != nil {
+			return err
+		}
+	}
+
+	if err := validateHeaders(c.ExportConfig.Headers); err != nil {
+		return fmt.Errorf("invalid headers: %w", err)
+	}
+	return nil
+}
+
+// validateBoundaries returns an error if boundaries is empty, or if the
+// boundaries are not positive finite numbers sorted in increasing order.
+func validateBoundaries(boundaries []float64) error {
+	if len(boundaries) == 0 {
+		return errors.New("no histogram bucket boundary")
+	}
+	for i, b := range boundaries {
+		if b <= 0 || math.IsInf(b, 0) || math.IsNaN(b) {
+			return fmt.Errorf("histogram bucket boundary is not a positive number: %v", b)
+		}
+		if i > 0 && b <= boundaries[i-1] {
+			return fmt.Errorf("histogram bucket boundaries not sorted in increasing order: %v", boundaries)
+		}
+	}
+	return nil
+}
+
+// validateSchemaURL returns an error if rawURL is not an absolute HTTP or
+// HTTPS URL, e.g. "https://opentelemetry.io/schemas/1.17.0".
+func validateSchemaURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid schema URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid schema URL %q: must be an absolute HTTP or HTTPS URL", rawURL)
+	}
+	return nil
+}
+
+// defaultLogger returns the logger used if WithLogger is not provided. It is
+// a zapr.Logger logging at the OTEL_LOG_LEVEL level if the environment
+// variable is set, otherwise it discards all logs.
+func defaultLogger() logr.Logger {
+	level, ok := os.LookupEnv(otelLogLevelKey)
+	if !ok {
+		return logr.Discard()
+	}
+	return logger(zapConfig(level))
+}
+
+// sdkDisabled returns true if the OTEL_SDK_DISABLED environment variable is
+// set to "true" (case insensitive).
+func sdkDisabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv(otelSDKDisabledKey)), "true")
 }
 
 // envOr returns the environment variable value associated with key if it
@@ -121,8 +897,164 @@ func (fn optionFunc) apply(c *config) {
 	fn(c)
 }
 
+// WithConfigFile configures the YAML file at path to load the configuration
+// from, e.g. in a container image, instead of setting many environment
+// variables. The file can set the following values:
+//
+//	# Endpoint the telemetry is sent to, as set by WithEndpoint.
+//	endpoint: https://collector:4317
+//	# Access token, as set by SPLUNK_ACCESS_TOKEN.
+//	access_token: <token>
+//	# Sampler and its argument, as set by OTEL_TRACES_SAMPLER and
+//	# OTEL_TRACES_SAMPLER_ARG.
+//	sampler: parentbased_traceidratio
+//	sampler_arg: "0.25"
+//	# Propagators, as set by OTEL_PROPAGATORS.
+//	propagators: [tracecontext, baggage]
+//	# Resource attributes, as set by OTEL_RESOURCE_ATTRIBUTES.
+//	resource_attributes:
+//	  service.name: checkout
+//	  deployment.environment: prod
+//
+// The values are resolved in order of precedence from the options, the
+// environment variables, the file, and the defaults. The endpoint of the
+// file is not used if any endpoint or realm is set with an option or an
+// environment variable (e.g. OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or
+// SPLUNK_REALM). The resource attributes of the file are merged with the
+// ones set by OTEL_RESOURCE_ATTRIBUTES, which take precedence.
+//
+// Run returns an error if the file cannot be read, has unknown fields, or
+// sets an invalid value. By default, no file is loaded.
+func WithConfigFile(path string) Option {
+	return optionFunc(func(c *config) {
+		c.ConfigFile = path
+	})
+}
+
+// WithEndpoint configures the endpoint telemetry is sent to.
+//
+// The endpoint needs to be a URL (e.g. "http://localhost:4317"). The OTLP
+// exporter connects without TLS when the "http" scheme is used. Run returns
+// an error if the endpoint is not a valid URL.
+//
+// The endpoint used by an exporter is resolved in the following order:
+//   - the endpoint passed to WithTracesEndpoint or WithMetricsEndpoint for
+//     the respective exporter,
+//   - the endpoint passed to this option,
+//   - the Splunk ingest endpoint for the realm passed to WithRealm,
+//   - the OTEL_EXPORTER_OTLP_TRACES_ENDPOINT,
+//     OTEL_EXPORTER_OTLP_METRICS_ENDPOINT, or OTEL_EXPORTER_JAEGER_ENDPOINT
+//     environment variable for the respective exporter,
+//   - the OTEL_EXPORTER_OTLP_ENDPOINT environment variable,
+//   - the Splunk ingest endpoint for the SPLUNK_REALM environment variable,
+//   - the default endpoint of a locally running collector.
+//
+// Passing an empty string results in this order being used as if this
+// option was not provided.
+func WithEndpoint(endpoint string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.Endpoint = endpoint
+	})
+}
+
+// WithTracesEndpoint configures the endpoint spans are sent to. It takes
+// precedence over the endpoint passed to WithEndpoint, the realm, and the
+// endpoint environment variables (see WithEndpoint for the resolution order).
+//
+// The endpoint needs to be a URL (e.g. "http://localhost:4317"). Like the
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variable, its URL path is
+// used as is by the OTLP exporter using the HTTP protocol, "/v1/traces" is
+// only used if it has no path. Run returns an error if the endpoint is not a
+// valid URL. Passing an empty string results in this option being ignored.
+func WithTracesEndpoint(endpoint string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.TracesEndpoint = endpoint
+	})
+}
+
+// WithMetricsEndpoint configures the endpoint metrics are sent to. It takes
+// precedence over the endpoint passed to WithEndpoint, the realm, and the
+// endpoint environment variables (see WithEndpoint for the resolution order).
+//
+// The endpoint needs to be a URL (e.g. "http://localhost:4317"). Like the
+// OTEL_EXPORTER_OTLP_METRICS_ENDPOINT environment variable, its URL path is
+// used as is by the OTLP exporter using the HTTP protocol, "/v1/metrics" is
+// only used if it has no path. Run returns an error if the endpoint is not a
+// valid URL. Passing an empty string results in this option being ignored.
+func WithMetricsEndpoint(endpoint string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.MetricsEndpoint = endpoint
+	})
+}
+
+// WithRealm configures the Splunk Observability Cloud realm (e.g. "us1")
+// telemetry is sent to. The exporters send telemetry directly to the ingest
+// endpoint of the realm (ingest.<realm>.signalfx.com) using TLS.
+//
+// The realm takes precedence over the endpoint environment variables and the
+// SPLUNK_REALM environment variable, but the endpoint passed to WithEndpoint
+// takes precedence over it. Run returns an error if the realm is used and no
+// access token is configured with WithAccessToken or the SPLUNK_ACCESS_TOKEN
+// environment variable, unless WithAllowNoToken is used.
+func WithRealm(realm string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.Realm = realm
+	})
+}
+
+// WithAccessToken configures the Splunk Observability Cloud access token
+// used to authenticate exported telemetry.
+//
+// The token is sent in the X-Sf-Token header by the OTLP exporter and using
+// basic authentication by the Jaeger exporter. It takes precedence over the
+// SPLUNK_ACCESS_TOKEN environment variable.
+func WithAccessToken(accessToken string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.AccessToken = accessToken
+	})
+}
+
+// WithAllowNoToken configures the SDK to export the telemetry to a Splunk
+// ingest endpoint (ingest.<realm>.signalfx.com) without an access token, e.g.
+// through an edge proxy adding the token to the requests.
+//
+// By default, Run returns an error if the traces or metrics are exported to
+// a Splunk ingest endpoint without an access token, as they would be
+// rejected.
+func WithAllowNoToken() Option {
+	return optionFunc(func(c *config) {
+		c.AllowNoToken = true
+	})
+}
+
+// WithHeaders configures additional headers sent by the OTLP exporters with
+// each export request (e.g. for tenant routing or an authenticating proxy).
+//
+// The headers are merged with the ones set by the OTEL_EXPORTER_OTLP_HEADERS
+// and signal specific (e.g. OTEL_EXPORTER_OTLP_TRACES_HEADERS) environment
+// variables and with the X-Sf-Token header of the access token. The passed
+// headers take precedence on key conflicts. Run returns an error if a header
+// name or value is not valid for HTTP.
+//
+// Multiple uses of this option are additive.
+func WithHeaders(headers map[string]string) Option {
+	return optionFunc(func(c *config) {
+		if c.ExportConfig.Headers == nil {
+			c.ExportConfig.Headers = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			c.ExportConfig.Headers[k] = v
+		}
+	})
+}
+
 // WithTLSConfig configures the TLS configuration used by the exporter.
 //
+// The passed configuration takes precedence over the TLS settings configured
+// with environment variables (e.g. OTEL_EXPORTER_OTLP_CERTIFICATE). Run
+// returns an error if the exporter endpoint uses the insecure "http" scheme,
+// as the configuration would not be used.
+//
 // If this option is not provided, the exporter connection will use the default
 // TLS config.
 func WithTLSConfig(conf *tls.Config) Option {
@@ -131,6 +1063,722 @@ func WithTLSConfig(conf *tls.Config) Option {
 	})
 }
 
+// WithOTLPProtocol configures the transport protocol used by the OTLP
+// exporters. The supported values are "grpc" and "http/protobuf".
+//
+// When "http/protobuf" is used, the signal specific path (e.g. "/v1/traces")
+// is appended to the path of the endpoint passed to WithEndpoint.
+//
+// The passed protocol takes precedence over the OTEL_EXPORTER_OTLP_PROTOCOL
+// environment variable. Run returns an error if the protocol is not
+// supported. By default, "grpc" is used.
+func WithOTLPProtocol(protocol string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.OTLPProtocol = protocol
+	})
+}
+
+// WithCompression configures the compression of the payloads sent by the
+// OTLP exporters. The supported values are "gzip" and "none".
+//
+// The passed compression takes precedence over the
+// OTEL_EXPORTER_OTLP_COMPRESSION and signal specific (e.g.
+// OTEL_EXPORTER_OTLP_TRACES_COMPRESSION) environment variables. Run returns an
+// error if the compression is not supported. By default, the payloads are not
+// compressed.
+func WithCompression(compression string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.Compression = strings.ToLower(strings.TrimSpace(compression))
+	})
+}
+
+// RetryConfig defines the retry policy of the OTLP exporters for exports that
+// fail with a transient error (e.g. the endpoint is temporarily unavailable).
+// An exponential back-off algorithm is used between retries.
+type RetryConfig struct {
+	// Enabled indicates whether failed exports are retried.
+	Enabled bool
+	// InitialInterval is the time to wait after the first failure before
+	// retrying.
+	InitialInterval time.Duration
+	// MaxInterval is the upper bound on the back-off interval.
+	MaxInterval time.Duration
+	// MaxElapsedTime is the maximum amount of time (including retries) spent
+	// trying to send a batch. Once reached, the data is discarded.
+	MaxElapsedTime time.Duration
+}
+
+// WithRetryConfig configures the retry policy of the OTLP exporters.
+//
+// There are no environment variables defined by OpenTelemetry to configure
+// the retry policy. The export timeout of each attempt can still be set with
+// the OTEL_EXPORTER_OTLP_TIMEOUT environment variable. If this option is not
+// provided, the exporter default policy is used: an initial interval of 5
+// seconds growing exponentially up to 30 seconds for a total of 1 minute.
+func WithRetryConfig(rc RetryConfig) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.RetryConfig = &rc
+	})
+}
+
+// WithGRPCDialOptions configures additional gRPC dial options used by the
+// OTLP exporters when the "grpc" protocol is used. The options are appended to
+// the ones computed by Run (e.g. the transport credentials), so they can be
+// used to add interceptors, keepalive parameters, or a custom resolver.
+//
+// Multiple uses of this option are additive. Nil options are ignored.
+func WithGRPCDialOptions(opts ...grpc.DialOption) Option {
+	return optionFunc(func(c *config) {
+		for _, o := range opts {
+			if o != nil {
+				c.ExportConfig.GRPCDialOptions = append(c.ExportConfig.GRPCDialOptions, o)
+			}
+		}
+	})
+}
+
+// WithGRPCKeepalive configures the keepalive parameters of the connections
+// used by the OTLP exporters when the "grpc" protocol is used.
+//
+// By default, a connection with an export in flight is pinged after 5
+// minutes of inactivity and closed if the ping is not acknowledged within 20
+// seconds. Idle connections are not pinged. A closed connection is re-dialed
+// on the next export, resolving the endpoint host again, so the exporters
+// recover when the address of the backend changes (e.g. a collector pod is
+// rescheduled) without the old address refusing connections.
+//
+// A Time lower than the minimum ping interval the server permits (5 minutes
+// unless its keepalive enforcement policy was changed) makes the server close
+// the connection. Setting Time to zero disables keepalive. Values passed with
+// WithGRPCDialOptions take precedence over this option.
+func WithGRPCKeepalive(params keepalive.ClientParameters) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.GRPCKeepalive = params
+	})
+}
+
+// WithMetricTemporality configures the temporality preference of the OTLP
+// metrics exporters. The supported values are "cumulative", "delta", and
+// "lowmemory", as defined by the OpenTelemetry specification.
+//
+// The passed preference takes precedence over the
+// OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE environment variable. Run
+// returns an error if the preference is not supported. By default, "delta" is
+// used as expected by Splunk Observability Cloud. This deviates from the
+// OpenTelemetry specification default of "cumulative".
+func WithMetricTemporality(preference string) Option {
+	return optionFunc(func(c *config) {
+		c.ExportConfig.MetricsTemporality = strings.ToLower(preference)
+	})
+}
+
+// WithPropagator configures the TextMapPropagator set as the global
+// propagator.
+//
+// The passed propagator takes precedence over the propagators configured
+// with the OTEL_PROPAGATORS environment variable, which is not used if this
+// option is provided. By default, the W3C tracecontext and baggage
+// propagators are used.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return optionFunc(func(c *config) {
+		c.Propagator = p
+	})
+}
+
+// WithSpanLimits configures the limits applied to the spans that are
+// recorded (e.g. the maximum number of attributes of a span).
+//
+// The passed limits are used as is and take precedence over the limits
+// configured with the OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT family of environment
+// variables. A limit of zero means that nothing is recorded for it, and a
+// negative limit means it is unlimited. Use trace.NewSpanLimits to start
+// from the OpenTelemetry defaults.
+//
+// If this option is not provided, the limits are set by those environment
+// variables, and Splunk defaults are used for any limit not set by them (the
+// link count is limited to 1000, the attribute value length is limited to
+// 12000, and all other limits are unlimited).
+func WithSpanLimits(limits trace.SpanLimits) Option {
+	return optionFunc(func(c *config) {
+		c.SpanLimits = &limits
+	})
+}
+
+// WithAttributeValueLengthLimit configures the maximum length of the string
+// (and string slice element) attribute values of the spans, their events,
+// and their links. The longer values are truncated to n bytes by the SDK, so
+// the values recorded by any instrumentation are truncated. A negative n
+// means the values are never truncated.
+//
+// This option takes precedence over the OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT
+// and OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT environment variables. It
+// overrides the limit passed with a prior WithSpanLimits option, and is
+// overridden by a subsequent one. By default, the limit is set by those
+// environment variables or is 12000.
+func WithAttributeValueLengthLimit(n int) Option {
+	return optionFunc(func(c *config) {
+		limits := *c.SpanLimits
+		limits.AttributeValueLengthLimit = n
+		c.SpanLimits = &limits
+	})
+}
+
+// WithAttributeTruncationMarker configures the attributes truncated to the
+// attribute value length limit to be marked with their original length. For
+// each truncated attribute, an attribute with the same key suffixed with
+// ".original_length" is added with the original length in bytes of the
+// value (or of each element of a string slice value).
+//
+// The values are then truncated when the spans end instead of when they are
+// recorded, for the processor exporting the spans with the configured
+// exporter and the processors passed with WithAdditionalSpanProcessor, after
+// the attributes are scrubbed with the functions passed with
+// WithAttributeScrubber. By default, the truncated attributes are not marked.
+func WithAttributeTruncationMarker() Option {
+	return optionFunc(func(c *config) {
+		c.TruncationMarker = true
+	})
+}
+
+// WithResource configures the resource describing the entity producing
+// telemetry.
+//
+// The passed resource is merged with the resource detected by default (e.g.
+// from the OTEL_RESOURCE_ATTRIBUTES environment variable, the process, and the
+// Go runtime). The attributes of the passed resource take precedence if the
+// same key is detected. Run returns an error if the schema URL of the passed
+// resource conflicts with the one of the detected resource.
+func WithResource(res *resource.Resource) Option {
+	return optionFunc(func(c *config) {
+		c.Resource = res
+	})
+}
+
+// WithResourceAttributes configures attributes of the resource describing
+// the entity producing telemetry, e.g. to set values containing commas or
+// equals signs without encoding them in the OTEL_RESOURCE_ATTRIBUTES
+// environment variable.
+//
+// The passed attributes take precedence over the ones of the
+// OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME environment variables and
+// of the resource detectors. The resource passed with WithResource, the name
+// passed with WithServiceName, and the namespace passed with
+// WithServiceNamespace take precedence over them. Multiple uses
+// of this option are additive, the last value of a key is used. The empty
+// keys are ignored.
+func WithResourceAttributes(attrs map[string]string) Option {
+	return optionFunc(func(c *config) {
+		if c.ResourceAttributes == nil {
+			c.ResourceAttributes = make(map[string]string, len(attrs))
+		}
+		for k, v := range attrs {
+			c.ResourceAttributes[k] = v
+		}
+	})
+}
+
+// WithServiceName configures the name of the service producing telemetry,
+// i.e. the service.name resource attribute.
+//
+// The passed name takes precedence over the OTEL_SERVICE_NAME environment
+// variable, the service.name attribute of the OTEL_RESOURCE_ATTRIBUTES
+// environment variable and of the attributes passed with
+// WithResourceAttributes, and the resource passed with WithResource. If the
+// service name is not configured, the base name of the executable is used.
+func WithServiceName(name string) Option {
+	return optionFunc(func(c *config) {
+		c.ServiceName = name
+	})
+}
+
+// WithServiceNamespace configures the namespace of the service producing
+// telemetry, i.e. the service.namespace resource attribute. The namespace
+// groups the services, e.g. to tell apart the "api" service of the
+// "payments" namespace from the one of the "checkout" namespace.
+//
+// The passed namespace takes precedence over the service.namespace attribute
+// of the OTEL_RESOURCE_ATTRIBUTES environment variable and of the attributes
+// passed with WithResourceAttributes, and the resource passed with
+// WithResource. By default, the service namespace is not set.
+func WithServiceNamespace(namespace string) Option {
+	return optionFunc(func(c *config) {
+		c.ServiceNamespace = namespace
+	})
+}
+
+// WithEnvironment configures the environment the service is deployed to
+// (e.g. "prod" or "staging"), i.e. the deployment.environment resource
+// attribute.
+//
+// The passed environment takes precedence over the SPLUNK_OTEL_ENV and
+// DEPLOYMENT_ENVIRONMENT environment variables, the deployment.environment
+// attribute of the OTEL_RESOURCE_ATTRIBUTES environment variable and of the
+// attributes passed with WithResourceAttributes, and the resource passed with
+// WithResource. If this option is not provided, the environment is set by
+// SPLUNK_OTEL_ENV, or DEPLOYMENT_ENVIRONMENT if it is unset, which take
+// precedence over OTEL_RESOURCE_ATTRIBUTES.
+func WithEnvironment(env string) Option {
+	return optionFunc(func(c *config) {
+		c.Environment = env
+	})
+}
+
+// WithTransportFallback configures the OTLP exporters using the gRPC protocol
+// to fall back to the http/protobuf protocol if the gRPC endpoint cannot be
+// reached when Run is called, e.g. in networks blocking gRPC. The telemetry
+// is then sent to port 4318 of the host of the gRPC endpoint (or to the
+// ingest endpoint of the realm), using TLS if the gRPC connection does. The
+// gRPC connection is checked once per signal, for at most 5 seconds, and the
+// fallback decision is logged.
+//
+// The fallback is not used if the OTLP exporters use the http/protobuf
+// protocol. By default, the exporters use the configured protocol even if
+// the endpoint cannot be reached.
+func WithTransportFallback() Option {
+	return optionFunc(func(c *config) {
+		c.TransportFallback = true
+	})
+}
+
+// WithSchemaURL configures the schema URL of the semantic conventions used by
+// the resource describing the entity producing telemetry (e.g.
+// "https://opentelemetry.io/schemas/1.17.0"), for the processors of the
+// telemetry relying on it. Run returns an error if it is not an absolute HTTP
+// or HTTPS URL. If this option is not provided, the schema URL of the
+// semantic conventions used by the OpenTelemetry SDK is used.
+//
+// The schema URL is set before the resource passed with WithResource is
+// merged. Resources with different non-empty schema URLs cannot be merged:
+// Run returns an error if the resource passed with WithResource has another
+// schema URL, and the resources of the detectors passed with
+// WithResourceDetectors are skipped if they have another schema URL than the
+// detected resource. The attributes are not converted to the passed schema:
+// make sure they are consistent with it.
+func WithSchemaURL(schemaURL string) Option {
+	return optionFunc(func(c *config) {
+		c.SchemaURL = schemaURL
+	})
+}
+
+// WithResourceDetectors configures additional detectors of the resource
+// describing the entity producing telemetry (e.g. a cloud provider detector).
+//
+// The detected resources are merged, in order, with the resource detected by
+// default. The resource passed to WithResource takes precedence over them.
+// Detection failures do not fail Run, they are logged and the resource of the
+// failing detector is not used.
+//
+// Detection is bounded by the timeout configured with
+// WithResourceDetectionTimeout.
+func WithResourceDetectors(detectors ...resource.Detector) Option {
+	return optionFunc(func(c *config) {
+		c.ResourceDetectors = append(c.ResourceDetectors, detectors...)
+	})
+}
+
+// WithHostDetection configures if the host resource detector is used to add
+// the host.* resource attributes (e.g. host.name).
+//
+// This option takes precedence over the OTEL_RESOURCE_DETECTORS environment
+// variable listing "host". The detection is bounded by the timeout configured
+// with WithResourceDetectionTimeout. By default, the detector is not used.
+func WithHostDetection(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.HostDetection = enabled
+	})
+}
+
+// WithDisabledInstrumentations configures the instrumentations not to be
+// started by Run. The only instrumentation started by Run is "runtime", the
+// Go runtime metrics. "host", the host metrics instrumentation, is also
+// accepted but is never started by Run, so disabling it has no effect. Run
+// returns an error if an unknown name is passed.
+//
+// The instrumentations listed in the OTEL_GO_DISABLED_INSTRUMENTATIONS
+// environment variable (comma-separated names) are also disabled. By
+// default, all the instrumentations are started.
+func WithDisabledInstrumentations(names ...string) Option {
+	return optionFunc(func(c *config) {
+		for _, name := range names {
+			c.DisabledInstrumentations = append(c.DisabledInstrumentations, strings.ToLower(strings.TrimSpace(name)))
+		}
+	})
+}
+
+// WithRuntimeMetrics configures if the Go runtime metrics (e.g. garbage
+// collection, goroutine, and memory metrics) are collected using the
+// go.opentelemetry.io/contrib/instrumentation/runtime instrumentation. The
+// metrics are only collected if a metrics exporter is used.
+//
+// This option takes precedence over the SPLUNK_RUNTIME_METRICS_ENABLED
+// environment variable. The metrics are not collected if "runtime" is
+// disabled with WithDisabledInstrumentations. By default, the metrics are
+// collected.
+func WithRuntimeMetrics(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.RuntimeMetrics = enabled
+	})
+}
+
+// WithRuntimeMetricsInterval configures the minimum interval between two
+// reads of the Go memory statistics by the runtime metrics instrumentation.
+// Reading them stops the world, so a larger interval reduces the overhead
+// while the memory metrics are updated less often. Run returns an error if
+// the interval is negative.
+//
+// A zero interval uses the default of 15 seconds.
+func WithRuntimeMetricsInterval(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.RuntimeMetricsInterval = d
+	})
+}
+
+// WithHTTPServerDurationBoundaries configures the bucket boundaries (in
+// milliseconds) of the http.server.duration histogram recorded with the
+// MeterProvider of the SDK by the handlers of the
+// github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp
+// package, e.g. to match service level objectives:
+//
+//	distro.Run(distro.WithHTTPServerDurationBoundaries(50, 100, 250, 500))
+//
+// Run returns an error if no boundary is passed, or if the boundaries are
+// not positive finite numbers sorted in increasing order. By default, the
+// Splunk preferred boundaries are used.
+func WithHTTPServerDurationBoundaries(boundaries ...float64) Option {
+	return optionFunc(func(c *config) {
+		c.HTTPServerDurationBoundaries = append([]float64{}, boundaries...)
+	})
+}
+
+// WithProfiler configures if the process is set up for Splunk AlwaysOn
+// Profiling: the profiles of the process are correlated with its spans.
+// Enabling it adds the following attributes to the resource:
+//
+//   - splunk.profiler.enabled: true
+//   - host.name: the host name of the process (the host detector is used even
+//     if disabled with WithHostDetection)
+//
+// The endpoint the profiling data is sent to is returned by the
+// ProfilerLogsEndpoint method of the SDK. The distribution does not collect
+// nor export profiles itself.
+//
+// By default, the SPLUNK_PROFILER_ENABLED environment variable is used. The
+// profiler is enabled if it is set to "true".
+func WithProfiler(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.Profiler = enabled
+	})
+}
+
+// WithProfilerLogsEndpoint configures the OTLP endpoint the profiling data
+// is sent to (as OTLP logs) when the profiler is enabled with WithProfiler.
+// Run returns an error if endpoint is not an http or https URL.
+//
+// By default, the SPLUNK_PROFILER_LOGS_ENDPOINT environment variable is used.
+// If it is not set, the endpoint passed with WithEndpoint, the
+// OTEL_EXPORTER_OTLP_ENDPOINT environment variable, or
+// "http://localhost:4317" is used, in that order.
+func WithProfilerLogsEndpoint(endpoint string) Option {
+	return optionFunc(func(c *config) {
+		c.ProfilerLogsEndpoint = endpoint
+	})
+}
+
+// WithBuildInfo configures the SDK to add the build information embedded in
+// the binary by the go command (see runtime/debug.ReadBuildInfo) to the
+// resource: the version of the main module as service.version, and the
+// vcs.revision, vcs.time, and vcs.modified attributes of the version control
+// settings (e.g. the commit hash).
+//
+// The attributes set by the OTEL_RESOURCE_ATTRIBUTES environment variable,
+// the resource detectors, WithResource, and WithServiceName take precedence.
+// Missing information is skipped, e.g. the module version of a binary built
+// with "go run" or the VCS settings of a binary built with -buildvcs=false.
+// By default, the build information is not added.
+func WithBuildInfo() Option {
+	return optionFunc(func(c *config) {
+		c.BuildInfo = true
+	})
+}
+
+// WithContainerDetection configures if the container resource detector is
+// used to add the container.id resource attribute read from the cgroup of the
+// process.
+//
+// This option takes precedence over the OTEL_RESOURCE_DETECTORS environment
+// variable listing "container". The detection is bounded by the timeout
+// configured with WithResourceDetectionTimeout. By default, the detector is
+// not used.
+func WithContainerDetection(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.ContainerDetection = enabled
+	})
+}
+
+// WithAWSResourceDetectors configures the Amazon EC2 and Amazon ECS resource
+// detectors to add the cloud.* and aws.* resource attributes when running on
+// AWS. It is equivalent to passing the detectors to WithResourceDetectors.
+func WithAWSResourceDetectors() Option {
+	return optionFunc(func(c *config) {
+		c.ResourceDetectors = append(c.ResourceDetectors,
+			ec2.NewResourceDetector(),
+			ecs.NewResourceDetector(),
+		)
+	})
+}
+
+// WithResourceDetectionTimeout configures the maximum duration the detectors
+// passed to WithResourceDetectors, and the host and container detectors, can
+// take. Detectors that do not complete
+// within the timeout are skipped. A non-positive timeout means detection is
+// not bounded.
+//
+// By default, a timeout of 5 seconds is used.
+func WithResourceDetectionTimeout(timeout time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.ResourceDetectionTimeout = timeout
+	})
+}
+
+// WithSampler configures the sampler used to decide which spans are
+// recorded and exported.
+//
+// The passed sampler takes precedence over the sampler configured with the
+// OTEL_TRACES_SAMPLER environment variable. If both are set, the conflict is
+// reported to the OpenTelemetry error handler.
+//
+// By default, all spans are sampled if OTEL_TRACES_SAMPLER is not set.
+func WithSampler(s trace.Sampler) Option {
+	return optionFunc(func(c *config) {
+		c.Sampler = s
+		c.DynamicSampling = false
+	})
+}
+
+// WithDynamicSamplingRatio configures the sampler to sample the root spans
+// with a ratio that can be updated while the application runs (e.g. to
+// increase the sampling during an incident) with SDK.SetSamplingRatio. The
+// ratio is initially set to ratio. The spans with a parent are sampled if the
+// parent is sampled, as with trace.ParentBased. The ratio is handled as by
+// trace.TraceIDRatioBased: a ratio >= 1 samples all the traces, and a ratio
+// <= 0 samples none.
+//
+// This option and WithSampler replace each other, the last one used takes
+// effect.
+func WithDynamicSamplingRatio(ratio float64) Option {
+	return optionFunc(func(c *config) {
+		c.Sampler = nil
+		c.DynamicSampling = true
+		c.DynamicSamplingRatio = ratio
+	})
+}
+
+// WithSamplingRatioReloadOnSIGHUP configures the SDK to set the ratio of the
+// sampler configured with WithDynamicSamplingRatio to the one returned by
+// load each time the process receives a SIGHUP signal (e.g. to read it from a
+// file). The ratio is kept if load returns an error, which is passed to the
+// OpenTelemetry error handler. The signal is no longer handled once the SDK
+// is shut down.
+//
+// Run returns an error if WithDynamicSamplingRatio is not used. A nil load
+// is ignored.
+func WithSamplingRatioReloadOnSIGHUP(load func() (float64, error)) Option {
+	return optionFunc(func(c *config) {
+		if load != nil {
+			c.SamplingRatioLoad = load
+		}
+	})
+}
+
+// WithBatchSpanProcessorOptions configures the batch span processor that
+// exports spans (e.g. the maximum queue size, the maximum export batch size,
+// the export timeout, and the delay between exports).
+//
+// The OTEL_BSP_MAX_QUEUE_SIZE, OTEL_BSP_MAX_EXPORT_BATCH_SIZE,
+// OTEL_BSP_EXPORT_TIMEOUT, and OTEL_BSP_SCHEDULE_DELAY environment variables
+// are used for any setting not configured with this option.
+//
+// The options are not used by the console exporter, which writes spans as
+// soon as they end.
+func WithBatchSpanProcessorOptions(opts ...trace.BatchSpanProcessorOption) Option {
+	return optionFunc(func(c *config) {
+		c.BSPOptions = append(c.BSPOptions, opts...)
+	})
+}
+
+// WithAdditionalSpanProcessor configures a SpanProcessor registered with
+// the TracerProvider in addition to the one exporting spans with the
+// configured exporter. It can be used to also send the spans somewhere else
+// (e.g. to stdout with a stdouttrace exporter while troubleshooting) or to
+// record them in memory (e.g. in tests).
+//
+// The processor is flushed by ForceFlush and shut down by Shutdown of the
+// returned SDK. It is used even if OTEL_TRACES_EXPORTER is set to "none".
+// Multiple uses of this option are additive. A nil processor is ignored.
+func WithAdditionalSpanProcessor(sp trace.SpanProcessor) Option {
+	return optionFunc(func(c *config) {
+		if sp != nil {
+			c.SpanProcessors = append(c.SpanProcessors, sp)
+		}
+	})
+}
+
+// WithAdditionalExporter configures a SpanExporter the spans are exported
+// with in addition to the configured exporter, e.g. to send the spans to
+// both a legacy Jaeger backend and Splunk Observability Cloud while
+// migrating.
+//
+// Each exporter is registered with its own BatchSpanProcessor, configured
+// with the options passed with WithBatchSpanProcessorOptions, so that a slow
+// or failing exporter does not delay the export of the others. The spans
+// queued for a blocked exporter are dropped once its queue is full. The
+// attributes are scrubbed as for the configured exporter.
+//
+// The exporter is flushed by ForceFlush and shut down by Shutdown of the
+// returned SDK. It is used even if OTEL_TRACES_EXPORTER is set to "none".
+// Multiple uses of this option are additive. A nil exporter is ignored.
+func WithAdditionalExporter(exp trace.SpanExporter) Option {
+	return optionFunc(func(c *config) {
+		if exp != nil {
+			c.SpanExporters = append(c.SpanExporters, exp)
+		}
+	})
+}
+
+// WithPrometheusExporter configures a metrics exporter registering the
+// metrics with registry for them to be scraped by Prometheus. Mount the
+// handler returned by the PrometheusHandler method of the SDK (e.g. at
+// /metrics) to expose them in the Prometheus text format. If registry is nil,
+// a new registry is used.
+//
+// The metrics are also exported with the exporter set by the
+// OTEL_METRICS_EXPORTER environment variable, unless it is set to "none". By
+// default, the metrics are not exposed to Prometheus.
+func WithPrometheusExporter(registry *prometheus.Registry) Option {
+	return optionFunc(func(c *config) {
+		if registry == nil {
+			registry = prometheus.NewRegistry()
+		}
+		c.PrometheusRegistry = registry
+	})
+}
+
+// WithSpanStartHook configures a hook called when a span is started, e.g. to
+// stamp every span with attributes from the baggage without modifying each
+// instrumentation (see BaggageAttributesHook). The hook is called by a span
+// processor, synchronously with the start of the span: it has to be fast and
+// safe for concurrent use.
+//
+// The hooks are called in the order they are passed, before the span
+// processors passed with WithAdditionalSpanProcessor. Multiple uses of this
+// option are additive. A nil hook is ignored.
+func WithSpanStartHook(hook SpanStartHook) Option {
+	return optionFunc(func(c *config) {
+		if hook != nil {
+			c.SpanStartHooks = append(c.SpanStartHooks, hook)
+		}
+	})
+}
+
+// WithHECLogsExporter configures a logs exporter sending the log records to
+// the Splunk HTTP Event Collector (HEC) of Splunk Enterprise or Splunk Cloud
+// Platform at rawURL, authenticated with token. If rawURL has no path, the
+// /services/collector/event path is used.
+//
+// The records are emitted with the log/slog Handler returned by the
+// LogHandler method of the SDK (Go 1.21 or later). They are batched and sent
+// as HEC events once the batch is full or periodically, with the message as
+// event, the record time, and the level, the attributes of the record and of
+// the resource (except host.name used as host) as indexed fields. The batches
+// are sent by ForceFlush and Shutdown of the returned SDK. Errors are handled
+// by the registered ErrorHandler.
+//
+// Run returns an error if the URL or the token is invalid. By default, the
+// logs are not exported.
+func WithHECLogsExporter(rawURL, token string, opts ...HECOption) Option {
+	return optionFunc(func(c *config) {
+		c.HECLogs = newHECConfig(rawURL, token, opts...)
+	})
+}
+
+// WithAttributeScrubber configures a function scrubbing the attributes of the
+// ended spans before they are exported, e.g. to redact personally
+// identifiable information (PII) accidentally recorded by the
+// instrumentation. The function is called with each attribute of the span and
+// returns the attribute to export, or false to drop it. See NewPIIScrubber
+// for a scrubber redacting email addresses and credit card numbers.
+//
+// The attributes are scrubbed for the processor exporting the spans with the
+// configured exporter and the processors passed with
+// WithAdditionalSpanProcessor. The attributes of the span events and links,
+// and the resource are not scrubbed. Multiple uses of this option are
+// additive, the functions are called in order. A nil function is ignored.
+func WithAttributeScrubber(fn func(attribute.KeyValue) (attribute.KeyValue, bool)) Option {
+	return optionFunc(func(c *config) {
+		if fn != nil {
+			c.AttributeScrubbers = append(c.AttributeScrubbers, fn)
+		}
+	})
+}
+
+// WithMaxSpanDuration configures the maximum duration of the spans. The spans
+// still active (i.e. not ended) after this duration, for example the ones
+// leaked without being ended, are ended by a background goroutine checking
+// the active spans at an interval of half the duration (at most a minute).
+// A "span.exceeded_max_duration" event with a "span.max_duration" attribute
+// is recorded on these spans before they are ended and exported. Their end
+// time is the time they are ended at, and the later calls to their End method
+// are ignored.
+//
+// Run returns an error if the duration is negative. By default, the spans
+// are never ended by the SDK.
+func WithMaxSpanDuration(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.MaxSpanDuration = d
+	})
+}
+
+// WithErrorHandler configures the ErrorHandler Run registers as the global
+// OpenTelemetry ErrorHandler. It handles errors the SDK cannot return (e.g.
+// export failures).
+//
+// The ErrorHandler is unregistered when the returned SDK is shut down. By
+// default, an ErrorHandler logging errors with the logger configured by
+// WithLogger is used.
+func WithErrorHandler(h otel.ErrorHandler) Option {
+	return optionFunc(func(c *config) {
+		c.ErrorHandler = h
+	})
+}
+
+// WithShutdownTimeout configures the maximum duration the shutdown of each
+// telemetry provider can take when the context passed to SDK.Shutdown does
+// not have a deadline. A non-positive timeout means the shutdown is only
+// bounded by the passed context.
+//
+// By default, a timeout of 30 seconds is used.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.ShutdownTimeout = timeout
+	})
+}
+
+// WithGlobalRegistration configures whether Run sets the configured
+// TracerProvider and MeterProvider as the global OpenTelemetry providers.
+//
+// If false is passed, the global providers are left unchanged and the
+// configured providers have to be retrieved with the SDK TracerProvider and
+// MeterProvider methods and passed explicitly to the instrumentation. This
+// allows running multiple isolated SDKs in a single process. The propagator,
+// error handler, and logger are still set globally.
+//
+// By default, the providers are registered globally.
+func WithGlobalRegistration(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.GlobalRegistration = enabled
+	})
+}
+
 // WithLogger configures the logger used by this distro.
 //
 // The logr.Logger provided should be configured with a verbosity enabled to
@@ -140,8 +1788,12 @@ func WithTLSConfig(conf *tls.Config) Option {
 //   - info: 1
 //   - debug: 2+
 //
-// By default, a zapr.Logger configured for info logging will be used if this
-// is not provided.
+// The logger is also set as the global OpenTelemetry logger. Run logs a
+// summary of the effective configuration at the info level.
+//
+// If this option is not provided, a zapr.Logger configured to log at the level
+// set by the OTEL_LOG_LEVEL environment variable is used. No logs are emitted
+// if OTEL_LOG_LEVEL is not set.
 func WithLogger(l logr.Logger) Option {
 	return optionFunc(func(c *config) {
 		c.Logger = l
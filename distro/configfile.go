@@ -0,0 +1,146 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/contrib/propagators/autoprop"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the configuration loaded from the YAML file passed with
+// WithConfigFile. Empty values are not set.
+type fileConfig struct {
+	// Endpoint is the endpoint of the exporters, as set with WithEndpoint.
+	Endpoint string `yaml:"endpoint"`
+	// AccessToken is the Splunk Observability Cloud access token, as set by
+	// SPLUNK_ACCESS_TOKEN.
+	AccessToken string `yaml:"access_token"`
+	// Sampler is the name of the sampler, as set by OTEL_TRACES_SAMPLER.
+	Sampler string `yaml:"sampler"`
+	// SamplerArg is the argument of the sampler, as set by
+	// OTEL_TRACES_SAMPLER_ARG.
+	SamplerArg string `yaml:"sampler_arg"`
+	// Propagators are the names of the propagators, as set by
+	// OTEL_PROPAGATORS.
+	Propagators []string `yaml:"propagators"`
+	// ResourceAttributes are the resource attributes, as set by
+	// OTEL_RESOURCE_ATTRIBUTES.
+	ResourceAttributes map[string]string `yaml:"resource_attributes"`
+}
+
+// loadConfigFile returns the configuration read from the YAML file at path.
+// An error is returned if the file cannot be read, is not valid YAML, or
+// has unknown fields.
+func loadConfigFile(path string) (*fileConfig, error) {
+	f, err := os.Open(path) //nolint:gosec // The path is set by the application.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer f.Close()
+
+	var fc fileConfig
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &fc, nil
+}
+
+// applyConfigFile sets the values of fc not set by the options nor by the
+// environment variables.
+func (c *config) applyConfigFile(fc *fileConfig) error {
+	e := c.ExportConfig
+	if fc.Endpoint != "" && e.Endpoint == "" && !notNone(e.Realm) && noneEnvVarSet(
+		otelExporterOTLPEndpointKey,
+		otelExporterOTLPTracesEndpointKey,
+		otelExporterOTLPMetricsEndpointKey,
+		otelExporterJaegerEndpointKey,
+		splunkRealmKey,
+	) {
+		e.Endpoint = fc.Endpoint
+	}
+
+	if fc.AccessToken != "" && e.AccessToken == "" {
+		e.AccessToken = fc.AccessToken
+	}
+
+	if fc.Sampler != "" && c.Sampler == nil && !c.DynamicSampling && noneEnvVarSet(tracesSamplerKey) {
+		s, err := samplerFromName(fc.Sampler, fc.SamplerArg)
+		if err != nil {
+			return fmt.Errorf("invalid config file sampler: %w", err)
+		}
+		c.Sampler = s
+	}
+
+	if len(fc.Propagators) > 0 && c.Propagator == nil && noneEnvVarSet(otelPropagatorsKey) {
+		p, err := autoprop.TextMapPropagator(fc.Propagators...)
+		if err != nil {
+			return fmt.Errorf("invalid config file propagators: %w", err)
+		}
+		c.Propagator, c.PropagatorNames = p, fc.Propagators
+	}
+
+	c.FileResourceAttributes = fc.ResourceAttributes
+	return nil
+}
+
+// samplerFromName returns the sampler with the name and argument defined by
+// the OpenTelemetry specification for the OTEL_TRACES_SAMPLER and
+// OTEL_TRACES_SAMPLER_ARG environment variables. The ratio of the trace ID
+// ratio based samplers is 1 if arg is empty.
+func samplerFromName(name, arg string) (trace.Sampler, error) {
+	ratio := func() (float64, error) {
+		if arg == "" {
+			return 1, nil
+		}
+		r, err := strconv.ParseFloat(arg, 64)
+		if err != nil || r < 0 || r > 1 {
+			return 0, fmt.Errorf("invalid ratio %q: must be a number between 0 and 1", arg)
+		}
+		return r, nil
+	}
+
+	switch name {
+	case "always_on":
+		return trace.AlwaysSample(), nil
+	case "always_off":
+		return trace.NeverSample(), nil
+	case "traceidratio":
+		r, err := ratio()
+		if err != nil {
+			return nil, err
+		}
+		return trace.TraceIDRatioBased(r), nil
+	case "parentbased_always_on":
+		return trace.ParentBased(trace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return trace.ParentBased(trace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		r, err := ratio()
+		if err != nil {
+			return nil, err
+		}
+		return trace.ParentBased(trace.TraceIDRatioBased(r)), nil
+	}
+	return nil, fmt.Errorf("unknown sampler %q", name)
+}
@@ -0,0 +1,159 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const testConfigFile = "testdata/config.yaml"
+
+// writeConfigFile writes content to a config file and returns its path.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func resourceValue(t *testing.T, c *config, key attribute.Key) string {
+	t.Helper()
+
+	res, err := newResource(context.Background(), c)
+	require.NoError(t, err)
+	v, _ := res.Set().Value(key)
+	return v.AsString()
+}
+
+func TestWithConfigFile(t *testing.T) {
+	c := newTestConfig(t, WithConfigFile(testConfigFile))
+
+	assert.Equal(t, "http://collector:4317", c.ExportConfig.Endpoint)
+	assert.Equal(t, "file-token", c.ExportConfig.AccessToken)
+	assert.Equal(t, sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.25)).Description(), c.Sampler.Description())
+	assert.Equal(t, []string{"tracecontext", "b3"}, c.PropagatorNames)
+	assert.Contains(t, c.Propagator.Fields(), "traceparent")
+	assert.Contains(t, c.Propagator.Fields(), "x-b3-traceid")
+	assert.Equal(t, "checkout", resourceValue(t, c, "service.name"))
+	assert.Equal(t, "file", resourceValue(t, c, "deployment.environment"))
+}
+
+func TestWithConfigFileEnvPrecedence(t *testing.T) {
+	t.Setenv(otelExporterOTLPEndpointKey, "http://env:4317")
+	t.Setenv(accessTokenKey, "env-token")
+	t.Setenv(tracesSamplerKey, "always_off")
+	t.Setenv(otelPropagatorsKey, "baggage")
+	t.Setenv(otelResourceAttributesKey, "deployment.environment=env")
+
+	c := newTestConfig(t, WithConfigFile(testConfigFile))
+
+	assert.Empty(t, c.ExportConfig.Endpoint, "endpoint environment variable must be used")
+	assert.Equal(t, "env-token", c.ExportConfig.AccessToken)
+	assert.Nil(t, c.Sampler, "sampler environment variable must be used")
+	assert.Equal(t, []string{"baggage"}, c.PropagatorNames)
+	assert.Equal(t, "env", resourceValue(t, c, "deployment.environment"))
+	assert.Equal(t, "checkout", resourceValue(t, c, "service.name"), "file attributes must be merged")
+}
+
+func TestWithConfigFileOptionPrecedence(t *testing.T) {
+	t.Setenv(accessTokenKey, "env-token")
+
+	c := newTestConfig(t,
+		WithEndpoint("https://option:4317"),
+		WithAccessToken("option-token"),
+		WithSampler(sdktrace.AlwaysSample()),
+		WithPropagator(propagation.TraceContext{}),
+		WithResourceAttributes(map[string]string{"service.name": "option"}),
+		// The file is applied after all the options, whatever their order.
+		WithConfigFile(testConfigFile),
+	)
+
+	assert.Equal(t, "https://option:4317", c.ExportConfig.Endpoint)
+	assert.Equal(t, "option-token", c.ExportConfig.AccessToken)
+	assert.Equal(t, sdktrace.AlwaysSample().Description(), c.Sampler.Description())
+	assert.Equal(t, []string{"custom"}, c.PropagatorNames)
+	assert.Equal(t, "option", resourceValue(t, c, "service.name"))
+}
+
+func TestWithConfigFileRealmPrecedence(t *testing.T) {
+	c := newTestConfig(t, WithRealm("us0"), WithAccessToken("token"), WithConfigFile(testConfigFile))
+	assert.Empty(t, c.ExportConfig.Endpoint, "realm must be used")
+}
+
+func TestWithConfigFileEmpty(t *testing.T) {
+	c := newTestConfig(t, WithConfigFile(writeConfigFile(t, "")))
+	assert.Empty(t, c.ExportConfig.Endpoint)
+	assert.Nil(t, c.Sampler)
+	assert.Equal(t, []string{"tracecontext", "baggage"}, c.PropagatorNames)
+}
+
+func TestWithConfigFileInvalid(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		content string
+		wantErr string
+	}{
+		{desc: "unknown field", content: "endpont: http://collector:4317", wantErr: "invalid config file"},
+		{desc: "not YAML", content: "endpoint: [", wantErr: "invalid config file"},
+		{desc: "unknown sampler", content: "sampler: sometimes", wantErr: "invalid config file sampler"},
+		{desc: "invalid sampler ratio", content: "sampler: traceidratio\nsampler_arg: \"2\"", wantErr: "invalid config file sampler"},
+		{desc: "unknown propagator", content: "propagators: [unknown]", wantErr: "invalid config file propagators"},
+		{desc: "invalid endpoint", content: "endpoint: collector:4317", wantErr: "invalid"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := newConfig(WithConfigFile(writeConfigFile(t, tc.content)))
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+
+	_, err := newConfig(WithConfigFile(filepath.Join(t.TempDir(), "missing.yaml")))
+	assert.ErrorContains(t, err, "failed to read config file")
+}
+
+func TestSamplerFromName(t *testing.T) {
+	testCases := []struct {
+		name, arg string
+		want      sdktrace.Sampler
+	}{
+		{name: "always_on", want: sdktrace.AlwaysSample()},
+		{name: "always_off", want: sdktrace.NeverSample()},
+		{name: "traceidratio", want: sdktrace.TraceIDRatioBased(1)},
+		{name: "traceidratio", arg: "0.5", want: sdktrace.TraceIDRatioBased(0.5)},
+		{name: "parentbased_always_on", want: sdktrace.ParentBased(sdktrace.AlwaysSample())},
+		{name: "parentbased_always_off", want: sdktrace.ParentBased(sdktrace.NeverSample())},
+		{name: "parentbased_traceidratio", arg: "0.1", want: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.1))},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name+":"+tc.arg, func(t *testing.T) {
+			got, err := samplerFromName(tc.name, tc.arg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want.Description(), got.Description())
+		})
+	}
+
+	_, err := samplerFromName("traceidratio", "half")
+	assert.Error(t, err)
+}
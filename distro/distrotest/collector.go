@@ -0,0 +1,177 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distrotest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	cmpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	ctpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"go.uber.org/multierr"
+	"google.golang.org/grpc"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+// Collector is an in-memory OTLP gRPC receiver. It collects the spans
+// exported to it and accepts, but drops, the exported metrics. It listens on
+// a local loopback port and does not use TLS.
+//
+// Use NewCollector or Run to create a Collector. It is safe for concurrent
+// use.
+type Collector struct {
+	endpoint string
+	srv      *grpc.Server
+	done     chan error
+
+	traces  *traceService
+	metrics *metricsService
+}
+
+// NewCollector returns a started Collector. Close the Collector once it is
+// no longer used to release its resources.
+func NewCollector() (*Collector, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Collector{
+		endpoint: "http://" + ln.Addr().String(),
+		srv:      grpc.NewServer(),
+		done:     make(chan error, 1),
+		traces:   &traceService{},
+		metrics:  &metricsService{},
+	}
+	ctpb.RegisterTraceServiceServer(c.srv, c.traces)
+	cmpb.RegisterMetricsServiceServer(c.srv, c.metrics)
+	go func() {
+		err := c.srv.Serve(ln)
+		if errors.Is(err, grpc.ErrServerStopped) {
+			// Closed before serving.
+			err = nil
+		}
+		c.done <- err
+	}()
+
+	return c, nil
+}
+
+// Endpoint returns the URL of the endpoint the Collector receives the OTLP
+// gRPC exports on (e.g. "http://127.0.0.1:54321").
+func (c *Collector) Endpoint() string {
+	return c.endpoint
+}
+
+// Options returns the distro options configuring the OTLP exporters to
+// export to the Collector.
+func (c *Collector) Options() []distro.Option {
+	return []distro.Option{
+		distro.WithEndpoint(c.endpoint),
+		distro.WithOTLPProtocol("grpc"),
+	}
+}
+
+// Spans returns the spans collected so far, in the order they were received.
+func (c *Collector) Spans() tracetest.SpanStubs {
+	return c.traces.Spans()
+}
+
+// Reset discards the spans collected so far.
+func (c *Collector) Reset() {
+	c.traces.Reset()
+}
+
+// Close stops the Collector. It waits for the pending exports to be received.
+// The collected spans are still available once the Collector is closed.
+func (c *Collector) Close() error {
+	c.srv.GracefulStop()
+	err := <-c.done
+	// Report the error only once if Close is called again.
+	c.done <- nil
+	return err
+}
+
+// Run starts a Collector and calls distro.Run with opts and the Collector
+// Options, which take precedence over opts. As distro.Run is used, the
+// OpenTelemetry SDK is installed globally unless
+// distro.WithGlobalRegistration(false) is passed.
+//
+// The returned shutdown function shuts down the SDK, exporting all the
+// pending spans, and then closes the Collector. Call it before asserting the
+// collected spans.
+//
+// The OTEL_TRACES_EXPORTER environment variable needs to be unset or set to
+// "otlp" for the spans to be exported.
+func Run(opts ...distro.Option) (*Collector, func(context.Context) error, error) {
+	c, err := NewCollector()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sdk, err := distro.Run(append(opts, c.Options()...)...)
+	if err != nil {
+		return nil, nil, multierr.Append(err, c.Close())
+	}
+
+	shutdown := func(ctx context.Context) error {
+		return multierr.Append(sdk.Shutdown(ctx), c.Close())
+	}
+	return c, shutdown, nil
+}
+
+type traceService struct {
+	ctpb.UnimplementedTraceServiceServer
+
+	mu    sync.Mutex
+	spans tracetest.SpanStubs
+}
+
+func (s *traceService) Export(_ context.Context, req *ctpb.ExportTraceServiceRequest) (*ctpb.ExportTraceServiceResponse, error) {
+	spans := spanStubs(req.GetResourceSpans())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spans = append(s.spans, spans...)
+
+	return &ctpb.ExportTraceServiceResponse{}, nil
+}
+
+func (s *traceService) Spans() tracetest.SpanStubs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spans := make(tracetest.SpanStubs, len(s.spans))
+	copy(spans, s.spans)
+	return spans
+}
+
+func (s *traceService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spans = nil
+}
+
+type metricsService struct {
+	cmpb.UnimplementedMetricsServiceServer
+}
+
+func (s *metricsService) Export(context.Context, *cmpb.ExportMetricsServiceRequest) (*cmpb.ExportMetricsServiceResponse, error) {
+	return &cmpb.ExportMetricsServiceResponse{}, nil
+}
@@ -0,0 +1,271 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distrotest_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/goleak"
+	"go.uber.org/multierr"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+	"github.com/signalfx/splunk-otel-go/distro/distrotest"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) Handle(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) Errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.errs
+}
+
+func setup(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_METRICS_EXPORTER", "otlp")
+	t.Setenv("OTEL_SERVICE_NAME", "distrotest")
+}
+
+func TestRun(t *testing.T) {
+	setup(t)
+	errs := &errorRecorder{}
+	coll, shutdown, err := distrotest.Run(distro.WithErrorHandler(errs))
+	require.NoError(t, err)
+
+	tracer := otel.Tracer("scope", trace.WithInstrumentationVersion("v1.2.3"))
+	ctx := context.Background()
+	_, linked := tracer.Start(ctx, "linked")
+	linked.End()
+
+	ctx, parent := tracer.Start(ctx, "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "child",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithLinks(trace.Link{
+			SpanContext: linked.SpanContext(),
+			Attributes:  []attribute.KeyValue{attribute.String("link", "value")},
+		}),
+		trace.WithAttributes(
+			attribute.String("string", "value"),
+			attribute.Bool("bool", true),
+			attribute.Int64("int", 42),
+			attribute.Float64("float", 4.2),
+			attribute.StringSlice("strings", []string{"a", "b"}),
+			attribute.BoolSlice("bools", []bool{true, false}),
+			attribute.Int64Slice("ints", []int64{1, 2}),
+			attribute.Float64Slice("floats", []float64{1.5, 2.5}),
+		),
+	)
+	child.AddEvent("event", trace.WithAttributes(attribute.String("event", "value")))
+	child.SetStatus(codes.Error, "failure")
+	child.End()
+	parent.SetStatus(codes.Ok, "")
+	parent.End()
+
+	require.NoError(t, shutdown(context.Background()))
+	assert.Empty(t, errs.Errors(), "metrics exports need to be accepted")
+
+	spans := coll.Spans()
+	require.Len(t, spans, 3)
+	byName := make(map[string]int, len(spans))
+	for i, s := range spans {
+		byName[s.Name] = i
+	}
+	l, p, c := spans[byName["linked"]], spans[byName["parent"]], spans[byName["child"]]
+
+	assert.Equal(t, linked.SpanContext().TraceID(), l.SpanContext.TraceID())
+	assert.Equal(t, linked.SpanContext().SpanID(), l.SpanContext.SpanID())
+	assert.True(t, l.SpanContext.IsSampled())
+	assert.False(t, l.Parent.IsValid())
+	assert.Equal(t, trace.SpanKindInternal, l.SpanKind)
+
+	assert.Equal(t, parent.SpanContext().SpanID(), p.SpanContext.SpanID())
+	assert.Equal(t, trace.SpanKindServer, p.SpanKind)
+	assert.Equal(t, codes.Ok, p.Status.Code)
+	assert.False(t, p.StartTime.IsZero())
+	assert.False(t, p.EndTime.Before(p.StartTime))
+
+	assert.Equal(t, child.SpanContext().SpanID(), c.SpanContext.SpanID())
+	assert.Equal(t, p.SpanContext.TraceID(), c.Parent.TraceID())
+	assert.Equal(t, p.SpanContext.SpanID(), c.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, c.SpanKind)
+	assert.Equal(t, codes.Error, c.Status.Code)
+	assert.Equal(t, "failure", c.Status.Description)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("string", "value"),
+		attribute.Bool("bool", true),
+		attribute.Int64("int", 42),
+		attribute.Float64("float", 4.2),
+		attribute.StringSlice("strings", []string{"a", "b"}),
+		attribute.BoolSlice("bools", []bool{true, false}),
+		attribute.Int64Slice("ints", []int64{1, 2}),
+		attribute.Float64Slice("floats", []float64{1.5, 2.5}),
+	}, c.Attributes)
+	require.Len(t, c.Events, 1)
+	assert.Equal(t, "event", c.Events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("event", "value")}, c.Events[0].Attributes)
+	assert.False(t, c.Events[0].Time.IsZero())
+	require.Len(t, c.Links, 1)
+	assert.Equal(t, linked.SpanContext().SpanID(), c.Links[0].SpanContext.SpanID())
+	assert.Equal(t, []attribute.KeyValue{attribute.String("link", "value")}, c.Links[0].Attributes)
+
+	for _, s := range spans {
+		assert.Equal(t, "scope", s.InstrumentationLibrary.Name)
+		assert.Equal(t, "v1.2.3", s.InstrumentationLibrary.Version)
+		assert.Contains(t, s.Resource.Attributes(), semconv.ServiceName("distrotest"))
+		assert.Contains(t, s.Resource.Attributes(), attribute.String("splunk.distro.version", distro.Version()))
+	}
+}
+
+func TestRunInvalidOption(t *testing.T) {
+	setup(t)
+	coll, shutdown, err := distrotest.Run(distro.WithHeaders(map[string]string{"bad header": "value"}))
+	assert.ErrorContains(t, err, "invalid headers")
+	assert.Nil(t, coll)
+	assert.Nil(t, shutdown)
+}
+
+func TestRunCollectorOptionsTakePrecedence(t *testing.T) {
+	setup(t)
+	coll, shutdown, err := distrotest.Run(
+		distro.WithEndpoint("https://localhost:1"),
+		distro.WithOTLPProtocol("http/protobuf"),
+	)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer(t.Name()).Start(context.Background(), "span")
+	span.End()
+
+	require.NoError(t, shutdown(context.Background()))
+	assert.Len(t, coll.Spans(), 1)
+}
+
+func TestCollectorReset(t *testing.T) {
+	setup(t)
+	coll, err := distrotest.NewCollector()
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, coll.Close()) }()
+
+	sdk, err := distro.Run(append(coll.Options(), distro.WithGlobalRegistration(false))...)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, sdk.Shutdown(context.Background())) }()
+	tracer := sdk.TracerProvider().Tracer(t.Name())
+
+	ctx := context.Background()
+	_, span := tracer.Start(ctx, "before")
+	span.End()
+	require.NoError(t, sdk.ForceFlush(ctx))
+	require.Len(t, coll.Spans(), 1)
+
+	coll.Reset()
+	assert.Empty(t, coll.Spans())
+
+	_, span = tracer.Start(ctx, "after")
+	span.End()
+	require.NoError(t, sdk.ForceFlush(ctx))
+	spans := coll.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "after", spans[0].Name)
+}
+
+func TestCollectorSpansCopy(t *testing.T) {
+	setup(t)
+	coll, shutdown, err := distrotest.Run()
+	require.NoError(t, err)
+
+	_, span := otel.Tracer(t.Name()).Start(context.Background(), "span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	spans := coll.Spans()
+	require.Len(t, spans, 1)
+	spans[0].Name = "modified"
+	assert.Equal(t, "span", coll.Spans()[0].Name)
+}
+
+func TestCollectorClose(t *testing.T) {
+	coll, err := distrotest.NewCollector()
+	require.NoError(t, err)
+	assert.Regexp(t, "^http://127.0.0.1:[0-9]+$", coll.Endpoint())
+
+	assert.NoError(t, coll.Close())
+	assert.NoError(t, coll.Close(), "closing twice must not fail")
+	assert.Empty(t, coll.Spans())
+}
+
+func TestCollectorConcurrentExports(t *testing.T) {
+	setup(t)
+	coll, err := distrotest.NewCollector()
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, coll.Close()) }()
+
+	const (
+		sdks  = 4
+		spans = 50
+	)
+	var wg sync.WaitGroup
+	errCh := make(chan error, sdks)
+	for i := 0; i < sdks; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sdk, err := distro.Run(append(coll.Options(), distro.WithGlobalRegistration(false))...)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			tracer := sdk.TracerProvider().Tracer(t.Name())
+			for j := 0; j < spans; j++ {
+				_, span := tracer.Start(context.Background(), fmt.Sprintf("span %d-%d", i, j))
+				span.End()
+				_ = coll.Spans()
+			}
+			errCh <- sdk.Shutdown(context.Background())
+		}(i)
+	}
+	wg.Wait()
+	close(errCh)
+
+	var errs []error
+	for err := range errCh {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	require.NoError(t, multierr.Combine(errs...))
+	assert.Len(t, coll.Spans(), sdks*spans)
+}
@@ -0,0 +1,222 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distrotest
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceapi "go.opentelemetry.io/otel/trace"
+	cpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tpb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// spanStubs returns the spans of the OTLP resource spans.
+func spanStubs(rss []*tpb.ResourceSpans) tracetest.SpanStubs {
+	var spans tracetest.SpanStubs
+	for _, rs := range rss {
+		res := resource.NewWithAttributes(rs.GetSchemaUrl(), attributes(rs.GetResource().GetAttributes())...)
+		for _, ss := range rs.GetScopeSpans() {
+			scope := instrumentation.Scope{
+				Name:      ss.GetScope().GetName(),
+				Version:   ss.GetScope().GetVersion(),
+				SchemaURL: ss.GetSchemaUrl(),
+			}
+			for _, s := range ss.GetSpans() {
+				spans = append(spans, spanStub(s, res, scope))
+			}
+		}
+	}
+	return spans
+}
+
+func spanStub(s *tpb.Span, res *resource.Resource, scope instrumentation.Scope) tracetest.SpanStub {
+	stub := tracetest.SpanStub{
+		Name:                   s.GetName(),
+		SpanContext:            spanContext(s.GetTraceId(), s.GetSpanId(), s.GetTraceState()),
+		SpanKind:               spanKind(s.GetKind()),
+		StartTime:              timestamp(s.GetStartTimeUnixNano()),
+		EndTime:                timestamp(s.GetEndTimeUnixNano()),
+		Attributes:             attributes(s.GetAttributes()),
+		DroppedAttributes:      int(s.GetDroppedAttributesCount()),
+		DroppedEvents:          int(s.GetDroppedEventsCount()),
+		DroppedLinks:           int(s.GetDroppedLinksCount()),
+		Status:                 status(s.GetStatus()),
+		Resource:               res,
+		InstrumentationLibrary: scope,
+	}
+	if len(s.GetParentSpanId()) > 0 {
+		stub.Parent = spanContext(s.GetTraceId(), s.GetParentSpanId(), "")
+	}
+	for _, e := range s.GetEvents() {
+		stub.Events = append(stub.Events, trace.Event{
+			Name:                  e.GetName(),
+			Attributes:            attributes(e.GetAttributes()),
+			DroppedAttributeCount: int(e.GetDroppedAttributesCount()),
+			Time:                  timestamp(e.GetTimeUnixNano()),
+		})
+	}
+	for _, l := range s.GetLinks() {
+		stub.Links = append(stub.Links, trace.Link{
+			SpanContext:           spanContext(l.GetTraceId(), l.GetSpanId(), l.GetTraceState()),
+			Attributes:            attributes(l.GetAttributes()),
+			DroppedAttributeCount: int(l.GetDroppedAttributesCount()),
+		})
+	}
+	return stub
+}
+
+// spanContext returns the SpanContext with the trace and span IDs. The
+// exported spans are sampled, the trace flags are set accordingly. An
+// invalid trace state is ignored.
+func spanContext(traceID, spanID []byte, traceState string) traceapi.SpanContext {
+	cfg := traceapi.SpanContextConfig{TraceFlags: traceapi.FlagsSampled}
+	copy(cfg.TraceID[:], traceID)
+	copy(cfg.SpanID[:], spanID)
+	if ts, err := traceapi.ParseTraceState(traceState); err == nil {
+		cfg.TraceState = ts
+	}
+	return traceapi.NewSpanContext(cfg)
+}
+
+func spanKind(k tpb.Span_SpanKind) traceapi.SpanKind {
+	switch k {
+	case tpb.Span_SPAN_KIND_INTERNAL:
+		return traceapi.SpanKindInternal
+	case tpb.Span_SPAN_KIND_SERVER:
+		return traceapi.SpanKindServer
+	case tpb.Span_SPAN_KIND_CLIENT:
+		return traceapi.SpanKindClient
+	case tpb.Span_SPAN_KIND_PRODUCER:
+		return traceapi.SpanKindProducer
+	case tpb.Span_SPAN_KIND_CONSUMER:
+		return traceapi.SpanKindConsumer
+	default:
+		return traceapi.SpanKindUnspecified
+	}
+}
+
+func status(s *tpb.Status) trace.Status {
+	switch s.GetCode() {
+	case tpb.Status_STATUS_CODE_OK:
+		return trace.Status{Code: codes.Ok}
+	case tpb.Status_STATUS_CODE_ERROR:
+		return trace.Status{Code: codes.Error, Description: s.GetMessage()}
+	default:
+		return trace.Status{Code: codes.Unset}
+	}
+}
+
+func timestamp(unixNano uint64) time.Time {
+	if unixNano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(unixNano))
+}
+
+// attributes returns the attributes of the OTLP key-values. Values that
+// cannot be represented as an attribute (e.g. maps) are converted to their
+// string representation.
+func attributes(kvs []*cpb.KeyValue) []attribute.KeyValue {
+	if len(kvs) == 0 {
+		return nil
+	}
+	attrs := make([]attribute.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		attrs = append(attrs, attribute.KeyValue{
+			Key:   attribute.Key(kv.GetKey()),
+			Value: value(kv.GetValue()),
+		})
+	}
+	return attrs
+}
+
+func value(v *cpb.AnyValue) attribute.Value {
+	switch v.GetValue().(type) {
+	case nil:
+		// Empty value.
+		return attribute.StringValue("")
+	case *cpb.AnyValue_StringValue:
+		return attribute.StringValue(v.GetStringValue())
+	case *cpb.AnyValue_BoolValue:
+		return attribute.BoolValue(v.GetBoolValue())
+	case *cpb.AnyValue_IntValue:
+		return attribute.Int64Value(v.GetIntValue())
+	case *cpb.AnyValue_DoubleValue:
+		return attribute.Float64Value(v.GetDoubleValue())
+	case *cpb.AnyValue_ArrayValue:
+		if val, ok := sliceValue(v.GetArrayValue().GetValues()); ok {
+			return val
+		}
+	}
+	return attribute.StringValue(v.String())
+}
+
+// sliceValue returns the slice attribute value of the OTLP array values. It
+// returns false if the values are not all of the same attribute type.
+func sliceValue(vals []*cpb.AnyValue) (attribute.Value, bool) {
+	if len(vals) == 0 {
+		return attribute.StringSliceValue(nil), true
+	}
+
+	switch vals[0].GetValue().(type) {
+	case *cpb.AnyValue_StringValue:
+		s := make([]string, 0, len(vals))
+		for _, v := range vals {
+			x, ok := v.GetValue().(*cpb.AnyValue_StringValue)
+			if !ok {
+				return attribute.Value{}, false
+			}
+			s = append(s, x.StringValue)
+		}
+		return attribute.StringSliceValue(s), true
+	case *cpb.AnyValue_BoolValue:
+		s := make([]bool, 0, len(vals))
+		for _, v := range vals {
+			x, ok := v.GetValue().(*cpb.AnyValue_BoolValue)
+			if !ok {
+				return attribute.Value{}, false
+			}
+			s = append(s, x.BoolValue)
+		}
+		return attribute.BoolSliceValue(s), true
+	case *cpb.AnyValue_IntValue:
+		s := make([]int64, 0, len(vals))
+		for _, v := range vals {
+			x, ok := v.GetValue().(*cpb.AnyValue_IntValue)
+			if !ok {
+				return attribute.Value{}, false
+			}
+			s = append(s, x.IntValue)
+		}
+		return attribute.Int64SliceValue(s), true
+	case *cpb.AnyValue_DoubleValue:
+		s := make([]float64, 0, len(vals))
+		for _, v := range vals {
+			x, ok := v.GetValue().(*cpb.AnyValue_DoubleValue)
+			if !ok {
+				return attribute.Value{}, false
+			}
+			s = append(s, x.DoubleValue)
+		}
+		return attribute.Float64SliceValue(s), true
+	}
+	return attribute.Value{}, false
+}
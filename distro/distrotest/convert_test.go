@@ -0,0 +1,100 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distrotest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	traceapi "go.opentelemetry.io/otel/trace"
+	cpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tpb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func strValue(s string) *cpb.AnyValue {
+	return &cpb.AnyValue{Value: &cpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func intValue(i int64) *cpb.AnyValue {
+	return &cpb.AnyValue{Value: &cpb.AnyValue_IntValue{IntValue: i}}
+}
+
+func arrayValue(vals ...*cpb.AnyValue) *cpb.AnyValue {
+	return &cpb.AnyValue{Value: &cpb.AnyValue_ArrayValue{ArrayValue: &cpb.ArrayValue{Values: vals}}}
+}
+
+func TestValue(t *testing.T) {
+	kvlist := &cpb.AnyValue{Value: &cpb.AnyValue_KvlistValue{KvlistValue: &cpb.KeyValueList{
+		Values: []*cpb.KeyValue{{Key: "k", Value: strValue("v")}},
+	}}}
+	mixed := arrayValue(strValue("a"), intValue(1))
+
+	testCases := []struct {
+		desc string
+		in   *cpb.AnyValue
+		want attribute.Value
+	}{
+		{desc: "string", in: strValue("a"), want: attribute.StringValue("a")},
+		{desc: "int", in: intValue(1), want: attribute.Int64Value(1)},
+		{desc: "empty array", in: arrayValue(), want: attribute.StringSliceValue(nil)},
+		{desc: "int array", in: arrayValue(intValue(1), intValue(2)), want: attribute.Int64SliceValue([]int64{1, 2})},
+		{desc: "mixed array", in: mixed, want: attribute.StringValue(mixed.String())},
+		{desc: "kvlist", in: kvlist, want: attribute.StringValue(kvlist.String())},
+		{desc: "nil", in: nil, want: attribute.StringValue("")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.want, value(tc.in))
+		})
+	}
+}
+
+func TestSpanKind(t *testing.T) {
+	assert.Equal(t, traceapi.SpanKindUnspecified, spanKind(tpb.Span_SPAN_KIND_UNSPECIFIED))
+	assert.Equal(t, traceapi.SpanKindInternal, spanKind(tpb.Span_SPAN_KIND_INTERNAL))
+	assert.Equal(t, traceapi.SpanKindServer, spanKind(tpb.Span_SPAN_KIND_SERVER))
+	assert.Equal(t, traceapi.SpanKindClient, spanKind(tpb.Span_SPAN_KIND_CLIENT))
+	assert.Equal(t, traceapi.SpanKindProducer, spanKind(tpb.Span_SPAN_KIND_PRODUCER))
+	assert.Equal(t, traceapi.SpanKindConsumer, spanKind(tpb.Span_SPAN_KIND_CONSUMER))
+}
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, codes.Unset, status(nil).Code)
+	assert.Equal(t, codes.Ok, status(&tpb.Status{Code: tpb.Status_STATUS_CODE_OK, Message: "ignored"}).Code)
+	assert.Empty(t, status(&tpb.Status{Code: tpb.Status_STATUS_CODE_OK, Message: "ignored"}).Description)
+	got := status(&tpb.Status{Code: tpb.Status_STATUS_CODE_ERROR, Message: "failure"})
+	assert.Equal(t, codes.Error, got.Code)
+	assert.Equal(t, "failure", got.Description)
+}
+
+func TestSpanContextTraceState(t *testing.T) {
+	traceID := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	spanID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	sc := spanContext(traceID, spanID, "key=value")
+	assert.True(t, sc.IsValid())
+	assert.Equal(t, "value", sc.TraceState().Get("key"))
+
+	sc = spanContext(traceID, spanID, "invalid")
+	assert.True(t, sc.IsValid())
+	assert.Equal(t, 0, sc.TraceState().Len())
+}
+
+func TestTimestamp(t *testing.T) {
+	assert.True(t, timestamp(0).IsZero())
+	assert.Equal(t, int64(42), timestamp(42).UnixNano())
+}
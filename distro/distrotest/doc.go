@@ -0,0 +1,35 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package distrotest provides utilities to test the telemetry produced by an
+// application or instrumentation configured with the distro package.
+//
+// A Collector is an in-memory OTLP receiver collecting the spans exported to
+// it. Run starts a Collector and configures the OpenTelemetry SDK with
+// distro.Run to export the spans to it, so the assertions are made on the
+// spans as they are exported by the distro (e.g. with the detected
+// resource):
+//
+//	func TestHandler(t *testing.T) {
+//		coll, shutdown, err := distrotest.Run()
+//		require.NoError(t, err)
+//
+//		// Exercise the instrumented code.
+//
+//		// Export all spans before asserting them.
+//		require.NoError(t, shutdown(context.Background()))
+//		spans := coll.Spans()
+//		// Assert the spans.
+//	}
+package distrotest // import "github.com/signalfx/splunk-otel-go/distro/distrotest"
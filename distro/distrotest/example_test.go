@@ -0,0 +1,47 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distrotest_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/signalfx/splunk-otel-go/distro/distrotest"
+)
+
+func Example() {
+	os.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	defer os.Unsetenv("OTEL_TRACES_EXPORTER")
+
+	coll, shutdown, err := distrotest.Run()
+	if err != nil {
+		panic(err)
+	}
+
+	_, span := otel.Tracer("my-service").Start(context.Background(), "operation")
+	span.End()
+
+	// Export all spans before reading them.
+	if err := shutdown(context.Background()); err != nil {
+		panic(err)
+	}
+	for _, s := range coll.Spans() {
+		fmt.Println(s.Name)
+	}
+	// Output: operation
+}
@@ -19,5 +19,36 @@ SDK with useful Splunk defaults.
 The default configuration sets the default OpenTelemetry SDK to propagate
 traces using a W3C tracecontext and W3C baggage propagator and export all
 spans and metrics to a locally running Splunk OpenTelemetry Collector.
+
+The propagators can be changed using the OTEL_PROPAGATORS environment
+variable. It accepts a comma-separated list of the following values:
+tracecontext, baggage, b3, b3multi, jaeger, xray, ottrace, and none. For
+example, OTEL_PROPAGATORS="tracecontext,baggage,jaeger" extracts and injects
+both W3C and Jaeger (uber-trace-id) headers.
+
+Setting the OTEL_TRACES_EXPORTER environment variable to "console" writes
+spans to stdout as soon as they end, which is useful for local debugging
+without a collector. The output is indented unless the
+SPLUNK_CONSOLE_PRETTY_PRINT_ENABLED environment variable is set to "false".
+Setting it to "none" disables exporting spans.
+
+Metrics are exported with delta temporality for counters and histograms by
+default, as expected by Splunk Observability Cloud. This deviates from the
+OpenTelemetry specification default of cumulative. Use the
+OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE environment variable (or
+WithMetricTemporality) to use "cumulative" (e.g. for Prometheus-style
+backends) or "lowmemory" temporality instead.
+
+The Go runtime metrics (e.g. garbage collection, goroutine, and memory
+metrics) are collected with the metrics. Set the SPLUNK_RUNTIME_METRICS_ENABLED
+environment variable to "false" (or use WithRuntimeMetrics) to not collect
+them.
+
+Set the SPLUNK_PROFILER_ENABLED environment variable to "true" (or use
+WithProfiler) to set up the resource for the correlation of the Splunk
+AlwaysOn Profiling profiles with the spans: the splunk.profiler.enabled and
+host.name attributes are added. The endpoint of the profiling data is
+returned by SDK.ProfilerLogsEndpoint. This distribution does not collect nor
+export profiles itself.
 */
 package distro
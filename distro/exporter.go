@@ -16,68 +16,457 @@ package distro
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"go.opentelemetry.io/otel/exporters/jaeger"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/net/http/httpguts"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-type traceExporterFunc func(*exporterConfig) (trace.SpanExporter, error)
+type traceExporterFunc func(context.Context, *exporterConfig) (trace.SpanExporter, error)
 
 // traceExporters maps environment variable values to trace exporter creation
 // functions.
 var traceExporters = map[string]traceExporterFunc{
-	// OTLP gRPC exporter.
+	// OTLP exporter using the gRPC or HTTP protocol.
 	"otlp": newOTLPTracesExporter,
 	// Jaeger thrift exporter.
 	"jaeger-thrift-splunk": newJaegerThriftExporter,
+	// Console exporter writing to stdout, meant for local debugging.
+	"console": newConsoleTracesExporter,
 	// None, explicitly do not set an exporter.
 	"none": nil,
 }
 
-func tracesExporter(log logr.Logger) traceExporterFunc {
-	key := envOr(otelTracesExporterKey, defaultTraceExporter)
+// ErrMultipleExporters is returned by Run if more than one exporter is
+// configured for a signal (e.g. OTEL_TRACES_EXPORTER=jaeger-thrift-splunk,otlp).
+// Sending a signal to multiple exporters is not supported.
+var ErrMultipleExporters = errors.New("multiple exporters are not supported")
+
+// exporterName returns the exporter name set by the environment variable key
+// or alt if it is not set. An error wrapping ErrMultipleExporters is returned
+// if the environment variable lists more than one exporter.
+func exporterName(key, alt string) (string, error) {
+	v := envOr(key, alt)
+	var names []string
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return alt, nil
+	case 1:
+		return names[0], nil
+	}
+	return "", fmt.Errorf("%w: %s=%q", ErrMultipleExporters, key, v)
+}
+
+// tracesExporter returns the name and creation function of the trace
+// exporter set by OTEL_TRACES_EXPORTER.
+func tracesExporter(log logr.Logger) (string, traceExporterFunc, error) {
+	key, err := exporterName(otelTracesExporterKey, defaultTraceExporter)
+	if err != nil {
+		return "", nil, err
+	}
 	tef, ok := traceExporters[key]
 	if !ok {
 		err := fmt.Errorf("invalid %s: %q", otelTracesExporterKey, key)
 		log.Error(err, "using default %s: %q", otelTracesExporterKey, defaultTraceExporter)
 
-		return traceExporters[defaultTraceExporter]
+		return defaultTraceExporter, traceExporters[defaultTraceExporter], nil
 	}
-	return tef
+	return key, tef, nil
 }
 
-func newOTLPTracesExporter(c *exporterConfig) (trace.SpanExporter, error) {
+func newOTLPTracesExporter(ctx context.Context, c *exporterConfig) (trace.SpanExporter, error) {
+	if c.OTLPProtocol == otlpProtocolHTTP {
+		return newOTLPHTTPTracesExporter(ctx, c)
+	}
+	return newOTLPGRPCTracesExporter(ctx, c)
+}
+
+func newOTLPGRPCTracesExporter(ctx context.Context, c *exporterConfig) (trace.SpanExporter, error) {
+	err := checkTLSConfig(c, otelExporterOTLPTracesEndpointKey, otelExporterOTLPEndpointKey)
+	if err != nil {
+		return nil, err
+	}
+
 	var opts []otlptracegrpc.Option
 
-	endpoint := otlpTracesEndpoint()
+	endpoint, err := otlpEndpoint(c, otlpTracesEndpoint)
+	if err != nil {
+		return nil, err
+	}
 	if endpoint != "" {
 		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
 	}
 
-	if c.AccessToken != "" {
-		opts = append(opts, otlptracegrpc.WithHeaders(map[string]string{
-			"X-Sf-Token": c.AccessToken,
+	headers, err := otlpHeaders(c, otelExporterOTLPTracesHeadersKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(headers))
+	}
+
+	compression, err := otlpCompression(c, otelExporterOTLPTracesCompressionKey)
+	if err != nil {
+		return nil, err
+	}
+	if compression != "" {
+		opts = append(opts, otlptracegrpc.WithCompressor(compression))
+	}
+
+	if creds := otlpCredentials(c, otelExporterOTLPTracesEndpointKey); creds != nil {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(creds))
+	}
+
+	if rc := c.RetryConfig; rc != nil {
+		opts = append(opts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{
+			Enabled:         rc.Enabled,
+			InitialInterval: rc.InitialInterval,
+			MaxInterval:     rc.MaxInterval,
+			MaxElapsedTime:  rc.MaxElapsedTime,
 		}))
 	}
 
+	if dialOpts := c.grpcDialOptions(); len(dialOpts) > 0 {
+		opts = append(opts, otlptracegrpc.WithDialOption(dialOpts...))
+	}
+
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+func newOTLPHTTPTracesExporter(ctx context.Context, c *exporterConfig) (trace.SpanExporter, error) {
+	err := checkTLSConfig(c, otelExporterOTLPTracesEndpointKey, otelExporterOTLPEndpointKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []otlptracehttp.Option
+
+	e, err := otlpHTTPEndpoint(c, otelExporterOTLPTracesEndpointKey, "/v1/traces", otlpHTTPRealmTracesPath)
+	if err != nil {
+		return nil, err
+	}
+	if e.Host != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(e.Host))
+	}
+	if e.Path != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(e.Path))
+	}
+
+	headers, err := otlpHeaders(c, otelExporterOTLPTracesHeadersKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+
+	compression, err := otlpCompression(c, otelExporterOTLPTracesCompressionKey)
+	if err != nil {
+		return nil, err
+	}
+	switch compression {
+	case compressionGzip:
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	case compressionNone:
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.NoCompression))
+	}
+
 	if c.TLSConfig != nil {
-		tlsCreds := credentials.NewTLS(c.TLSConfig)
-		opts = append(opts, otlptracegrpc.WithTLSCredentials(tlsCreds))
-	} else if noneEnvVarSet(otelExporterOTLPEndpointKey, otelExporterOTLPTracesEndpointKey, splunkRealmKey) {
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(c.TLSConfig))
+	} else if e.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	if rc := c.RetryConfig; rc != nil {
+		opts = append(opts, otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
+			Enabled:         rc.Enabled,
+			InitialInterval: rc.InitialInterval,
+			MaxInterval:     rc.MaxInterval,
+			MaxElapsedTime:  rc.MaxElapsedTime,
+		}))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
+
+// httpEndpoint is the resolved endpoint of an OTLP HTTP exporter.
+type httpEndpoint struct {
+	// Host and port to connect to. The exporter default is used if empty.
+	Host string
+	// Path to send the signal to. The exporter default is used if empty.
+	Path string
+	// Insecure is true if the connection does not use TLS.
+	Insecure bool
+}
+
+// otlpHTTPEndpoint returns the endpoint to use for an OTLP HTTP exporter.
+//
+// The signalPath is appended to the path of the endpoint passed with
+// WithEndpoint. The path of a signal specific endpoint is used as is, unless
+// it is empty. The realmPath is used for the ingest endpoint of a realm. If
+// neither option is set and an OTLP endpoint environment variable is
+// defined, the exporter is allowed to interpret it directly.
+func otlpHTTPEndpoint(c *exporterConfig, signalEndpointKey, signalPath, realmPath string) (httpEndpoint, error) {
+	if c.Endpoint != "" {
+		u, err := parseEndpoint(c.Endpoint)
+		if err != nil {
+			return httpEndpoint{}, err
+		}
+		path := strings.TrimSuffix(u.Path, "/") + signalPath
+		if c.signalEndpoint && u.Path != "" {
+			path = u.Path
+		}
+		return httpEndpoint{
+			Host:     u.Host,
+			Path:     path,
+			Insecure: u.Scheme == "http",
+		}, nil
+	}
+
+	realm := c.Realm
+	if !notNone(realm) {
+		if !noneEnvVarSet(otelExporterOTLPEndpointKey, signalEndpointKey) {
+			// Allow the exporter to interpret these environment variables directly.
+			return httpEndpoint{}, nil
+		}
+		realm = os.Getenv(splunkRealmKey)
+	}
+	if notNone(realm) {
+		return httpEndpoint{
+			Host: fmt.Sprintf(otlpHTTPRealmHostFormat, realm),
+			Path: realmPath,
+		}, nil
+	}
+
+	// The OTel default is the same as Splunk's (localhost:4318), assume that
+	// the local collector is non-TLS.
+	return httpEndpoint{Insecure: true}, nil
+}
+
+// otlpEndpoint returns the host and port of the endpoint passed with
+// WithEndpoint if it was set, the ingest endpoint of the realm passed with
+// WithRealm if it was set, otherwise it returns the value of envFn.
+func otlpEndpoint(c *exporterConfig, envFn func() string) (string, error) {
+	if c.Endpoint == "" {
+		if notNone(c.Realm) {
+			return fmt.Sprintf(otlpRealmEndpointFormat, c.Realm), nil
+		}
+		return envFn(), nil
+	}
+	u, err := parseEndpoint(c.Endpoint)
+	if err != nil {
+		return "", err
+	}
+	return u.Host, nil
+}
+
+// otlpCredentials returns the transport credentials to use for an OTLP gRPC
+// exporter or nil if the exporter defaults are to be used.
+func otlpCredentials(c *exporterConfig, signalEndpointKey string) credentials.TransportCredentials {
+	if c.TLSConfig != nil {
+		return credentials.NewTLS(c.TLSConfig)
+	}
+
+	if c.Endpoint != "" {
+		// The endpoint was validated before, the error is always nil.
+		if u, _ := parseEndpoint(c.Endpoint); u.Scheme == "http" {
+			return insecure.NewCredentials()
+		}
+		// Use the system CA for authentication and encryption even if an
+		// environment variable configured an insecure connection.
+		return credentials.NewTLS(nil)
+	}
+
+	if notNone(c.Realm) {
+		// Splunk ingest endpoints always require TLS.
+		return credentials.NewTLS(nil)
+	}
+
+	if noneEnvVarSet(otelExporterOTLPEndpointKey, signalEndpointKey, splunkRealmKey) {
 		// Assume that the default endpoint (local collector) is non-TLS.
-		opts = append(opts, otlptracegrpc.WithTLSCredentials(insecure.NewCredentials()))
+		return insecure.NewCredentials()
+	}
+	return nil
+}
+
+// grpcDialOptions returns the gRPC dial options of the OTLP gRPC exporters.
+// The keepalive parameters come first so they can be overridden by the
+// options passed with WithGRPCDialOptions.
+func (c *exporterConfig) grpcDialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+	if c.GRPCKeepalive.Time > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(c.GRPCKeepalive))
+	}
+	return append(opts, c.GRPCDialOptions...)
+}
+
+// checkTLSConfig returns an error if a TLS configuration was passed with
+// WithTLSConfig and the endpoint the exporter sends to uses the insecure
+// "http" scheme. The endpoint is resolved from the WithEndpoint and WithRealm
+// options and then from the endpointKeys environment variables, in order.
+func checkTLSConfig(c *exporterConfig, endpointKeys ...string) error {
+	if c.TLSConfig == nil {
+		return nil
+	}
+
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		if notNone(c.Realm) {
+			// Realm endpoints always use TLS.
+			return nil
+		}
+		for _, key := range endpointKeys {
+			if endpoint = os.Getenv(key); endpoint != "" {
+				break
+			}
+		}
+	}
+
+	if u, err := url.Parse(endpoint); err == nil && u.Scheme == "http" {
+		return fmt.Errorf("TLS configuration cannot be used with insecure endpoint %q: use an https endpoint", endpoint)
+	}
+	return nil
+}
+
+// parseEndpoint parses endpoint and returns an error if it is not an http or
+// https URL.
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q: must be an http or https URL", endpoint)
+	}
+	return u, nil
+}
+
+// otlpHeaders returns the headers to send with the OTLP exports. They are
+// merged from the OTEL_EXPORTER_OTLP_HEADERS and signalHeadersKey environment
+// variables, the X-Sf-Token header of the access token, and the headers
+// passed with WithHeaders, in increasing order of precedence.
+//
+// Header names are case-insensitive, they are canonicalized to resolve the
+// conflicts. The exporters ignore the environment variables when headers are
+// passed to them, so the variables are always interpreted here.
+func otlpHeaders(c *exporterConfig, signalHeadersKey string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, key := range []string{otelExporterOTLPHeadersKey, signalHeadersKey} {
+		h, err := parseHeaders(os.Getenv(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		for k, v := range h {
+			headers[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	if c.AccessToken != "" {
+		headers["X-Sf-Token"] = c.AccessToken
 	}
+	for k, v := range c.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+	return headers, nil
+}
 
-	return otlptracegrpc.New(context.Background(), opts...)
+// otlpCompression returns the compression of the OTLP exports. It is the one
+// passed with WithCompression, otherwise the one set by the signalKey or
+// OTEL_EXPORTER_OTLP_COMPRESSION environment variables, in order. An empty
+// string is returned if none is set.
+//
+// The environment variables are interpreted here to report unsupported
+// values, the exporters silently ignore them.
+func otlpCompression(c *exporterConfig, signalKey string) (string, error) {
+	if c.Compression != "" {
+		return c.Compression, nil
+	}
+	for _, key := range []string{signalKey, otelExporterOTLPCompressionKey} {
+		v := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
+		if v == "" {
+			continue
+		}
+		if err := validateCompression(v); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", key, err)
+		}
+		return v, nil
+	}
+	return "", nil
+}
+
+// validateCompression returns an error if compression is not supported.
+func validateCompression(compression string) error {
+	switch compression {
+	case compressionGzip, compressionNone:
+		return nil
+	}
+	return fmt.Errorf("invalid compression %q: must be %q or %q", compression, compressionGzip, compressionNone)
+}
+
+// parseHeaders parses the headers in the format of the
+// OTEL_EXPORTER_OTLP_HEADERS environment variable: a comma-separated list of
+// URL-encoded key=value pairs. An error is returned if a pair is malformed or
+// if a header is not valid for HTTP.
+func parseHeaders(v string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("missing \"=\" in %q", pair)
+		}
+		key, err := url.QueryUnescape(strings.TrimSpace(k))
+		if err != nil {
+			return nil, fmt.Errorf("invalid header name %q: %w", k, err)
+		}
+		val, err := url.QueryUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of header %q: %w", key, err)
+		}
+		headers[key] = val
+	}
+	if err := validateHeaders(headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// validateHeaders returns an error if any of the headers has a name or a
+// value that is not valid for HTTP.
+func validateHeaders(headers map[string]string) error {
+	for k, v := range headers {
+		if !httpguts.ValidHeaderFieldName(k) {
+			return fmt.Errorf("invalid header name %q", k)
+		}
+		if !httpguts.ValidHeaderFieldValue(v) {
+			return fmt.Errorf("invalid value of header %q: must not contain control characters", k)
+		}
+	}
+	return nil
 }
 
 // otlpTracesEndpoint returns the endpoint to use for the OTLP gRPC traces exporter.
@@ -124,10 +513,22 @@ func otlpMetricsEndpoint() string {
 	return ""
 }
 
-func newJaegerThriftExporter(c *exporterConfig) (trace.SpanExporter, error) {
+func newJaegerThriftExporter(_ context.Context, c *exporterConfig) (trace.SpanExporter, error) {
+	if err := checkTLSConfig(c, otelExporterJaegerEndpointKey); err != nil {
+		return nil, err
+	}
+
 	var opts []jaeger.CollectorEndpointOption
 
-	if e := jaegerEndpoint(); e != "" {
+	e := c.Endpoint
+	if e == "" {
+		if notNone(c.Realm) {
+			e = fmt.Sprintf(realmEndpointFormat, c.Realm)
+		} else {
+			e = jaegerEndpoint()
+		}
+	}
+	if e != "" {
 		opts = append(opts, jaeger.WithEndpoint(e))
 	}
 
@@ -166,52 +567,192 @@ func jaegerEndpoint() string {
 	return defaultJaegerEndpoint
 }
 
-type metricsExporterFunc func(*exporterConfig) (metric.Exporter, error)
+func newConsoleTracesExporter(context.Context, *exporterConfig) (trace.SpanExporter, error) {
+	// Pass os.Stdout explicitly, the exporter default is resolved when its
+	// package is initialized.
+	opts := []stdouttrace.Option{stdouttrace.WithWriter(os.Stdout)}
+	if v := os.Getenv(splunkConsolePrettyPrintKey); !strings.EqualFold(v, "false") {
+		opts = append(opts, stdouttrace.WithPrettyPrint())
+	}
+	return stdouttrace.New(opts...)
+}
+
+type metricsExporterFunc func(context.Context, *exporterConfig) (metric.Exporter, error)
 
 // metricsExporters maps environment variable values to metrics exporter creation
 // functions.
 var metricsExporters = map[string]metricsExporterFunc{
-	// OTLP gRPC exporter.
+	// OTLP exporter using the gRPC or HTTP protocol.
 	"otlp": newOTLPMetricsExporter,
 	// None, explicitly do not set an exporter.
 	"none": nil,
 }
 
-func metricsExporter(log logr.Logger) metricsExporterFunc {
-	key := envOr(otelMetricsExporterKey, defaultMetricsExporter)
+// metricsExporter returns the name and creation function of the metrics
+// exporter set by OTEL_METRICS_EXPORTER.
+func metricsExporter(log logr.Logger) (string, metricsExporterFunc, error) {
+	key, err := exporterName(otelMetricsExporterKey, defaultMetricsExporter)
+	if err != nil {
+		return "", nil, err
+	}
 	mef, ok := metricsExporters[key]
 	if !ok {
 		err := fmt.Errorf("invalid %s: %q", otelMetricsExporterKey, key)
 		log.Error(err, "using default %s: %q", otelMetricsExporterKey, defaultMetricsExporter)
 
-		return metricsExporters[defaultMetricsExporter]
+		return defaultMetricsExporter, metricsExporters[defaultMetricsExporter], nil
 	}
-	return mef
+	return key, mef, nil
 }
 
-func newOTLPMetricsExporter(c *exporterConfig) (metric.Exporter, error) {
+func newOTLPMetricsExporter(ctx context.Context, c *exporterConfig) (metric.Exporter, error) {
+	if c.OTLPProtocol == otlpProtocolHTTP {
+		return newOTLPHTTPMetricsExporter(ctx, c)
+	}
+	return newOTLPGRPCMetricsExporter(ctx, c)
+}
+
+func newOTLPGRPCMetricsExporter(ctx context.Context, c *exporterConfig) (metric.Exporter, error) {
+	err := checkTLSConfig(c, otelExporterOTLPMetricsEndpointKey, otelExporterOTLPEndpointKey)
+	if err != nil {
+		return nil, err
+	}
+
 	var opts []otlpmetricgrpc.Option
 
-	endpoint := otlpMetricsEndpoint()
+	endpoint, err := otlpEndpoint(c, otlpMetricsEndpoint)
+	if err != nil {
+		return nil, err
+	}
 	if endpoint != "" {
 		opts = append(opts, otlpmetricgrpc.WithEndpoint(endpoint))
 	}
 
-	if c.AccessToken != "" {
-		opts = append(opts, otlpmetricgrpc.WithHeaders(map[string]string{
-			"X-Sf-Token": c.AccessToken,
+	headers, err := otlpHeaders(c, otelExporterOTLPMetricsHeadersKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(headers))
+	}
+
+	compression, err := otlpCompression(c, otelExporterOTLPMetricsCompressionKey)
+	if err != nil {
+		return nil, err
+	}
+	if compression != "" {
+		opts = append(opts, otlpmetricgrpc.WithCompressor(compression))
+	}
+
+	if creds := otlpCredentials(c, otelExporterOTLPMetricsEndpointKey); creds != nil {
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(creds))
+	}
+
+	if rc := c.RetryConfig; rc != nil {
+		opts = append(opts, otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig{
+			Enabled:         rc.Enabled,
+			InitialInterval: rc.InitialInterval,
+			MaxInterval:     rc.MaxInterval,
+			MaxElapsedTime:  rc.MaxElapsedTime,
 		}))
 	}
 
+	if dialOpts := c.grpcDialOptions(); len(dialOpts) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithDialOption(dialOpts...))
+	}
+
+	opts = append(opts, otlpmetricgrpc.WithTemporalitySelector(
+		temporalitySelector(c.MetricsTemporality),
+	))
+
+	return otlpmetricgrpc.New(ctx, opts...)
+}
+
+func newOTLPHTTPMetricsExporter(ctx context.Context, c *exporterConfig) (metric.Exporter, error) {
+	err := checkTLSConfig(c, otelExporterOTLPMetricsEndpointKey, otelExporterOTLPEndpointKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []otlpmetrichttp.Option
+
+	e, err := otlpHTTPEndpoint(c, otelExporterOTLPMetricsEndpointKey, "/v1/metrics", otlpHTTPRealmMetricsPath)
+	if err != nil {
+		return nil, err
+	}
+	if e.Host != "" {
+		opts = append(opts, otlpmetrichttp.WithEndpoint(e.Host))
+	}
+	if e.Path != "" {
+		opts = append(opts, otlpmetrichttp.WithURLPath(e.Path))
+	}
+
+	headers, err := otlpHeaders(c, otelExporterOTLPMetricsHeadersKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(headers))
+	}
+
+	compression, err := otlpCompression(c, otelExporterOTLPMetricsCompressionKey)
+	if err != nil {
+		return nil, err
+	}
+	switch compression {
+	case compressionGzip:
+		opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	case compressionNone:
+		opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.NoCompression))
+	}
+
 	if c.TLSConfig != nil {
-		tlsCreds := credentials.NewTLS(c.TLSConfig)
-		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(tlsCreds))
-	} else if noneEnvVarSet(otelExporterOTLPEndpointKey, otelExporterOTLPMetricsEndpointKey, splunkRealmKey) {
-		// Assume that the default endpoint (local collector) is non-TLS.
-		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(insecure.NewCredentials()))
+		opts = append(opts, otlpmetrichttp.WithTLSClientConfig(c.TLSConfig))
+	} else if e.Insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
 	}
 
-	return otlpmetricgrpc.New(context.Background(), opts...)
+	if rc := c.RetryConfig; rc != nil {
+		opts = append(opts, otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig{
+			Enabled:         rc.Enabled,
+			InitialInterval: rc.InitialInterval,
+			MaxInterval:     rc.MaxInterval,
+			MaxElapsedTime:  rc.MaxElapsedTime,
+		}))
+	}
+
+	opts = append(opts, otlpmetrichttp.WithTemporalitySelector(
+		temporalitySelector(c.MetricsTemporality),
+	))
+
+	return otlpmetrichttp.New(ctx, opts...)
+}
+
+// temporalitySelector returns the TemporalitySelector for the temporality
+// preference as defined by the OpenTelemetry specification.
+func temporalitySelector(preference string) metric.TemporalitySelector {
+	switch preference {
+	case temporalityCumulative:
+		return metric.DefaultTemporalitySelector
+	case temporalityLowMemory:
+		return func(k metric.InstrumentKind) metricdata.Temporality {
+			switch k {
+			case metric.InstrumentKindCounter, metric.InstrumentKindHistogram:
+				return metricdata.DeltaTemporality
+			default:
+				return metricdata.CumulativeTemporality
+			}
+		}
+	default:
+		return func(k metric.InstrumentKind) metricdata.Temporality {
+			switch k {
+			case metric.InstrumentKindCounter, metric.InstrumentKindHistogram, metric.InstrumentKindObservableCounter:
+				return metricdata.DeltaTemporality
+			default:
+				return metricdata.CumulativeTemporality
+			}
+		}
+	}
 }
 
 // noneEnvVarSet returns true if none of provided env vars is set.
@@ -19,6 +19,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
 const (
@@ -141,3 +143,222 @@ func TestJaegerEndpoint(t *testing.T) {
 		assert.Equal(t, "", jaegerEndpoint())
 	})
 }
+
+func TestParseEndpoint(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		host     string
+		wantErr  bool
+	}{
+		{endpoint: "http://localhost:4317", host: "localhost:4317"},
+		{endpoint: "https://ingest.us0.signalfx.com:443", host: "ingest.us0.signalfx.com:443"},
+		{endpoint: "localhost:4317", wantErr: true},
+		{endpoint: "grpc://localhost:4317", wantErr: true},
+		{endpoint: "http://", wantErr: true},
+		{endpoint: "http://local host:4317", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.endpoint, func(t *testing.T) {
+			u, err := parseEndpoint(tc.endpoint)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.host, u.Host)
+			}
+		})
+	}
+}
+
+func TestRealmEndpoint(t *testing.T) {
+	for _, realm := range []string{"us0", "us1", "eu0", "jp0"} {
+		t.Run(realm, func(t *testing.T) {
+			c := &exporterConfig{Realm: realm}
+
+			got, err := otlpEndpoint(c, otlpTracesEndpoint)
+			assert.NoError(t, err)
+			assert.Equal(t, "ingest."+realm+".signalfx.com:443", got)
+
+			got, err = otlpEndpoint(c, otlpMetricsEndpoint)
+			assert.NoError(t, err)
+			assert.Equal(t, "ingest."+realm+".signalfx.com:443", got)
+		})
+	}
+
+	t.Run("precedence over environment", func(t *testing.T) {
+		t.Setenv(splunkRealmKey, invalidRealm)
+		t.Setenv(otelExporterOTLPEndpointKey, fakeEndpoint)
+
+		got, err := otlpEndpoint(&exporterConfig{Realm: "us1"}, otlpTracesEndpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, "ingest.us1.signalfx.com:443", got)
+	})
+
+	t.Run("endpoint precedence", func(t *testing.T) {
+		c := &exporterConfig{Endpoint: "https://collector:4317", Realm: "us1"}
+
+		got, err := otlpEndpoint(c, otlpTracesEndpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, "collector:4317", got)
+	})
+}
+
+func TestOTLPHTTPEndpoint(t *testing.T) {
+	testCases := []struct {
+		desc string
+		conf *exporterConfig
+		env  map[string]string
+		want httpEndpoint
+	}{
+		{
+			desc: "default",
+			conf: &exporterConfig{},
+			want: httpEndpoint{Insecure: true},
+		},
+		{
+			desc: "WithEndpoint",
+			conf: &exporterConfig{Endpoint: "http://localhost:4318"},
+			want: httpEndpoint{Host: "localhost:4318", Path: "/v1/traces", Insecure: true},
+		},
+		{
+			desc: "WithEndpoint path",
+			conf: &exporterConfig{Endpoint: "https://collector/otlp/"},
+			want: httpEndpoint{Host: "collector", Path: "/otlp/v1/traces"},
+		},
+		{
+			desc: "WithRealm",
+			conf: &exporterConfig{Realm: "us1"},
+			env:  map[string]string{otelExporterOTLPEndpointKey: fakeEndpoint},
+			want: httpEndpoint{Host: "ingest.us1.signalfx.com", Path: otlpHTTPRealmTracesPath},
+		},
+		{
+			desc: splunkRealmKey,
+			conf: &exporterConfig{},
+			env:  map[string]string{splunkRealmKey: "us1"},
+			want: httpEndpoint{Host: "ingest.us1.signalfx.com", Path: otlpHTTPRealmTracesPath},
+		},
+		{
+			desc: otelExporterOTLPEndpointKey,
+			conf: &exporterConfig{},
+			env: map[string]string{
+				splunkRealmKey:              "us1",
+				otelExporterOTLPEndpointKey: fakeEndpoint,
+			},
+			want: httpEndpoint{},
+		},
+		{
+			desc: otelExporterOTLPTracesEndpointKey,
+			conf: &exporterConfig{},
+			env:  map[string]string{otelExporterOTLPTracesEndpointKey: fakeEndpoint},
+			want: httpEndpoint{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			got, err := otlpHTTPEndpoint(tc.conf, otelExporterOTLPTracesEndpointKey, "/v1/traces", otlpHTTPRealmTracesPath)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTemporalitySelector(t *testing.T) {
+	const (
+		delta      = metricdata.DeltaTemporality
+		cumulative = metricdata.CumulativeTemporality
+	)
+	kinds := []metric.InstrumentKind{
+		metric.InstrumentKindCounter,
+		metric.InstrumentKindHistogram,
+		metric.InstrumentKindObservableCounter,
+		metric.InstrumentKindUpDownCounter,
+		metric.InstrumentKindObservableUpDownCounter,
+		metric.InstrumentKindObservableGauge,
+	}
+	testCases := []struct {
+		preference string
+		want       []metricdata.Temporality
+	}{
+		{
+			preference: temporalityCumulative,
+			want:       []metricdata.Temporality{cumulative, cumulative, cumulative, cumulative, cumulative, cumulative},
+		},
+		{
+			preference: temporalityDelta,
+			want:       []metricdata.Temporality{delta, delta, delta, cumulative, cumulative, cumulative},
+		},
+		{
+			preference: temporalityLowMemory,
+			want:       []metricdata.Temporality{delta, delta, cumulative, cumulative, cumulative, cumulative},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.preference, func(t *testing.T) {
+			selector := temporalitySelector(tc.preference)
+			for i, k := range kinds {
+				assert.Equal(t, tc.want[i], selector(k), "instrument kind %d", k)
+			}
+		})
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		value   string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			desc:  "empty",
+			value: "",
+			want:  map[string]string{},
+		},
+		{
+			desc:  "multiple",
+			value: " x-tenant = acme ,X-Env=prod%20eu,, ",
+			want:  map[string]string{"x-tenant": "acme", "X-Env": "prod eu"},
+		},
+		{
+			desc:  "value with equals sign",
+			value: "authorization=Basic%20dXNlcjpwYXNz==",
+			want:  map[string]string{"authorization": "Basic dXNlcjpwYXNz=="},
+		},
+		{
+			desc:    "missing equals sign",
+			value:   "x-tenant",
+			wantErr: `missing "=" in "x-tenant"`,
+		},
+		{
+			desc:    "invalid escape",
+			value:   "x-tenant=%zz",
+			wantErr: `invalid value of header "x-tenant"`,
+		},
+		{
+			desc:    "invalid name",
+			value:   "x%20tenant=acme",
+			wantErr: `invalid header name "x tenant"`,
+		},
+		{
+			desc:    "control character",
+			value:   "x-tenant=ac%0Dme",
+			wantErr: `invalid value of header "x-tenant"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := parseHeaders(tc.value)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
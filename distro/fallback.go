@@ -0,0 +1,127 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// otlpHTTPFallbackPort is the port of the OTLP HTTP endpoint used when the
+// OTLP gRPC endpoint cannot be reached and WithTransportFallback is used.
+const otlpHTTPFallbackPort = "4318"
+
+// transportFallbackTimeout bounds the connectivity check of the OTLP gRPC
+// endpoint.
+var transportFallbackTimeout = 5 * time.Second
+
+// transportFallback returns the configuration of the OTLP exporter of a
+// signal. If WithTransportFallback is used and the gRPC endpoint of e cannot
+// be reached, a copy of e using the http/protobuf protocol on port 4318 of
+// the same host is returned. Otherwise, e is returned.
+func (c *config) transportFallback(ctx context.Context, e *exporterConfig, signalEndpointKey string) *exporterConfig {
+	if !c.TransportFallback || e.OTLPProtocol != otlpProtocolGRPC {
+		return e
+	}
+
+	target, insecureConn, realm := grpcEndpoint(e, signalEndpointKey)
+	creds := credentials.NewTLS(e.TLSConfig)
+	if insecureConn {
+		creds = insecure.NewCredentials()
+	}
+	err := checkGRPCConn(ctx, target, creds, e.GRPCDialOptions)
+	if err == nil {
+		c.Logger.V(1).Info("OTLP gRPC endpoint reachable", "endpoint", target)
+		return e
+	}
+
+	fallback := *e
+	fallback.OTLPProtocol = otlpProtocolHTTP
+	if !realm {
+		// The realm ingest endpoints are resolved by the HTTP exporters.
+		host, _, splitErr := net.SplitHostPort(target)
+		if splitErr != nil {
+			host = target
+		}
+		u := url.URL{Scheme: "https", Host: net.JoinHostPort(host, otlpHTTPFallbackPort)}
+		if insecureConn {
+			u.Scheme = "http"
+		}
+		fallback.Endpoint = u.String()
+		fallback.signalEndpoint = false
+	}
+	c.Logger.Info("OTLP gRPC endpoint unreachable; falling back to http/protobuf",
+		"endpoint", target,
+		"fallbackEndpoint", otlpEndpointHost(&fallback, signalEndpointKey),
+		"error", err.Error(),
+	)
+	return &fallback
+}
+
+// grpcEndpoint returns the host and port the OTLP gRPC exporter of a signal
+// connects to, if the connection is insecure, and if it is the ingest
+// endpoint of a realm. The endpoint is resolved as by otlpEndpointHost.
+func grpcEndpoint(e *exporterConfig, signalEndpointKey string) (target string, insecureConn, realm bool) {
+	target = otlpEndpointHost(e, signalEndpointKey)
+	if e.TLSConfig != nil {
+		return target, false, false
+	}
+	if e.Endpoint != "" {
+		// The endpoint was validated before, the error is always nil.
+		u, _ := parseEndpoint(e.Endpoint)
+		return target, u.Scheme == "http", false
+	}
+	if notNone(e.Realm) {
+		return target, false, true
+	}
+	for _, key := range []string{signalEndpointKey, otelExporterOTLPEndpointKey} {
+		if v := os.Getenv(key); v != "" {
+			insecureEnv := strings.EqualFold(os.Getenv(otelExporterOTLPInsecureKey), "true")
+			return target, strings.HasPrefix(v, "http://") || insecureEnv, false
+		}
+	}
+	if notNone(os.Getenv(splunkRealmKey)) {
+		return target, false, true
+	}
+	// The default endpoint (local collector) is non-TLS.
+	return target, true, false
+}
+
+// checkGRPCConn returns an error if a gRPC connection to target cannot be
+// established within transportFallbackTimeout.
+func checkGRPCConn(ctx context.Context, target string, creds credentials.TransportCredentials, opts []grpc.DialOption) error {
+	ctx, cancel := context.WithTimeout(ctx, transportFallbackTimeout)
+	defer cancel()
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithBlock(),
+		grpc.FailOnNonTempDialError(true),
+		grpc.WithReturnConnectionError(),
+	}
+	conn, err := grpc.DialContext(ctx, target, append(dialOpts, opts...)...)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,92 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGRPCEndpoint(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		env          map[string]string
+		conf         exporterConfig
+		wantTarget   string
+		wantInsecure bool
+		wantRealm    bool
+	}{
+		{
+			desc:         "default",
+			wantTarget:   "localhost:4317",
+			wantInsecure: true,
+		},
+		{
+			desc:         "insecure endpoint",
+			conf:         exporterConfig{Endpoint: "http://collector:4317"},
+			wantTarget:   "collector:4317",
+			wantInsecure: true,
+		},
+		{
+			desc:       "secure endpoint",
+			conf:       exporterConfig{Endpoint: "https://collector:4317"},
+			wantTarget: "collector:4317",
+		},
+		{
+			desc:       "TLS config",
+			conf:       exporterConfig{Endpoint: "https://collector:4317", TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12}},
+			wantTarget: "collector:4317",
+		},
+		{
+			desc:       "realm",
+			conf:       exporterConfig{Realm: "us0"},
+			wantTarget: "ingest.us0.signalfx.com:443",
+			wantRealm:  true,
+		},
+		{
+			desc:         "endpoint env",
+			env:          map[string]string{otelExporterOTLPEndpointKey: "http://collector:4317"},
+			wantTarget:   "collector:4317",
+			wantInsecure: true,
+		},
+		{
+			desc:         "insecure env",
+			env:          map[string]string{otelExporterOTLPTracesEndpointKey: "collector:4317", otelExporterOTLPInsecureKey: "true"},
+			wantTarget:   "collector:4317",
+			wantInsecure: true,
+		},
+		{
+			desc:       "realm env",
+			env:        map[string]string{splunkRealmKey: "us0"},
+			wantTarget: "ingest.us0.signalfx.com:443",
+			wantRealm:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			for _, key := range []string{otelExporterOTLPEndpointKey, otelExporterOTLPTracesEndpointKey, otelExporterOTLPInsecureKey, splunkRealmKey} {
+				t.Setenv(key, tc.env[key])
+			}
+			tc.conf.OTLPProtocol = otlpProtocolGRPC
+
+			target, insecureConn, realm := grpcEndpoint(&tc.conf, otelExporterOTLPTracesEndpointKey)
+			assert.Equal(t, tc.wantTarget, target)
+			assert.Equal(t, tc.wantInsecure, insecureConn, "insecure")
+			assert.Equal(t, tc.wantRealm, realm, "realm")
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonglil/buflogr"
+	"go.opentelemetry.io/otel"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+// closedEndpoint returns the URL of a local endpoint nothing listens on.
+func closedEndpoint(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+	return "http://" + net.JoinHostPort("localhost", strconv.Itoa(port))
+}
+
+// pathRecorder records the URL paths of the handled requests.
+type pathRecorder struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (r *pathRecorder) ServeHTTP(_ http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths = append(r.paths, req.URL.Path)
+}
+
+func (r *pathRecorder) Paths() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.paths...)
+}
+
+// startOTLPHTTPFallback starts an HTTP server on the OTLP HTTP port used by
+// the transport fallback.
+func startOTLPHTTPFallback(t *testing.T) *pathRecorder {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "localhost:4318")
+	require.NoError(t, err)
+	rec := &pathRecorder{}
+	srv := httptest.NewUnstartedServer(rec)
+	require.NoError(t, srv.Listener.Close())
+	srv.Listener = ln
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return rec
+}
+
+func TestRunWithTransportFallback(t *testing.T) {
+	httpSrv := startOTLPHTTPFallback(t)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_METRICS_EXPORTER", "otlp")
+
+	var buf bytes.Buffer
+	sdk, err := distro.Run(
+		distro.WithEndpoint(closedEndpoint(t)),
+		distro.WithTransportFallback(),
+		distro.WithLogger(buflogr.NewWithBuffer(&buf)),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, span := otel.Tracer(t.Name()).Start(ctx, spanName)
+	span.End()
+	cnt, err := otel.GetMeterProvider().Meter(t.Name()).Int64Counter(metricName)
+	require.NoError(t, err)
+	cnt.Add(ctx, 1)
+	require.NoError(t, sdk.Shutdown(ctx))
+
+	assert.Contains(t, httpSrv.Paths(), "/v1/traces", "traces must be exported with the HTTP exporter")
+	assert.Contains(t, httpSrv.Paths(), "/v1/metrics", "metrics must be exported with the HTTP exporter")
+	assert.Contains(t, buf.String(), "OTLP gRPC endpoint unreachable; falling back to http/protobuf")
+	assert.Contains(t, buf.String(), "fallbackEndpoint localhost:4318")
+}
+
+func TestRunWithTransportFallbackGRPCReachable(t *testing.T) {
+	httpSrv := startOTLPHTTPFallback(t)
+	coll := &collector{}
+	coll.Start(t)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+coll.Endpoint)
+
+	var buf bytes.Buffer
+	sdk, err := distro.Run(distro.WithTransportFallback(), distro.WithLogger(buflogr.NewWithBuffer(&buf)))
+	require.NoError(t, err)
+	_, span := otel.Tracer(t.Name()).Start(context.Background(), spanName)
+	span.End()
+	require.NoError(t, sdk.Shutdown(context.Background()))
+
+	asssertHasSpan(t, coll.ExportedSpans())
+	assert.Empty(t, httpSrv.Paths())
+	assert.NotContains(t, buf.String(), "falling back")
+}
+
+func TestRunWithoutTransportFallback(t *testing.T) {
+	httpSrv := startOTLPHTTPFallback(t)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+	emitSpan(t,
+		distro.WithEndpoint(closedEndpoint(t)),
+		distro.WithRetryConfig(distro.RetryConfig{Enabled: false}),
+	)
+
+	assert.Empty(t, httpSrv.Paths(), "gRPC must be used by default")
+}
@@ -0,0 +1,66 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"log"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+)
+
+var (
+	errorHandlerMu sync.Mutex
+	// registeredErrorHandler is the error handler last registered by Run.
+	registeredErrorHandler *errorHandler
+)
+
+// errorHandler is an otel.ErrorHandler registered by Run. Its address
+// identifies the registration.
+type errorHandler struct {
+	otel.ErrorHandler
+}
+
+// registerErrorHandler sets h as the global OpenTelemetry ErrorHandler.
+func registerErrorHandler(h otel.ErrorHandler) *errorHandler {
+	errorHandlerMu.Lock()
+	defer errorHandlerMu.Unlock()
+
+	registeredErrorHandler = &errorHandler{ErrorHandler: h}
+	otel.SetErrorHandler(registeredErrorHandler)
+	return registeredErrorHandler
+}
+
+// unregisterErrorHandler restores the default OpenTelemetry ErrorHandler,
+// logging to stderr, if h is still the registered global ErrorHandler. The
+// error handler registered by a subsequent Run is left in place.
+func unregisterErrorHandler(h *errorHandler) {
+	if h == nil {
+		return
+	}
+
+	errorHandlerMu.Lock()
+	defer errorHandlerMu.Unlock()
+
+	if registeredErrorHandler != h {
+		return
+	}
+	registeredErrorHandler = nil
+	l := log.New(os.Stderr, "", log.LstdFlags)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		l.Print(err)
+	}))
+}
@@ -0,0 +1,354 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// Default HEC configuration.
+const (
+	defaultHECPath         = "/services/collector/event"
+	defaultHECBatchSize    = 100
+	defaultHECBatchTimeout = 5 * time.Second
+)
+
+// hecConfig is the configuration of the logs exporter sending the log records
+// to a Splunk HTTP Event Collector (HEC).
+type hecConfig struct {
+	URL          string
+	Token        string
+	Index        string
+	Source       string
+	SourceType   string
+	BatchSize    int
+	BatchTimeout time.Duration
+}
+
+func newHECConfig(rawURL, token string, opts ...HECOption) *hecConfig {
+	c := &hecConfig{
+		URL:          rawURL,
+		Token:        token,
+		BatchSize:    defaultHECBatchSize,
+		BatchTimeout: defaultHECBatchTimeout,
+	}
+	for _, o := range opts {
+		o.apply(c)
+	}
+	return c
+}
+
+// endpoint returns the URL the events are sent to. The default event
+// endpoint path is used if the configured URL has no path.
+func (c *hecConfig) endpoint() (*url.URL, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HEC URL %q: %w", c.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid HEC URL %q: scheme must be http or https", c.URL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultHECPath
+	}
+	return u, nil
+}
+
+func (c *hecConfig) validate() error {
+	if _, err := c.endpoint(); err != nil {
+		return err
+	}
+	if c.Token == "" {
+		return errors.New("invalid HEC token: must not be empty")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("invalid HEC batch size %d: must be positive", c.BatchSize)
+	}
+	if c.BatchTimeout <= 0 {
+		return fmt.Errorf("invalid HEC batch timeout %s: must be positive", c.BatchTimeout)
+	}
+	return nil
+}
+
+// HECOption configures the logs exporter configured by WithHECLogsExporter.
+type HECOption interface {
+	apply(*hecConfig)
+}
+
+type hecOptionFunc func(*hecConfig)
+
+func (fn hecOptionFunc) apply(c *hecConfig) {
+	fn(c)
+}
+
+// WithHECIndex sets the index the events are stored in. By default, the
+// default index of the token is used.
+func WithHECIndex(index string) HECOption {
+	return hecOptionFunc(func(c *hecConfig) {
+		c.Index = index
+	})
+}
+
+// WithHECSource sets the source of the events. By default, the source
+// configured for the token is used.
+func WithHECSource(source string) HECOption {
+	return hecOptionFunc(func(c *hecConfig) {
+		c.Source = source
+	})
+}
+
+// WithHECSourceType sets the source type of the events. By default, the
+// source type configured for the token is used.
+func WithHECSourceType(sourceType string) HECOption {
+	return hecOptionFunc(func(c *hecConfig) {
+		c.SourceType = sourceType
+	})
+}
+
+// WithHECBatchSize sets the maximum number of events sent in a single
+// request. The default is 100.
+func WithHECBatchSize(size int) HECOption {
+	return hecOptionFunc(func(c *hecConfig) {
+		c.BatchSize = size
+	})
+}
+
+// WithHECBatchTimeout sets the maximum time the events are buffered before
+// they are sent. The default is 5 seconds.
+func WithHECBatchTimeout(timeout time.Duration) HECOption {
+	return hecOptionFunc(func(c *hecConfig) {
+		c.BatchTimeout = timeout
+	})
+}
+
+// hecEvent is the JSON representation of an event sent to the HEC event
+// endpoint.
+type hecEvent struct {
+	Time       float64           `json:"time"`
+	Host       string            `json:"host,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	SourceType string            `json:"sourcetype,omitempty"`
+	Index      string            `json:"index,omitempty"`
+	Event      string            `json:"event"`
+	Fields     map[string]string `json:"fields,omitempty"`
+}
+
+// hecRecord is a log record exported by the hecExporter.
+type hecRecord struct {
+	Time       time.Time
+	Severity   string
+	Body       string
+	Attributes []attribute.KeyValue
+}
+
+// hecExporter batches the log records and sends them as events to a Splunk
+// HTTP Event Collector. The batch is sent once it is full or after the batch
+// timeout by a background goroutine.
+type hecExporter struct {
+	conf     *hecConfig
+	endpoint string
+	client   *http.Client
+	// host and fields are set from the resource.
+	host   string
+	fields map[string]string
+
+	mu       sync.Mutex
+	batch    []hecEvent
+	shutdown bool
+
+	full chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newHECExporter(c *hecConfig, res *resource.Resource) (*hecExporter, error) {
+	u, err := c.endpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &hecExporter{
+		conf:     c,
+		endpoint: u.String(),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		fields:   make(map[string]string),
+		full:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for iter := res.Iter(); iter.Next(); {
+		kv := iter.Attribute()
+		if kv.Key == semconv.HostNameKey {
+			e.host = kv.Value.Emit()
+			continue
+		}
+		e.fields[string(kv.Key)] = kv.Value.Emit()
+	}
+
+	go e.run()
+	return e, nil
+}
+
+func (e *hecExporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.conf.BatchTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.full:
+		case <-e.stop:
+			return
+		}
+		if err := e.send(context.Background()); err != nil {
+			otel.Handle(err)
+		}
+	}
+}
+
+// Export adds the record to the batch. The record is dropped if the exporter
+// is shut down.
+func (e *hecExporter) Export(r hecRecord) {
+	ev := e.event(r)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.shutdown {
+		return
+	}
+	e.batch = append(e.batch, ev)
+	if len(e.batch) >= e.conf.BatchSize {
+		select {
+		case e.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// event maps the record to a HEC event. The resource and record attributes,
+// and the severity, are sent as indexed fields.
+func (e *hecExporter) event(r hecRecord) hecEvent {
+	fields := make(map[string]string, len(e.fields)+len(r.Attributes)+1)
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	for _, kv := range r.Attributes {
+		fields[string(kv.Key)] = kv.Value.Emit()
+	}
+	if r.Severity != "" {
+		fields["severity"] = r.Severity
+	}
+	return hecEvent{
+		Time:       float64(r.Time.UnixMilli()) / 1e3,
+		Host:       e.host,
+		Source:     e.conf.Source,
+		SourceType: e.conf.SourceType,
+		Index:      e.conf.Index,
+		Event:      r.Body,
+		Fields:     fields,
+	}
+}
+
+// send sends the batched events in requests of at most the batch size.
+func (e *hecExporter) send(ctx context.Context) error {
+	e.mu.Lock()
+	batch := e.batch
+	e.batch = nil
+	e.mu.Unlock()
+
+	var err error
+	for len(batch) > 0 {
+		n := len(batch)
+		if n > e.conf.BatchSize {
+			n = e.conf.BatchSize
+		}
+		if sendErr := e.post(ctx, batch[:n]); sendErr != nil && err == nil {
+			err = sendErr
+		}
+		batch = batch[n:]
+	}
+	return err
+}
+
+// post sends the events in a single request. The events are concatenated as
+// expected by the HEC event endpoint.
+func (e *hecExporter) post(ctx context.Context, events []hecEvent) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return fmt.Errorf("failed to encode HEC event: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create HEC request: %w", err)
+	}
+	req.Header.Set("Authorization", "Splunk "+e.conf.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %d events to HEC: %w", len(events), err)
+	}
+	defer resp.Body.Close()
+	// Read the body for the connection to be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to send %d events to HEC: %s", len(events), resp.Status)
+	}
+	return nil
+}
+
+// ForceFlush sends the batched events.
+func (e *hecExporter) ForceFlush(ctx context.Context) error {
+	return e.send(ctx)
+}
+
+// Shutdown stops the background goroutine and sends the batched events. The
+// records exported afterwards are dropped.
+func (e *hecExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if e.shutdown {
+		e.mu.Unlock()
+		return nil
+	}
+	e.shutdown = true
+	e.mu.Unlock()
+
+	close(e.stop)
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return e.send(ctx)
+}
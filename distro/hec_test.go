@@ -0,0 +1,223 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package distro_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+type hecRequest struct {
+	Path          string
+	Authorization string
+	ContentType   string
+	Events        []map[string]interface{}
+}
+
+// mockHEC is a Splunk HTTP Event Collector recording the received requests.
+type mockHEC struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []hecRequest
+	status   int
+}
+
+func newMockHEC(t *testing.T, status int) *mockHEC {
+	h := &mockHEC{status: status}
+	h.Server = httptest.NewServer(http.HandlerFunc(h.handle))
+	t.Cleanup(h.Close)
+	return h
+}
+
+func (h *mockHEC) handle(w http.ResponseWriter, r *http.Request) {
+	req := hecRequest{
+		Path:          r.URL.Path,
+		Authorization: r.Header.Get("Authorization"),
+		ContentType:   r.Header.Get("Content-Type"),
+	}
+	// The events are concatenated JSON objects.
+	dec := json.NewDecoder(r.Body)
+	for {
+		var ev map[string]interface{}
+		if err := dec.Decode(&ev); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Events = append(req.Events, ev)
+	}
+
+	h.mu.Lock()
+	h.requests = append(h.requests, req)
+	h.mu.Unlock()
+	w.WriteHeader(h.status)
+}
+
+func (h *mockHEC) Requests() []hecRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]hecRequest(nil), h.requests...)
+}
+
+func TestRunWithHECLogsExporter(t *testing.T) {
+	hec := newMockHEC(t, http.StatusOK)
+
+	res := resource.NewSchemaless(
+		semconv.ServiceNameKey.String("my-service"),
+		semconv.HostNameKey.String("my-host"),
+	)
+	sdk, err := distroRun(t,
+		distro.WithResource(res),
+		distro.WithHECLogsExporter(hec.URL, "secret",
+			distro.WithHECIndex("main"),
+			distro.WithHECSource("my-source"),
+			distro.WithHECSourceType("_json"),
+			distro.WithHECBatchSize(2),
+			distro.WithHECBatchTimeout(time.Hour),
+		),
+	)
+	require.NoError(t, err)
+
+	tp := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+	ctx, span := tp.Tracer(t.Name()).Start(context.Background(), spanName)
+	logger := slog.New(sdk.LogHandler()).With("tenant", "acme").WithGroup("req")
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 678e6, time.UTC)
+	r := slog.NewRecord(ts, slog.LevelWarn, "slow request", 0)
+	r.AddAttrs(slog.Int("attempt", 2), slog.Group("http", slog.String("method", "GET")))
+	require.NoError(t, logger.Handler().Handle(ctx, r))
+	span.End()
+	logger.Info("second")
+	logger.Info("third")
+	require.NoError(t, sdk.Shutdown(context.Background()))
+
+	reqs := hec.Requests()
+	require.Len(t, reqs, 2, "events must be sent in batches of 2")
+	assert.Equal(t, "/services/collector/event", reqs[0].Path)
+	assert.Equal(t, "Splunk secret", reqs[0].Authorization)
+	assert.Equal(t, "application/json", reqs[0].ContentType)
+	require.Len(t, reqs[0].Events, 2)
+	require.Len(t, reqs[1].Events, 1)
+
+	sc := span.SpanContext()
+	want := map[string]interface{}{
+		"time":       1672628645.678,
+		"host":       "my-host",
+		"source":     "my-source",
+		"sourcetype": "_json",
+		"index":      "main",
+		"event":      "slow request",
+	}
+	wantFields := map[string]interface{}{
+		"service.name":    "my-service",
+		"severity":        "WARN",
+		"tenant":          "acme",
+		"req.attempt":     "2",
+		"req.http.method": "GET",
+		"trace_id":        sc.TraceID().String(),
+		"span_id":         sc.SpanID().String(),
+	}
+	got := reqs[0].Events[0]
+	fields, ok := got["fields"].(map[string]interface{})
+	require.True(t, ok, "fields must be an object")
+	delete(got, "fields")
+	assert.Equal(t, want, got)
+	// The fields also contain the detected resource attributes.
+	for k, v := range wantFields {
+		assert.Equal(t, v, fields[k], k)
+	}
+	assert.NotContains(t, fields, "host.name", "host.name must be sent as host")
+	assert.Equal(t, "second", reqs[0].Events[1]["event"])
+	assert.Equal(t, "third", reqs[1].Events[0]["event"])
+}
+
+func TestRunWithHECLogsExporterFlush(t *testing.T) {
+	hec := newMockHEC(t, http.StatusOK)
+
+	sdk, err := distroRun(t, distro.WithHECLogsExporter(hec.URL+"/custom", "secret"))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+
+	slog.New(sdk.LogHandler()).Info("msg")
+	require.NoError(t, sdk.ForceFlush(context.Background()))
+
+	reqs := hec.Requests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "/custom", reqs[0].Path, "configured path must be used")
+	require.Len(t, reqs[0].Events, 1)
+	assert.Equal(t, "msg", reqs[0].Events[0]["event"])
+}
+
+func TestRunWithHECLogsExporterError(t *testing.T) {
+	hec := newMockHEC(t, http.StatusForbidden)
+
+	sdk, err := distroRun(t, distro.WithHECLogsExporter(hec.URL, "invalid"))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+
+	slog.New(sdk.LogHandler()).Info("msg")
+	assert.ErrorContains(t, sdk.ForceFlush(context.Background()), "failed to send 1 events to HEC: 403 Forbidden")
+}
+
+func TestRunWithHECLogsExporterInvalid(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		url     string
+		token   string
+		opts    []distro.HECOption
+		wantErr string
+	}{
+		{desc: "scheme", url: "localhost:8088", token: "t", wantErr: "invalid HEC URL"},
+		{desc: "token", url: "https://localhost:8088", wantErr: "invalid HEC token"},
+		{desc: "batch size", url: "https://localhost:8088", token: "t", opts: []distro.HECOption{distro.WithHECBatchSize(0)}, wantErr: "invalid HEC batch size 0"},
+		{desc: "batch timeout", url: "https://localhost:8088", token: "t", opts: []distro.HECOption{distro.WithHECBatchTimeout(-time.Second)}, wantErr: "invalid HEC batch timeout -1s"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := distroRun(t, distro.WithHECLogsExporter(tc.url, tc.token, tc.opts...))
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLogHandlerWithoutHECLogsExporter(t *testing.T) {
+	sdk, err := distroRun(t)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+
+	h := sdk.LogHandler()
+	assert.False(t, h.Enabled(context.Background(), slog.LevelError))
+	assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)))
+}
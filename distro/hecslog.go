@@ -0,0 +1,133 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package distro
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/distro/splunkslog"
+)
+
+// LogHandler returns a slog.Handler exporting the log records with the logs
+// exporter configured by WithHECLogsExporter. The trace context of the span
+// in the context passed to Handle is added to the records as the trace_id
+// and span_id attributes. The attributes of groups are prefixed with the
+// group names separated by ".".
+//
+// The returned handler is disabled if no logs exporter is configured. It
+// requires Go 1.21 or later.
+func (s SDK) LogHandler() slog.Handler {
+	return &hecHandler{exporter: s.hecExporter}
+}
+
+// hecHandler is a slog.Handler exporting the records with a hecExporter.
+type hecHandler struct {
+	exporter *hecExporter
+	attrs    []attribute.KeyValue
+	prefix   string
+}
+
+var _ slog.Handler = (*hecHandler)(nil)
+
+// Enabled reports whether the records are exported.
+func (h *hecHandler) Enabled(context.Context, slog.Level) bool {
+	return h.exporter != nil
+}
+
+// Handle exports r.
+func (h *hecHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.exporter == nil {
+		return nil
+	}
+
+	attrs := make([]attribute.KeyValue, len(h.attrs), len(h.attrs)+r.NumAttrs()+2)
+	copy(attrs, h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendSlogAttr(attrs, h.prefix, a)
+		return true
+	})
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs,
+			attribute.String(splunkslog.TraceIDKey, sc.TraceID().String()),
+			attribute.String(splunkslog.SpanIDKey, sc.SpanID().String()),
+		)
+	}
+
+	h.exporter.Export(hecRecord{
+		Time:       r.Time,
+		Severity:   r.Level.String(),
+		Body:       r.Message,
+		Attributes: attrs,
+	})
+	return nil
+}
+
+// WithAttrs returns a handler adding attrs to the records.
+func (h *hecHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = make([]attribute.KeyValue, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(h2.attrs, h.attrs)
+	for _, a := range attrs {
+		h2.attrs = appendSlogAttr(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a handler prefixing the attributes added afterwards with
+// the group name.
+func (h *hecHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendSlogAttr appends a converted to attrs. The attributes of groups are
+// flattened.
+func appendSlogAttr(attrs []attribute.KeyValue, prefix string, a slog.Attr) []attribute.KeyValue {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		// Empty attributes are ignored as by the slog handlers.
+		return attrs
+	}
+	v := a.Value
+	key := prefix + a.Key
+	switch v.Kind() {
+	case slog.KindGroup:
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		for _, ga := range v.Group() {
+			attrs = appendSlogAttr(attrs, prefix, ga)
+		}
+		return attrs
+	case slog.KindBool:
+		return append(attrs, attribute.Bool(key, v.Bool()))
+	case slog.KindInt64:
+		return append(attrs, attribute.Int64(key, v.Int64()))
+	case slog.KindFloat64:
+		return append(attrs, attribute.Float64(key, v.Float64()))
+	default:
+		return append(attrs, attribute.String(key, v.String()))
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+func TestGRPCKeepaliveDefault(t *testing.T) {
+	c := newTestConfig(t)
+
+	assert.Equal(t, keepalive.ClientParameters{
+		Time:    5 * time.Minute,
+		Timeout: 20 * time.Second,
+	}, c.ExportConfig.GRPCKeepalive)
+	assert.Len(t, c.ExportConfig.grpcDialOptions(), 1, "keepalive dial option")
+}
+
+func TestWithGRPCKeepalive(t *testing.T) {
+	params := keepalive.ClientParameters{
+		Time:                time.Hour,
+		Timeout:             time.Minute,
+		PermitWithoutStream: true,
+	}
+	c := newTestConfig(t, WithGRPCKeepalive(params))
+
+	assert.Equal(t, params, c.ExportConfig.GRPCKeepalive)
+	assert.Len(t, c.ExportConfig.grpcDialOptions(), 1, "keepalive dial option")
+}
+
+func TestWithGRPCKeepaliveDisabled(t *testing.T) {
+	c := newTestConfig(t, WithGRPCKeepalive(keepalive.ClientParameters{}))
+
+	assert.Empty(t, c.ExportConfig.grpcDialOptions())
+}
+
+func TestGRPCKeepaliveBeforeDialOptions(t *testing.T) {
+	// User dial options are applied last so they override the keepalive.
+	userOpt := grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: time.Hour})
+	c := newTestConfig(t, WithGRPCDialOptions(userOpt))
+
+	got := c.ExportConfig.grpcDialOptions()
+	require.Len(t, got, 2)
+	assert.Same(t, userOpt, got[1])
+}
@@ -0,0 +1,150 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+// switchDialer dials the address it currently points to, regardless of the
+// address requested by gRPC. It simulates the DNS record of the endpoint host
+// being changed.
+type switchDialer struct {
+	mu    sync.Mutex
+	addr  string
+	conns []*blackHoleConn
+}
+
+func (d *switchDialer) Dial(ctx context.Context, _ string) (net.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var nd net.Dialer
+	conn, err := nd.DialContext(ctx, "tcp", d.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &blackHoleConn{Conn: conn, closed: make(chan struct{})}
+	d.conns = append(d.conns, c)
+	return c, nil
+}
+
+// Switch points the dialer to addr and turns the connections dialed so far
+// into black holes, as if the old backend host disappeared without closing
+// them.
+func (d *switchDialer) Switch(addr string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.addr = addr
+	for _, c := range d.conns {
+		c.dead.Store(true)
+	}
+}
+
+// blackHoleConn is a connection that, once dead, discards everything written
+// and never returns anything read until it is closed.
+type blackHoleConn struct {
+	net.Conn
+
+	dead      atomic.Bool
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *blackHoleConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if c.dead.Load() {
+		<-c.closed
+		return 0, net.ErrClosed
+	}
+	return n, err
+}
+
+func (c *blackHoleConn) Write(b []byte) (int, error) {
+	if c.dead.Load() {
+		return len(b), nil
+	}
+	return c.Conn.Write(b)
+}
+
+func (c *blackHoleConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.Conn.Close()
+}
+
+// TestRunGRPCReconnectAfterBackendChange verifies that the OTLP gRPC exporter
+// detects a dead connection with keepalive and reconnects to the new address
+// of the backend. It uses the minimum keepalive interval gRPC allows and takes
+// about 15 seconds.
+func TestRunGRPCReconnectAfterBackendChange(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping slow reconnection test in short mode")
+	}
+
+	coll1 := &collector{}
+	coll1.Start(t)
+	coll2 := &collector{}
+	coll2.Start(t)
+
+	dialer := &switchDialer{addr: coll1.Endpoint}
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	sdk, err := distroRun(t,
+		distro.WithEndpoint("http://collector.test:4317"),
+		distro.WithGRPCDialOptions(grpc.WithContextDialer(dialer.Dial)),
+		distro.WithGRPCKeepalive(keepalive.ClientParameters{
+			Time:    10 * time.Second,
+			Timeout: 5 * time.Second,
+		}),
+		distro.WithRetryConfig(distro.RetryConfig{
+			Enabled:         true,
+			InitialInterval: 100 * time.Millisecond,
+			MaxInterval:     time.Second,
+			MaxElapsedTime:  time.Minute,
+		}),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(ctx)) })
+
+	emit := func() error {
+		_, span := otel.Tracer(t.Name()).Start(ctx, spanName)
+		span.End()
+		return sdk.ForceFlush(ctx)
+	}
+
+	require.NoError(t, emit())
+	asssertHasSpan(t, coll1.ExportedSpans())
+
+	dialer.Switch(coll2.Endpoint)
+	assert.Eventually(t, func() bool {
+		// Exports on the dead connection fail until keepalive closes it.
+		_ = emit()
+		return coll2.ExportedSpans() != nil
+	}, time.Minute, 100*time.Millisecond, "exporter did not reconnect")
+}
@@ -16,7 +16,10 @@ package distro
 
 import (
 	"os"
+	"strings"
+	"unicode/utf8"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
@@ -40,6 +43,10 @@ const (
 
 	spanLinkAttributeCountKey     = "OTEL_LINK_ATTRIBUTE_COUNT_LIMIT"
 	spanLinkAttributeCountDefault = -1 // Unlimited.
+
+	// originalLengthSuffix is appended to the key of a truncated attribute
+	// to form the key of the marker attribute recording its original length.
+	originalLengthSuffix = ".original_length"
 )
 
 // newSpanLimits returns new span limits that use Splunk defaults (the link
@@ -74,3 +81,143 @@ func limitValue(current, zero int, envs ...string) int {
 	}
 	return zero
 }
+
+// truncatingProcessor is a SpanProcessor passing the ended spans to the
+// wrapped processor with the values of their attributes truncated to a
+// length limit. A marker attribute recording the original length is added
+// for each truncated attribute.
+//
+// It is used in place of the truncation of the SDK, which happens when the
+// attributes are set and does not keep the original length.
+type truncatingProcessor struct {
+	trace.SpanProcessor
+
+	limit int
+}
+
+var _ trace.SpanProcessor = (*truncatingProcessor)(nil)
+
+// newTruncatingProcessor returns sp wrapped to truncate the attribute values
+// of the ended spans to limit, or sp if limit is negative (unlimited).
+func newTruncatingProcessor(sp trace.SpanProcessor, limit int) trace.SpanProcessor {
+	if limit < 0 {
+		return sp
+	}
+	return &truncatingProcessor{SpanProcessor: sp, limit: limit}
+}
+
+// OnEnd passes the span with its truncated attributes to the wrapped
+// processor.
+func (p *truncatingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	events := s.Events()
+	truncEvents := make([]trace.Event, len(events))
+	for i, e := range events {
+		e.Attributes = truncateAttrs(p.limit, e.Attributes)
+		truncEvents[i] = e
+	}
+	links := s.Links()
+	truncLinks := make([]trace.Link, len(links))
+	for i, l := range links {
+		l.Attributes = truncateAttrs(p.limit, l.Attributes)
+		truncLinks[i] = l
+	}
+	p.SpanProcessor.OnEnd(truncatedSpan{
+		ReadOnlySpan: s,
+		attrs:        truncateAttrs(p.limit, s.Attributes()),
+		events:       truncEvents,
+		links:        truncLinks,
+	})
+}
+
+// truncatedSpan is a ReadOnlySpan with truncated attribute values.
+type truncatedSpan struct {
+	trace.ReadOnlySpan
+
+	attrs  []attribute.KeyValue
+	events []trace.Event
+	links  []trace.Link
+}
+
+// Attributes returns the truncated attributes of the span.
+func (s truncatedSpan) Attributes() []attribute.KeyValue {
+	return s.attrs
+}
+
+// Events returns the events of the span with truncated attributes.
+func (s truncatedSpan) Events() []trace.Event {
+	return s.events
+}
+
+// Links returns the links of the span with truncated attributes.
+func (s truncatedSpan) Links() []trace.Link {
+	return s.links
+}
+
+// truncateAttrs returns attrs with the string and string slice values longer
+// than limit bytes truncated, as the SDK does, followed by a marker attribute
+// for each truncated attribute. The marker of a string slice records the
+// original length of each of its elements.
+func truncateAttrs(limit int, attrs []attribute.KeyValue) []attribute.KeyValue {
+	var out, markers []attribute.KeyValue
+	for i, kv := range attrs {
+		kv, marker, ok := truncateAttr(limit, kv)
+		if !ok {
+			if out != nil {
+				out = append(out, kv)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]attribute.KeyValue, i, len(attrs)+1)
+			copy(out, attrs[:i])
+		}
+		out = append(out, kv)
+		markers = append(markers, marker)
+	}
+	if out == nil {
+		return attrs
+	}
+	return append(out, markers...)
+}
+
+// truncateAttr returns kv truncated to limit and its marker attribute, or
+// false if kv is not truncated.
+func truncateAttr(limit int, kv attribute.KeyValue) (attribute.KeyValue, attribute.KeyValue, bool) {
+	markerKey := kv.Key + originalLengthSuffix
+	switch kv.Value.Type() {
+	case attribute.STRING:
+		if v := kv.Value.AsString(); len(v) > limit {
+			return kv.Key.String(safeTruncate(v, limit)), markerKey.Int(len(v)), true
+		}
+	case attribute.STRINGSLICE:
+		v := kv.Value.AsStringSlice()
+		lengths := make([]int, len(v))
+		var truncated bool
+		for i := range v {
+			lengths[i] = len(v[i])
+			if len(v[i]) > limit {
+				v[i] = safeTruncate(v[i], limit)
+				truncated = true
+			}
+		}
+		if truncated {
+			return kv.Key.StringSlice(v), markerKey.IntSlice(lengths), true
+		}
+	}
+	return kv, attribute.KeyValue{}, false
+}
+
+// safeTruncate truncates s to at most limit bytes at the bounds of complete
+// UTF-8 characters, dropping the invalid UTF-8 sequences, as the SDK does.
+func safeTruncate(s string, limit int) string {
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
@@ -15,10 +15,14 @@
 package distro
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceapi "go.opentelemetry.io/otel/trace"
 )
 
 func expectedSL(aLen, aN, eN, lN, aPerE, aPerL int) *trace.SpanLimits {
@@ -109,3 +113,60 @@ func TestNewSpanLimits(t *testing.T) {
 		})
 	}
 }
+
+func TestTruncateAttrs(t *testing.T) {
+	attrs := []attribute.KeyValue{
+		attribute.String("a", "abcdef"),
+		attribute.Int("b", 1),
+		attribute.StringSlice("c", []string{"ab", "abcdefgh"}),
+		attribute.String("d", "x\u00e9\u00e9"), // 5 bytes, é is 2 bytes long.
+		attribute.String("e", "abc"),
+	}
+	want := []attribute.KeyValue{
+		attribute.String("a", "abcd"),
+		attribute.Int("b", 1),
+		attribute.StringSlice("c", []string{"ab", "abcd"}),
+		attribute.String("d", "x\u00e9"),
+		attribute.String("e", "abc"),
+		attribute.Int("a.original_length", 6),
+		attribute.IntSlice("c.original_length", []int{2, 8}),
+		attribute.Int("d.original_length", 5),
+	}
+	assert.Equal(t, want, truncateAttrs(4, attrs))
+
+	short := []attribute.KeyValue{attribute.String("a", "abc")}
+	assert.Equal(t, short, truncateAttrs(4, short))
+	assert.Equal(t, attrs, truncateAttrs(100, attrs))
+}
+
+func TestSafeTruncate(t *testing.T) {
+	assert.Equal(t, "", safeTruncate("abc", 0))
+	assert.Equal(t, "ab", safeTruncate("ab", 2))
+	assert.Equal(t, "a", safeTruncate("a\u00e9", 2))
+	assert.Equal(t, "ab", safeTruncate("a\xffbc", 2), "invalid UTF-8 must be dropped")
+}
+
+func TestTruncatingProcessor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(newTruncatingProcessor(sr, 3)))
+	_, span := tp.Tracer(t.Name()).Start(context.Background(), "span")
+	span.SetAttributes(attribute.String("a", "abcd"))
+	span.AddEvent("event", traceapi.WithAttributes(attribute.String("b", "bcde")))
+	span.End()
+
+	ended := sr.Ended()
+	if assert.Len(t, ended, 1) {
+		assert.Equal(t, []attribute.KeyValue{
+			attribute.String("a", "abc"),
+			attribute.Int("a.original_length", 4),
+		}, ended[0].Attributes())
+		if assert.Len(t, ended[0].Events(), 1) {
+			assert.Equal(t, []attribute.KeyValue{
+				attribute.String("b", "bcd"),
+				attribute.Int("b.original_length", 4),
+			}, ended[0].Events()[0].Attributes)
+		}
+	}
+
+	assert.Same(t, sr, newTruncatingProcessor(sr, -1))
+}
@@ -0,0 +1,177 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	traceapi "go.opentelemetry.io/otel/trace"
+)
+
+// Event and attributes recorded on the spans ended by the maxDurationProcessor.
+const (
+	exceededMaxDurationEvent = "span.exceeded_max_duration"
+	maxDurationKey           = attribute.Key("span.max_duration")
+)
+
+// Bounds of the interval between two checks of the active spans.
+const (
+	minReapInterval = time.Millisecond
+	maxReapInterval = time.Minute
+)
+
+// spanKey identifies a span across its ReadWriteSpan and the ReadOnlySpan
+// snapshot passed to OnEnd.
+type spanKey struct {
+	traceID traceapi.TraceID
+	spanID  traceapi.SpanID
+}
+
+func newSpanKey(sc traceapi.SpanContext) spanKey {
+	return spanKey{traceID: sc.TraceID(), spanID: sc.SpanID()}
+}
+
+// maxDurationProcessor is a SpanProcessor ending the spans still active after
+// the max duration.
+//
+// The SDK has no way to end a span other than its End method, and a span is
+// not exported until it is ended. The processor keeps the ReadWriteSpan of
+// each active span received by OnStart and a background goroutine (the
+// reaper) ends the ones exceeding the max duration, after recording the
+// span.exceeded_max_duration event on them. The spans are then exported as
+// any other ended span, with their end time set when they are reaped. The
+// End calls made by the instrumentation afterwards are ignored by the SDK.
+type maxDurationProcessor struct {
+	max time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	spans map[spanKey]trace.ReadWriteSpan
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+var _ trace.SpanProcessor = (*maxDurationProcessor)(nil)
+
+// newMaxDurationProcessor returns a maxDurationProcessor ending the spans
+// exceeding maxDuration. The active spans are checked every interval if it
+// is positive, otherwise the reaper is not started.
+func newMaxDurationProcessor(maxDuration, interval time.Duration, now func() time.Time) *maxDurationProcessor {
+	p := &maxDurationProcessor{
+		max:   maxDuration,
+		now:   now,
+		spans: make(map[spanKey]trace.ReadWriteSpan),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if interval <= 0 {
+		close(p.done)
+		return p
+	}
+	go p.run(interval)
+	return p
+}
+
+// reapInterval returns the interval between two checks of the active spans
+// for maxDuration: half of it, bounded by minReapInterval and
+// maxReapInterval.
+func reapInterval(maxDuration time.Duration) time.Duration {
+	interval := maxDuration / 2
+	if interval < minReapInterval {
+		return minReapInterval
+	}
+	if interval > maxReapInterval {
+		return maxReapInterval
+	}
+	return interval
+}
+
+func (p *maxDurationProcessor) run(interval time.Duration) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.reap()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// reap ends the active spans started more than the max duration ago.
+func (p *maxDurationProcessor) reap() {
+	now := p.now()
+
+	var exceeded []trace.ReadWriteSpan
+	p.mu.Lock()
+	for k, s := range p.spans {
+		if now.Sub(s.StartTime()) > p.max {
+			exceeded = append(exceeded, s)
+			delete(p.spans, k)
+		}
+	}
+	p.mu.Unlock()
+
+	// The spans are ended without holding the lock as End calls OnEnd.
+	for _, s := range exceeded {
+		s.AddEvent(exceededMaxDurationEvent, traceapi.WithTimestamp(now), traceapi.WithAttributes(
+			maxDurationKey.String(p.max.String()),
+		))
+		s.End(traceapi.WithTimestamp(now))
+	}
+}
+
+// OnStart tracks the started span until it ends.
+func (p *maxDurationProcessor) OnStart(_ context.Context, s trace.ReadWriteSpan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.spans[newSpanKey(s.SpanContext())] = s
+}
+
+// OnEnd stops tracking the ended span.
+func (p *maxDurationProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.spans, newSpanKey(s.SpanContext()))
+}
+
+// Shutdown stops the reaper. The active spans are not ended.
+func (p *maxDurationProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.spans = make(map[spanKey]trace.ReadWriteSpan)
+	return nil
+}
+
+// ForceFlush does nothing, the spans are only ended by the reaper.
+func (p *maxDurationProcessor) ForceFlush(context.Context) error {
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceapi "go.opentelemetry.io/otel/trace"
+)
+
+func TestMaxDurationProcessorReap(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	p := newMaxDurationProcessor(time.Minute, 0, clock.Now)
+	rec := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(p), trace.WithSpanProcessor(rec))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	ctx := context.Background()
+	tracer := tp.Tracer(t.Name())
+	_, leaked := tracer.Start(ctx, "leaked", traceapi.WithTimestamp(clock.Now()))
+	_, ended := tracer.Start(ctx, "ended", traceapi.WithTimestamp(clock.Now()))
+	ended.End(traceapi.WithTimestamp(clock.Now().Add(time.Second)))
+
+	clock.Advance(time.Minute)
+	_, recent := tracer.Start(ctx, "recent", traceapi.WithTimestamp(clock.Now()))
+	p.reap()
+	require.Len(t, rec.Ended(), 1, "spans not exceeding the max duration must not be ended")
+
+	clock.Advance(time.Second)
+	p.reap()
+	require.Len(t, rec.Ended(), 2)
+	got := rec.Ended()[1]
+	assert.Equal(t, "leaked", got.Name())
+	assert.Equal(t, clock.Now(), got.EndTime())
+	require.Len(t, got.Events(), 1)
+	assert.Equal(t, exceededMaxDurationEvent, got.Events()[0].Name)
+	assert.Contains(t, got.Events()[0].Attributes, maxDurationKey.String("1m0s"))
+
+	// Ending a reaped span is ignored.
+	leaked.End()
+	assert.Len(t, rec.Ended(), 2)
+
+	recent.End()
+	assert.Len(t, rec.Ended(), 3)
+	p.mu.Lock()
+	assert.Empty(t, p.spans, "ended spans must not be tracked")
+	p.mu.Unlock()
+}
+
+func TestMaxDurationProcessorShutdown(t *testing.T) {
+	p := newMaxDurationProcessor(time.Minute, time.Millisecond, time.Now)
+	require.NoError(t, p.Shutdown(context.Background()))
+	// Shutting down multiple times is allowed.
+	assert.NoError(t, p.Shutdown(context.Background()))
+	assert.NoError(t, p.ForceFlush(context.Background()))
+}
+
+func TestReapInterval(t *testing.T) {
+	assert.Equal(t, minReapInterval, reapInterval(time.Microsecond))
+	assert.Equal(t, 5*time.Second, reapInterval(10*time.Second))
+	assert.Equal(t, maxReapInterval, reapInterval(time.Hour))
+}
@@ -17,61 +17,196 @@ package distro
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	metricapi "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 )
 
 var errShutdown = errors.New("SDK shutdown failure")
 
 const distroVerAttr = "splunk.distro.version"
 
-const tracesSamplerKey = "OTEL_TRACES_SAMPLER"
+const (
+	tracesSamplerKey    = "OTEL_TRACES_SAMPLER"
+	tracesSamplerArgKey = "OTEL_TRACES_SAMPLER_ARG"
+)
 
 const noServiceWarn = `service.name attribute is not set. Your service is unnamed and might be difficult to identify. Set your service name using the OTEL_SERVICE_NAME environment variable. For example, OTEL_SERVICE_NAME="<YOUR_SERVICE_NAME_HERE>")`
 
 // SDK is the Splunk distribution of the OpenTelemetry SDK.
 type SDK struct {
-	shutdownFuncs []shutdownFunc
+	shutdownFuncs   []shutdownFunc
+	flushFuncs      []flushFunc
+	shutdownTimeout time.Duration
+	tracerProvider  traceapi.TracerProvider
+	meterProvider   metricapi.MeterProvider
+	errorHandler    *errorHandler
+	hecExporter     *hecExporter
+	promRegistry    *prometheus.Registry
+	dynamicSampler  *dynamicRatioSampler
+
+	// profilerLogsEndpoint is empty if the profiler is disabled.
+	profilerLogsEndpoint string
 }
 
-type shutdownFunc func(context.Context) error
+type (
+	shutdownFunc func(context.Context) error
+	flushFunc    func(context.Context) error
+)
+
+// TracerProvider returns the TracerProvider configured by Run. A no-op
+// TracerProvider is returned if tracing is disabled (e.g.
+// OTEL_TRACES_EXPORTER is set to "none").
+//
+// It is the TracerProvider registered globally by Run unless
+// WithGlobalRegistration(false) is used. Use it to pass the TracerProvider
+// explicitly to the instrumentation not relying on the global provider.
+func (s SDK) TracerProvider() traceapi.TracerProvider {
+	if s.tracerProvider == nil {
+		return traceapi.NewNoopTracerProvider()
+	}
+	return s.tracerProvider
+}
+
+// MeterProvider returns the MeterProvider configured by Run. A no-op
+// MeterProvider is returned if metrics are disabled (e.g.
+// OTEL_METRICS_EXPORTER is set to "none").
+//
+// It is the MeterProvider registered globally by Run unless
+// WithGlobalRegistration(false) is used.
+func (s SDK) MeterProvider() metricapi.MeterProvider {
+	if s.meterProvider == nil {
+		return noop.NewMeterProvider()
+	}
+	return s.meterProvider
+}
+
+// SetSamplingRatio sets the ratio of the root spans sampled by the sampler
+// configured with WithDynamicSamplingRatio. It takes effect for the sampling
+// decisions made once it returns, and is safe to call concurrently with the
+// creation of spans. The ratio is handled as by trace.TraceIDRatioBased: a
+// ratio >= 1 samples all the traces, and a ratio <= 0 samples none.
+//
+// An error is returned if the SDK was not configured with
+// WithDynamicSamplingRatio or if ratio is NaN.
+func (s SDK) SetSamplingRatio(ratio float64) error {
+	if s.dynamicSampler == nil {
+		return errNoDynamicSampler
+	}
+	return s.dynamicSampler.setRatio(ratio)
+}
+
+// ForceFlush exports all telemetry that has not yet been exported and waits
+// until the export completes or ctx is done.
+//
+// The returned error lists each export failure (e.g. the collector cannot be
+// reached). As the metrics exporter sends a request even if there is no new
+// data, ForceFlush can be used as a readiness check of the connection to the
+// collector when metrics are enabled. An error means the SDK is not healthy,
+// it is not fatal: the application should keep running and report itself as
+// not ready. ForceFlush does nothing if Run did not configure the SDK (e.g.
+// OTEL_SDK_DISABLED is set to "true").
+func (s SDK) ForceFlush(ctx context.Context) error {
+	var retErr error
+	for _, fn := range s.flushFuncs {
+		retErr = multierr.Append(retErr, fn(ctx))
+	}
+	return retErr
+}
 
 // Shutdown stops the SDK and releases any used resources.
+//
+// If ctx does not have a deadline, the shutdown of each telemetry provider is
+// bounded by the timeout configured with WithShutdownTimeout. All providers
+// are shut down even if one of them fails. The returned error lists each
+// failure.
+//
+// The error handler registered by Run is unregistered once all providers are
+// shut down, unless Run was called again since.
 func (s SDK) Shutdown(ctx context.Context) error {
 	var retErr error
 	// Calling shutdownFuncs sequentially for sake of simplicity.
 	for _, fn := range s.shutdownFuncs {
-		if err := fn(ctx); err != nil {
+		if err := s.shutdown(ctx, fn); err != nil {
 			// Each error can have different cause therefore we are logging them via otel.Handle.
 			otel.Handle(err)
-			// We are returning a sentinel error when any shutdown error happens.
-			retErr = errShutdown
+			retErr = multierr.Append(retErr, err)
 		}
 	}
-	return retErr
+	unregisterErrorHandler(s.errorHandler)
+
+	if retErr != nil {
+		// We are returning a sentinel error when any shutdown error happens.
+		return multierr.Combine(errShutdown, retErr)
+	}
+	return nil
 }
 
-// Run configures the default OpenTelemetry SDK and installs it globally.
+func (s SDK) shutdown(ctx context.Context, fn shutdownFunc) error {
+	if _, ok := ctx.Deadline(); !ok && s.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// Run configures the default OpenTelemetry SDK and installs it globally. Use
+// WithGlobalRegistration to only access the configured providers through the
+// returned SDK.
 //
 // It is the callers responsibility to shut down the returned SDK when
 // complete. This ensures all resources are released and all telemetry
 // flushed.
+//
+// If the OTEL_SDK_DISABLED environment variable is set to "true", Run does not
+// configure anything and returns an SDK whose Shutdown method does nothing.
+// The global OpenTelemetry providers remain no-op implementations.
+//
+// Run is RunWithContext with context.Background().
 func Run(opts ...Option) (SDK, error) {
-	ctx := context.Background()
-	c := newConfig(opts...)
+	return RunWithContext(context.Background(), opts...)
+}
+
+// RunWithContext is like Run, but the resource detection and the exporter
+// creation (e.g. a blocking gRPC dial configured with WithGRPCDialOptions)
+// are bounded by ctx. If ctx is canceled or its deadline is exceeded during
+// the resource detection, the error of ctx is returned and nothing is
+// installed. The ctx is only used during the startup: canceling it once
+// RunWithContext returns does not shut down the returned SDK.
+func RunWithContext(ctx context.Context, opts ...Option) (SDK, error) {
+	if sdkDisabled() {
+		return SDK{}, nil
+	}
+
+	c, err := newConfig(opts...)
+	if err != nil {
+		return SDK{}, err
+	}
 
 	// Unify the SDK logging with OTel.
-	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(e error) {
-		c.Logger.Error(e, "OpenTelemetry error")
-	}))
+	sdk := SDK{
+		shutdownTimeout: c.ShutdownTimeout,
+		errorHandler:    registerErrorHandler(c.ErrorHandler),
+	}
 	otel.SetLogger(c.Logger)
 
 	// SPLUNK_METRICS_ENDPOINT is currently not supported, log this fact.
@@ -79,45 +214,117 @@ func Run(opts ...Option) (SDK, error) {
 		c.Logger.Info("SPLUNK_METRICS_ENDPOINT set; not supported by this distro")
 	}
 
-	res, err := newResource(ctx)
+	// Exemplars are not supported: the metrics SDK (v0.39.0) has no
+	// exemplar reservoir, so the exemplars of the data points are never
+	// recorded, and the OTLP metric exporters do not export them. Log this
+	// fact if they were requested.
+	if f := strings.TrimSpace(os.Getenv(otelMetricsExemplarFilterKey)); f != "" && !strings.EqualFold(f, "always_off") {
+		c.Logger.Info("OTEL_METRICS_EXEMPLAR_FILTER set; exemplars are not supported by this distro", "value", f)
+	}
+
+	// The OTEL_LOGS_EXPORTER exporters are not supported (logs are only exported
+	// with WithHECLogsExporter), log if one was requested.
+	if exp := envOr(otelLogsExporterKey, defaultLogsExporter); exp != defaultLogsExporter {
+		c.Logger.Info("OTEL_LOGS_EXPORTER set; logs are not supported by this distro", "value", exp)
+	}
+
+	// The host metrics are not collected by this distro, disabling them is a
+	// no-op.
+	if !c.instrumentationEnabled(instrumentationHost) {
+		c.Logger.V(1).Info("host instrumentation disabled: not started by this distro")
+	}
+
+	res, err := newResource(ctx, c)
 	if err != nil {
+		sdk.Shutdown(context.Background()) //nolint:errcheck // there is nothing to shut down
 		return SDK{}, err
 	}
 
 	if !serviceNameDefined(res) {
-		c.Logger.Info(noServiceWarn)
+		name := derivedServiceName(os.Args)
+		c.Logger.Info(noServiceWarn, "derivedServiceName", name)
+		// The merge of a schemaless resource cannot fail.
+		res, _ = resource.Merge(res, resource.NewSchemaless(semconv.ServiceNameKey.String(name)))
 	}
 
 	otel.SetTextMapPropagator(c.Propagator)
 
-	sdk := SDK{}
-
-	shutdownFn, err := runTraces(c, res)
+	tp, err := runTraces(ctx, c, res)
 	if err != nil {
-		sdk.Shutdown(ctx) //nolint:errcheck // the Shutdown errors are logged
+		sdk.Shutdown(context.Background()) //nolint:errcheck // the Shutdown errors are logged
 		return SDK{}, err
 	}
-	if shutdownFn != nil {
-		sdk.shutdownFuncs = append(sdk.shutdownFuncs, shutdownFn)
+	if tp != nil {
+		sdk.tracerProvider = tp
+		sdk.shutdownFuncs = append(sdk.shutdownFuncs, tp.Shutdown)
+		sdk.flushFuncs = append(sdk.flushFuncs, tp.ForceFlush)
+
+		if c.DynamicSampler != nil {
+			sdk.dynamicSampler = c.DynamicSampler
+			if c.SamplingRatioLoad != nil {
+				stop := startSamplingRatioReload(c.DynamicSampler, c.SamplingRatioLoad)
+				sdk.shutdownFuncs = append(sdk.shutdownFuncs, stop)
+			}
+		}
 	}
 
-	shutdownFn, err = runMetrics(c, res)
+	mp, err := runMetrics(ctx, c, res)
 	if err != nil {
-		sdk.Shutdown(ctx) //nolint:errcheck // the Shutdown errors are logged
+		sdk.Shutdown(context.Background()) //nolint:errcheck // the Shutdown errors are logged
 		return SDK{}, err
 	}
-	if shutdownFn != nil {
-		sdk.shutdownFuncs = append(sdk.shutdownFuncs, shutdownFn)
+	if mp != nil {
+		sdk.meterProvider = mp
+		sdk.shutdownFuncs = append(sdk.shutdownFuncs, mp.Shutdown)
+		sdk.flushFuncs = append(sdk.flushFuncs, mp.ForceFlush)
+		sdk.promRegistry = c.PrometheusRegistry
+	}
+	sdk.profilerLogsEndpoint = c.profilerLogsEndpoint()
+
+	if c.HECLogs != nil {
+		exp, err := newHECExporter(c.HECLogs, res)
+		if err != nil {
+			sdk.Shutdown(context.Background()) //nolint:errcheck // the Shutdown errors are logged
+			return SDK{}, err
+		}
+		sdk.hecExporter = exp
+		sdk.shutdownFuncs = append(sdk.shutdownFuncs, exp.Shutdown)
+		sdk.flushFuncs = append(sdk.flushFuncs, exp.ForceFlush)
 	}
 
+	c.Logger.V(1).Info("OpenTelemetry SDK configured", c.logKeysAndValues()...)
+
 	return sdk, nil
 }
 
-func newResource(ctx context.Context) (*resource.Resource, error) {
-	// SDK's default resource.
-	defaultRes := resource.Default()
+// newResource returns the detected resource merged with the resources of the
+// configured detectors and the user-provided resource. Attributes of the
+// user-provided resource take precedence.
+func newResource(ctx context.Context, c *config) (*resource.Resource, error) {
+	// SDK's default resource, without the "unknown_service:" service name
+	// default (see derivedServiceName). It is detected on each call, unlike
+	// resource.Default, so that the environment variables are read again.
+	// The environment variables are parsed by envDetector in place of
+	// resource.WithFromEnv to percent-decode the values as specified.
+	defaultRes, err := resource.New(ctx,
+		resource.WithDetectors(envDetector{}),
+		resource.WithTelemetrySDK(),
+	)
+	if errors.Is(err, resource.ErrPartialResource) {
+		// Invalid OTEL_RESOURCE_ATTRIBUTES entries are skipped, as done by
+		// resource.Default.
+		otel.Handle(err)
+	} else if err != nil {
+		return nil, err
+	}
+	if len(c.FileResourceAttributes) > 0 {
+		// The attributes of the config file have a lower precedence than
+		// the ones of the environment variables. The merge of a schemaless
+		// resource cannot fail.
+		defaultRes, _ = resource.Merge(resource.NewSchemaless(resourceAttributes(c.FileResourceAttributes)...), defaultRes)
+	}
 	// Add additional detectors.
-	resWithDetectors, err := resource.New(ctx,
+	resOpts := []resource.Option{
 		resource.WithDetectors(
 			// Add Splunk-specific attributes.
 			resource.StringDetector(semconv.SchemaURL, distroVerAttr, func() (string, error) {
@@ -126,7 +333,11 @@ func newResource(ctx context.Context) (*resource.Resource, error) {
 		),
 		// Add process and Go runtime information.
 		resource.WithProcess(),
-	)
+	}
+	if c.Profiler {
+		resOpts = append(resOpts, resource.WithAttributes(profilerResourceAttributes()...))
+	}
+	resWithDetectors, err := resource.New(ctx, resOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -135,64 +346,315 @@ func newResource(ctx context.Context) (*resource.Resource, error) {
 		return nil, err
 	}
 
+	if c.BuildInfo {
+		// The build information has the lowest precedence. The merge of a
+		// schemaless resource cannot fail.
+		res, _ = resource.Merge(buildInfoResource(readBuildInfo), res)
+	}
+
+	res = mergeDetected(ctx, c, res)
+	// The detectors failing because ctx is done are skipped, but the startup
+	// has to be aborted.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(c.ResourceAttributes) > 0 {
+		// The merge of a schemaless resource cannot fail.
+		res, _ = resource.Merge(res, resource.NewSchemaless(resourceAttributes(c.ResourceAttributes)...))
+	}
+
+	if c.SchemaURL != "" {
+		// The schema URL is set before the user-provided resource is merged
+		// for a resource with the same schema URL to be merged.
+		res = resource.NewWithAttributes(c.SchemaURL, res.Attributes()...)
+	}
+
+	if c.Resource != nil {
+		res, err = resource.Merge(res, c.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge user-provided resource: %w", err)
+		}
+	}
+
+	if c.ServiceName != "" {
+		res, err = resource.Merge(res, resource.NewSchemaless(semconv.ServiceNameKey.String(c.ServiceName)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.ServiceNamespace != "" {
+		// The merge of a schemaless resource cannot fail.
+		res, _ = resource.Merge(res, resource.NewSchemaless(semconv.ServiceNamespaceKey.String(c.ServiceNamespace)))
+	}
+
+	if c.Environment != "" {
+		// The merge of a schemaless resource cannot fail.
+		res, _ = resource.Merge(res, resource.NewSchemaless(semconv.DeploymentEnvironmentKey.String(c.Environment)))
+	}
+
 	return res, nil
 }
 
-func runTraces(c *config, res *resource.Resource) (shutdownFunc, error) {
-	if c.TracesExporterFunc == nil {
+// mergeDetected returns res merged with the resources of the host and
+// container detectors, if enabled, and the detectors passed with
+// WithResourceDetectors. Detection is bounded by the resource detection
+// timeout. Detection and merge failures are logged and the resource of the
+// failing detector is skipped.
+func mergeDetected(ctx context.Context, c *config, res *resource.Resource) *resource.Resource {
+	var detectors []resource.Detector
+	if c.HostDetection || c.Profiler {
+		// The host name is required to correlate the profiles.
+		detectors = append(detectors, optionDetector{resource.WithHost()})
+	}
+	if c.ContainerDetection {
+		detectors = append(detectors, optionDetector{resource.WithContainer()})
+	}
+	detectors = append(detectors, c.ResourceDetectors...)
+	if len(detectors) == 0 {
+		return res
+	}
+
+	if c.ResourceDetectionTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.ResourceDetectionTimeout)
+		defer cancel()
+	}
+
+	for _, d := range detectors {
+		detected, err := detect(ctx, d)
+		if err == nil {
+			detected, err = resource.Merge(res, detected)
+		}
+		if err != nil {
+			c.Logger.Info("resource detection failed; skipping detector", "detector", fmt.Sprintf("%T", d), "error", err.Error())
+			continue
+		}
+		res = detected
+	}
+	return res
+}
+
+// detect returns the resource detected by d. It returns when ctx is done
+// even if d does not honor ctx (e.g. blocking file reads).
+func detect(ctx context.Context, d resource.Detector) (*resource.Resource, error) {
+	type result struct {
+		res *resource.Resource
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		r, err := d.Detect(ctx)
+		ch <- result{res: r, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.res, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// optionDetector is a resource.Detector using the detectors configured by a
+// resource.Option (e.g. resource.WithHost).
+type optionDetector struct {
+	opt resource.Option
+}
+
+func (d optionDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	return resource.New(ctx, d.opt)
+}
+
+func runTraces(ctx context.Context, c *config, res *resource.Resource) (*trace.TracerProvider, error) {
+	if c.TracesExporterFunc == nil && len(c.SpanProcessors) == 0 && len(c.SpanExporters) == 0 {
 		c.Logger.V(1).Info("OTEL_TRACES_EXPORTER set to none: Tracing disabled")
 		// "none" exporter configured.
 		return nil, nil
 	}
 
-	exp, err := c.TracesExporterFunc(c.ExportConfig)
-	if err != nil {
-		return nil, err
+	limits := *c.SpanLimits
+	wrap := func(sp trace.SpanProcessor) trace.SpanProcessor {
+		return newScrubbingProcessor(sp, c.AttributeScrubbers)
 	}
+	if c.TruncationMarker && limits.AttributeValueLengthLimit >= 0 {
+		// Truncate when the spans end to know the original lengths.
+		truncLimit := limits.AttributeValueLengthLimit
+		limits.AttributeValueLengthLimit = -1
+		wrap = func(sp trace.SpanProcessor) trace.SpanProcessor {
+			return newScrubbingProcessor(newTruncatingProcessor(sp, truncLimit), c.AttributeScrubbers)
+		}
+	}
+
 	o := []trace.TracerProviderOption{
 		trace.WithResource(res),
-		trace.WithRawSpanLimits(*c.SpanLimits),
-		trace.WithSpanProcessor(trace.NewBatchSpanProcessor(exp)),
+		trace.WithRawSpanLimits(limits),
+	}
+	if c.MaxSpanDuration > 0 {
+		// Registered first to stop ending spans before the other processors
+		// are shut down.
+		p := newMaxDurationProcessor(c.MaxSpanDuration, reapInterval(c.MaxSpanDuration), time.Now)
+		o = append(o, trace.WithSpanProcessor(p))
+	}
+	if len(c.SpanStartHooks) > 0 {
+		o = append(o, trace.WithSpanProcessor(spanStartHookProcessor{hooks: c.SpanStartHooks}))
+	}
+	if c.TracesExporterFunc != nil {
+		e := c.ExportConfig.signalConfig(c.ExportConfig.TracesEndpoint)
+		if c.TracesExporter == "otlp" {
+			e = c.transportFallback(ctx, e, otelExporterOTLPTracesEndpointKey)
+		}
+		exp, err := c.TracesExporterFunc(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := exp.(*stdouttrace.Exporter); ok {
+			// Write spans to the console as soon as they end.
+			o = append(o, trace.WithSpanProcessor(wrap(trace.NewSimpleSpanProcessor(exp))))
+		} else {
+			o = append(o, trace.WithSpanProcessor(wrap(trace.NewBatchSpanProcessor(exp, c.BSPOptions...))))
+		}
+	} else {
+		c.Logger.V(1).Info("OTEL_TRACES_EXPORTER set to none: spans are only passed to the additional span processors and exporters")
 	}
-	if _, ok := os.LookupEnv(tracesSamplerKey); !ok {
+	for _, exp := range c.SpanExporters {
+		// A processor per exporter so that they do not block each other.
+		o = append(o, trace.WithSpanProcessor(wrap(trace.NewBatchSpanProcessor(exp, c.BSPOptions...))))
+	}
+	for _, sp := range c.SpanProcessors {
+		o = append(o, trace.WithSpanProcessor(wrap(sp)))
+	}
+	_, samplerEnvSet := os.LookupEnv(tracesSamplerKey)
+	if c.Sampler != nil {
+		if samplerEnvSet {
+			otel.Handle(fmt.Errorf("%s is ignored: sampler set with WithSampler option", tracesSamplerKey))
+		}
+		o = append(o, trace.WithSampler(c.Sampler))
+	} else if !samplerEnvSet {
 		o = append(o, trace.WithSampler(trace.AlwaysSample()))
 	}
 
 	traceProvider := trace.NewTracerProvider(o...)
-	otel.SetTracerProvider(traceProvider)
+	if c.GlobalRegistration {
+		otel.SetTracerProvider(traceProvider)
+	}
 
-	return traceProvider.Shutdown, nil
+	return traceProvider, nil
 }
 
-func runMetrics(c *config, res *resource.Resource) (shutdownFunc, error) {
-	if c.MetricsExporterFunc == nil {
+func runMetrics(ctx context.Context, c *config, res *resource.Resource) (*metric.MeterProvider, error) {
+	if c.MetricsExporterFunc == nil && c.PrometheusRegistry == nil {
 		c.Logger.V(1).Info("OTEL_METRICS_EXPORTER set to none: Metrics disabled")
 		// "none" exporter configured.
 		return nil, nil
 	}
 
-	exp, err := c.MetricsExporterFunc(c.ExportConfig)
-	if err != nil {
-		return nil, err
+	o := []metric.Option{metric.WithResource(res)}
+	if c.MetricsExporterFunc != nil {
+		e := c.ExportConfig.signalConfig(c.ExportConfig.MetricsEndpoint)
+		if c.MetricsExporter == "otlp" {
+			e = c.transportFallback(ctx, e, otelExporterOTLPMetricsEndpointKey)
+		}
+		exp, err := c.MetricsExporterFunc(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		o = append(o, metric.WithReader(metric.NewPeriodicReader(exp)))
+	}
+	if c.PrometheusRegistry != nil {
+		reader, err := newPrometheusReader(c.PrometheusRegistry)
+		if err != nil {
+			return nil, err
+		}
+		o = append(o, metric.WithReader(reader))
+	}
+	o = append(o, splunkHTTPViews(c.HTTPServerDurationBoundaries)...)
+
+	meterProvider := metric.NewMeterProvider(o...)
+	if c.GlobalRegistration {
+		otel.SetMeterProvider(meterProvider)
 	}
 
-	o := []metric.Option{
-		metric.WithResource(res),
-		metric.WithReader(metric.NewPeriodicReader(exp)),
+	// Add runtime metrics instrumentation. The collection stops when the
+	// MeterProvider is shut down.
+	if c.runtimeMetricsEnabled() {
+		opts := []runtime.Option{runtime.WithMeterProvider(meterProvider)}
+		if c.RuntimeMetricsInterval > 0 {
+			opts = append(opts, runtime.WithMinimumReadMemStatsInterval(c.RuntimeMetricsInterval))
+		}
+		if err := runtime.Start(opts...); err != nil {
+			return nil, err
+		}
+	} else {
+		c.Logger.V(1).Info("runtime instrumentation disabled: Go runtime metrics not collected")
 	}
 
-	provider := metric.NewMeterProvider(o...)
-	otel.SetMeterProvider(provider)
+	return meterProvider, nil
+}
 
-	// Add runtime metrics instrumentation.
-	if err := runtime.Start(); err != nil {
-		return nil, err
+// splunkhttpInstrumentationName is the name of the meter of the
+// github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp
+// package.
+const splunkhttpInstrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp"
+
+// Splunk preferred histogram bucket boundaries of the HTTP server metrics.
+var (
+	// httpDurationBoundaries are in milliseconds.
+	httpDurationBoundaries = []float64{0, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+	// httpSizeBoundaries are in bytes.
+	httpSizeBoundaries = []float64{0, 128, 512, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216}
+)
+
+// splunkHTTPViews returns the options setting the Splunk preferred bucket
+// boundaries of the histograms recorded by the splunkhttp instrumentation.
+// The durationBoundaries are used for http.server.duration if not nil.
+func splunkHTTPViews(durationBoundaries []float64) []metric.Option {
+	if durationBoundaries == nil {
+		durationBoundaries = httpDurationBoundaries
+	}
+	view := func(name string, boundaries []float64) metric.Option {
+		return metric.WithView(metric.NewView(
+			metric.Instrument{
+				Name:  name,
+				Scope: instrumentation.Scope{Name: splunkhttpInstrumentationName},
+			},
+			metric.Stream{
+				Aggregation: aggregation.ExplicitBucketHistogram{Boundaries: boundaries},
+			},
+		))
+	}
+	return []metric.Option{
+		view("http.server.duration", durationBoundaries),
+		view("http.server.request.size", httpSizeBoundaries),
+		view("http.server.response.size", httpSizeBoundaries),
 	}
+}
 
-	return provider.Shutdown, nil
+// derivedServiceName returns the service name derived from the command-line
+// arguments: the base name of the executable without the ".exe" extension.
+// If it cannot be derived, "unknown_service" is returned as defined by the
+// OpenTelemetry specification.
+func derivedServiceName(args []string) string {
+	const unknown = "unknown_service"
+	if len(args) == 0 || args[0] == "" {
+		return unknown
+	}
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return unknown
+	}
+	return name
 }
 
+// serviceNameDefined returns if r has a service name that is not empty and
+// is not a default "unknown_service" (or "unknown_service:<executable>")
+// service name.
 func serviceNameDefined(r *resource.Resource) bool {
 	val, ok := r.Set().Value(semconv.ServiceNameKey)
-	return ok && val.Type() == attribute.STRING && !strings.HasPrefix(val.AsString(), "unknown_service:")
+	if !ok || val.Type() != attribute.STRING {
+		return false
+	}
+	name := val.AsString()
+	return name != "" && name != "unknown_service" && !strings.HasPrefix(name, "unknown_service:")
 }
@@ -0,0 +1,195 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestSDKShutdownTimeout(t *testing.T) {
+	errFlush := errors.New("flush failure")
+	var metricsShutdown bool
+	sdk := SDK{
+		shutdownFuncs: []shutdownFunc{
+			func(ctx context.Context) error {
+				// Simulate a trace provider flush that does not complete.
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			func(context.Context) error {
+				return errFlush
+			},
+			func(context.Context) error {
+				metricsShutdown = true
+				return nil
+			},
+		},
+		shutdownTimeout: time.Millisecond,
+	}
+
+	err := sdk.Shutdown(context.Background())
+	assert.ErrorIs(t, err, errShutdown)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, errFlush)
+	assert.True(t, metricsShutdown, "all providers need to be shut down")
+}
+
+func TestSDKShutdownContextDeadline(t *testing.T) {
+	sdk := SDK{
+		shutdownFuncs: []shutdownFunc{
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		// The deadline of the passed context needs to take precedence.
+		shutdownTimeout: time.Hour,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, sdk.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestSDKShutdownNoTimeout(t *testing.T) {
+	sdk := SDK{
+		shutdownFuncs: []shutdownFunc{
+			func(ctx context.Context) error {
+				_, ok := ctx.Deadline()
+				assert.False(t, ok, "deadline should not be set")
+				return nil
+			},
+		},
+	}
+	assert.NoError(t, sdk.Shutdown(context.Background()))
+}
+
+func TestDerivedServiceName(t *testing.T) {
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"/usr/local/bin/my-service", "-flag"}, want: "my-service"},
+		{args: []string{"my-service.exe"}, want: "my-service"},
+		{args: []string{"./my-service"}, want: "my-service"},
+		{args: nil, want: "unknown_service"},
+		{args: []string{""}, want: "unknown_service"},
+		{args: []string{"/"}, want: "unknown_service"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, derivedServiceName(tc.args), "args: %q", tc.args)
+	}
+}
+
+func TestSplunkHTTPViews(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(append(splunkHTTPViews(nil), metric.WithReader(reader))...)
+
+	ctx := context.Background()
+	meter := mp.Meter(splunkhttpInstrumentationName)
+	duration, err := meter.Float64Histogram("http.server.duration")
+	require.NoError(t, err)
+	duration.Record(ctx, 42)
+	size, err := meter.Int64Histogram("http.server.response.size")
+	require.NoError(t, err)
+	size.Record(ctx, 42)
+	other, err := mp.Meter("other").Float64Histogram("http.server.duration")
+	require.NoError(t, err)
+	other.Record(ctx, 42)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	bounds := make(map[string][]float64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				bounds[sm.Scope.Name+" "+m.Name] = data.DataPoints[0].Bounds
+			case metricdata.Histogram[int64]:
+				bounds[sm.Scope.Name+" "+m.Name] = data.DataPoints[0].Bounds
+			}
+		}
+	}
+	assert.Equal(t, httpDurationBoundaries, bounds[splunkhttpInstrumentationName+" http.server.duration"])
+	assert.Equal(t, httpSizeBoundaries, bounds[splunkhttpInstrumentationName+" http.server.response.size"])
+	assert.NotEqual(t, httpDurationBoundaries, bounds["other http.server.duration"], "other instrumentation should not be changed")
+}
+
+func TestSplunkHTTPViewsDurationBoundaries(t *testing.T) {
+	boundaries := []float64{50, 100, 250, 500}
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(append(splunkHTTPViews(boundaries), metric.WithReader(reader))...)
+
+	ctx := context.Background()
+	meter := mp.Meter(splunkhttpInstrumentationName)
+	duration, err := meter.Float64Histogram("http.server.duration")
+	require.NoError(t, err)
+	for _, v := range []float64{75, 300, 300, 1000} {
+		duration.Record(ctx, v)
+	}
+	size, err := meter.Int64Histogram("http.server.request.size")
+	require.NoError(t, err)
+	size.Record(ctx, 42)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	var found bool
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Histogram[float64]:
+			found = true
+			require.Len(t, data.DataPoints, 1)
+			dp := data.DataPoints[0]
+			assert.Equal(t, boundaries, dp.Bounds)
+			assert.Equal(t, []uint64{0, 1, 0, 2, 1}, dp.BucketCounts)
+		case metricdata.Histogram[int64]:
+			require.Len(t, data.DataPoints, 1)
+			assert.Equal(t, httpSizeBoundaries, data.DataPoints[0].Bounds, "size boundaries should not be changed")
+		}
+	}
+	assert.True(t, found, "duration should be recorded")
+}
+
+func TestValidateBoundaries(t *testing.T) {
+	assert.NoError(t, validateBoundaries([]float64{50, 100, 250, 500}))
+
+	testCases := []struct {
+		desc       string
+		boundaries []float64
+	}{
+		{desc: "empty"},
+		{desc: "zero", boundaries: []float64{0, 100}},
+		{desc: "negative", boundaries: []float64{-1, 100}},
+		{desc: "NaN", boundaries: []float64{math.NaN()}},
+		{desc: "infinite", boundaries: []float64{100, math.Inf(1)}},
+		{desc: "not sorted", boundaries: []float64{100, 50}},
+		{desc: "duplicate", boundaries: []float64{50, 50}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Error(t, validateBoundaries(tc.boundaries))
+		})
+	}
+}
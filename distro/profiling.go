@@ -0,0 +1,75 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// profilerEnabledAttr is the resource attribute marking the processes with
+// Splunk AlwaysOn Profiling enabled.
+const profilerEnabledAttr = "splunk.profiler.enabled"
+
+// defaultProfilerLogsEndpoint is the endpoint of the local collector the
+// profiling data is sent to if no endpoint is configured.
+const defaultProfilerLogsEndpoint = "http://localhost:4317"
+
+// ProfilerLogsEndpoint returns the OTLP endpoint the profiling data (sent as
+// OTLP logs) of the Splunk AlwaysOn Profiling is to be exported to. It is
+// empty if profiling is not enabled with WithProfiler or the
+// SPLUNK_PROFILER_ENABLED environment variable.
+//
+// The distribution does not collect nor export profiles itself. Pass the
+// endpoint to the profiler (e.g. the OpenTelemetry Collector receiving the
+// profiles) to correlate the profiles with the spans of this process.
+func (s SDK) ProfilerLogsEndpoint() string {
+	return s.profilerLogsEndpoint
+}
+
+// profilerEnabled returns if SPLUNK_PROFILER_ENABLED is set to "true".
+func profilerEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv(splunkProfilerEnabledKey)), "true")
+}
+
+// profilerLogsEndpoint returns the endpoint of the profiling data: the
+// endpoint passed with WithProfilerLogsEndpoint, set by
+// SPLUNK_PROFILER_LOGS_ENDPOINT, passed with WithEndpoint, or set by
+// OTEL_EXPORTER_OTLP_ENDPOINT, in that order. The local collector is used
+// otherwise. It is empty if profiling is disabled.
+func (c *config) profilerLogsEndpoint() string {
+	if !c.Profiler {
+		return ""
+	}
+	if c.ProfilerLogsEndpoint != "" {
+		return c.ProfilerLogsEndpoint
+	}
+	if v := os.Getenv(splunkProfilerLogsEndpointKey); v != "" {
+		return v
+	}
+	if c.ExportConfig.Endpoint != "" {
+		return c.ExportConfig.Endpoint
+	}
+	return envOr(otelExporterOTLPEndpointKey, defaultProfilerLogsEndpoint)
+}
+
+// profilerResourceAttributes returns the resource attributes required to
+// correlate the profiles with the spans. The host.name attribute is added by
+// the host detector, enabled with the profiler.
+func profilerResourceAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{attribute.Bool(profilerEnabledAttr, true)}
+}
@@ -0,0 +1,149 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	comm "go.opentelemetry.io/proto/otlp/common/v1"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+// profilerResource returns the resource attributes of the span exported
+// with opts.
+func profilerResource(t *testing.T, opts ...distro.Option) []*comm.KeyValue {
+	t.Helper()
+
+	coll := &collector{}
+	coll.Start(t)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+coll.Endpoint)
+
+	// The host detector has to be enabled by the profiler.
+	emitSpan(t, append([]distro.Option{distro.WithHostDetection(false)}, opts...)...)
+
+	got := coll.ExportedSpans()
+	require.NotNil(t, got)
+	return got.Resource.GetAttributes()
+}
+
+func boolKeyValue(key string, value bool) *comm.KeyValue {
+	return &comm.KeyValue{
+		Key:   key,
+		Value: &comm.AnyValue{Value: &comm.AnyValue_BoolValue{BoolValue: value}},
+	}
+}
+
+func TestRunWithProfiler(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	attrs := profilerResource(t, distro.WithProfiler(true))
+
+	assert.Contains(t, attrs, boolKeyValue("splunk.profiler.enabled", true))
+	assert.Contains(t, attrs, strKeyValue("host.name", hostname))
+}
+
+func TestRunProfilerEnabledEnv(t *testing.T) {
+	t.Setenv("SPLUNK_PROFILER_ENABLED", "true")
+
+	attrs := profilerResource(t)
+
+	assert.Contains(t, attrs, boolKeyValue("splunk.profiler.enabled", true))
+}
+
+func TestRunProfilerDisabled(t *testing.T) {
+	t.Setenv("SPLUNK_PROFILER_ENABLED", "true")
+
+	attrs := profilerResource(t, distro.WithProfiler(false))
+
+	for _, kv := range attrs {
+		assert.NotEqual(t, "splunk.profiler.enabled", kv.Key)
+		assert.NotEqual(t, "host.name", kv.Key)
+	}
+}
+
+func TestProfilerLogsEndpoint(t *testing.T) {
+	testCases := []struct {
+		desc string
+		env  map[string]string
+		opts []distro.Option
+		want string
+	}{
+		{
+			desc: "disabled",
+			env:  map[string]string{"SPLUNK_PROFILER_LOGS_ENDPOINT": "http://env:4317"},
+			want: "",
+		},
+		{
+			desc: "option",
+			env:  map[string]string{"SPLUNK_PROFILER_LOGS_ENDPOINT": "http://env:4317"},
+			opts: []distro.Option{
+				distro.WithProfiler(true),
+				distro.WithProfilerLogsEndpoint("http://option:4317"),
+			},
+			want: "http://option:4317",
+		},
+		{
+			desc: "SPLUNK_PROFILER_LOGS_ENDPOINT",
+			env: map[string]string{
+				"SPLUNK_PROFILER_LOGS_ENDPOINT": "http://env:4317",
+				"OTEL_EXPORTER_OTLP_ENDPOINT":   "http://otlp:4317",
+			},
+			opts: []distro.Option{distro.WithProfiler(true), distro.WithEndpoint("http://endpoint:4317")},
+			want: "http://env:4317",
+		},
+		{
+			desc: "WithEndpoint",
+			env:  map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://otlp:4317"},
+			opts: []distro.Option{distro.WithProfiler(true), distro.WithEndpoint("http://endpoint:4317")},
+			want: "http://endpoint:4317",
+		},
+		{
+			desc: "OTEL_EXPORTER_OTLP_ENDPOINT",
+			env:  map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://otlp:4317"},
+			opts: []distro.Option{distro.WithProfiler(true)},
+			want: "http://otlp:4317",
+		},
+		{
+			desc: "default",
+			opts: []distro.Option{distro.WithProfiler(true)},
+			want: "http://localhost:4317",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			sdk, err := distroRun(t, tc.opts...)
+			require.NoError(t, err)
+			t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+
+			assert.Equal(t, tc.want, sdk.ProfilerLogsEndpoint())
+		})
+	}
+}
+
+func TestRunWithProfilerLogsEndpointInvalid(t *testing.T) {
+	_, err := distroRun(t, distro.WithProfiler(true), distro.WithProfilerLogsEndpoint("localhost:4317"))
+	assert.ErrorContains(t, err, "invalid profiler logs endpoint")
+}
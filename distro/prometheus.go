@@ -0,0 +1,44 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+// PrometheusHandler returns an http.Handler serving the metrics registered
+// with the registry of the exporter configured by WithPrometheusExporter in
+// the Prometheus text format. Mount it where Prometheus scrapes the metrics
+// (e.g. /metrics).
+//
+// The returned handler responds with 404 Not Found if no Prometheus exporter
+// is configured or metrics are disabled.
+func (s SDK) PrometheusHandler() http.Handler {
+	if s.promRegistry == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(s.promRegistry, promhttp.HandlerOpts{})
+}
+
+// newPrometheusReader returns a metric.Reader registering the metrics with
+// registry. The metrics are collected when registry is gathered.
+func newPrometheusReader(registry *prometheus.Registry) (metric.Reader, error) {
+	return otelprom.New(otelprom.WithRegisterer(registry))
+}
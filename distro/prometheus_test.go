@@ -0,0 +1,110 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+const promCounterName = "test.requests"
+
+// scrape returns the status code and the body of the response of the
+// Prometheus handler of sdk.
+func scrape(t *testing.T, sdk distro.SDK) (int, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	sdk.PrometheusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	return rec.Code, rec.Body.String()
+}
+
+func addPromCounter(t *testing.T, sdk distro.SDK) {
+	t.Helper()
+
+	cnt, err := sdk.MeterProvider().Meter(t.Name()).Int64Counter(promCounterName)
+	require.NoError(t, err)
+	cnt.Add(context.Background(), 3)
+}
+
+func TestRunWithPrometheusExporter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	sdk, err := distroRun(t, distro.WithPrometheusExporter(reg))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+
+	addPromCounter(t, sdk)
+
+	code, body := scrape(t, sdk)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "# TYPE test_requests_total counter")
+	assert.Regexp(t, `(?m)^test_requests_total\{.*\} 3$`, body)
+
+	// The metrics are registered with the passed registry.
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	var names []string
+	for _, mf := range mfs {
+		names = append(names, mf.GetName())
+	}
+	assert.Contains(t, names, "test_requests_total")
+}
+
+func TestRunWithPrometheusExporterNilRegistry(t *testing.T) {
+	sdk, err := distroRun(t, distro.WithPrometheusExporter(nil))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+
+	addPromCounter(t, sdk)
+
+	code, body := scrape(t, sdk)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "test_requests_total")
+}
+
+func TestRunWithPrometheusExporterAndOTLP(t *testing.T) {
+	coll := &collector{}
+	coll.Start(t)
+	t.Setenv("OTEL_METRICS_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+coll.Endpoint)
+
+	sdk, err := distroRun(t, distro.WithPrometheusExporter(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	addPromCounter(t, sdk)
+
+	_, body := scrape(t, sdk)
+	assert.Contains(t, body, "test_requests_total")
+
+	require.NoError(t, sdk.Shutdown(context.Background()))
+	assertHasMetric(t, coll.ExportedMetrics(), promCounterName)
+}
+
+func TestPrometheusHandlerNotConfigured(t *testing.T) {
+	sdk, err := distroRun(t)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+
+	code, _ := scrape(t, sdk)
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,122 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// Environment variables describing the resource.
+const (
+	otelResourceAttributesKey = "OTEL_RESOURCE_ATTRIBUTES"
+	otelServiceNameKey        = "OTEL_SERVICE_NAME"
+
+	// The deployment environment (e.g. "prod" or "staging"). The first one
+	// set is used.
+	splunkOTelEnvKey         = "SPLUNK_OTEL_ENV"
+	deploymentEnvironmentKey = "DEPLOYMENT_ENVIRONMENT"
+)
+
+// envDetector is a resource.Detector detecting the resource from the
+// OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME environment variables, as
+// resource.WithFromEnv, and the deployment environment from the
+// SPLUNK_OTEL_ENV or DEPLOYMENT_ENVIRONMENT environment variables. The values
+// of OTEL_RESOURCE_ATTRIBUTES are percent-decoded as defined by the
+// specification, so that a "+" is kept instead of being decoded as a space.
+type envDetector struct{}
+
+var _ resource.Detector = envDetector{}
+
+// Detect returns the resource described by the environment variables. The
+// service name of OTEL_SERVICE_NAME and the deployment environment take
+// precedence over the ones of OTEL_RESOURCE_ATTRIBUTES. A partial resource is
+// returned with an error wrapping resource.ErrPartialResource if
+// OTEL_RESOURCE_ATTRIBUTES has invalid entries.
+func (envDetector) Detect(context.Context) (*resource.Resource, error) {
+	attrs, err := parseResourceAttributes(os.Getenv(otelResourceAttributesKey))
+	// The last value of a key is kept by the attribute set.
+	if name := strings.TrimSpace(os.Getenv(otelServiceNameKey)); name != "" {
+		attrs = append(attrs, semconv.ServiceNameKey.String(name))
+	}
+	if env := envDeploymentEnvironment(); env != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentKey.String(env))
+	}
+	return resource.NewSchemaless(attrs...), err
+}
+
+// envDeploymentEnvironment returns the deployment environment set by the
+// SPLUNK_OTEL_ENV or, if unset or empty, the DEPLOYMENT_ENVIRONMENT
+// environment variables.
+func envDeploymentEnvironment() string {
+	for _, key := range []string{splunkOTelEnvKey, deploymentEnvironmentKey} {
+		if env := strings.TrimSpace(os.Getenv(key)); env != "" {
+			return env
+		}
+	}
+	return ""
+}
+
+// parseResourceAttributes returns the attributes of s, a comma-separated list
+// of key=value pairs with percent-encoded values. The keys and values are
+// trimmed of the surrounding whitespace and the empty entries are ignored.
+// The entries without a key, an "=", or with an invalid encoding are skipped
+// and returned in an error wrapping resource.ErrPartialResource.
+func parseResourceAttributes(s string) ([]attribute.KeyValue, error) {
+	var (
+		attrs   []attribute.KeyValue
+		invalid []string
+	)
+	for _, p := range strings.Split(s, ",") {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(p, "=")
+		key := strings.TrimSpace(k)
+		if !ok || key == "" {
+			invalid = append(invalid, p)
+			continue
+		}
+		val, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			invalid = append(invalid, p)
+			continue
+		}
+		attrs = append(attrs, attribute.String(key, val))
+	}
+	if len(invalid) > 0 {
+		return attrs, fmt.Errorf("%w: invalid %s entries: %q", resource.ErrPartialResource, otelResourceAttributesKey, invalid)
+	}
+	return attrs, nil
+}
+
+// resourceAttributes returns the attributes of m, passed with
+// WithResourceAttributes, skipping the empty keys.
+func resourceAttributes(m map[string]string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(m))
+	for k, v := range m {
+		if k != "" {
+			attrs = append(attrs, attribute.String(k, v))
+		}
+	}
+	return attrs
+}
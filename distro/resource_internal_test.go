@@ -0,0 +1,166 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+func TestParseResourceAttributes(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		in      string
+		want    []attribute.KeyValue
+		invalid bool
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc: "plain",
+			in:   "service.name=svc,deployment.environment=prod",
+			want: []attribute.KeyValue{
+				attribute.String("service.name", "svc"),
+				attribute.String("deployment.environment", "prod"),
+			},
+		},
+		{
+			desc: "encoded comma",
+			in:   "tags=a%2Cb%2Cc",
+			want: []attribute.KeyValue{attribute.String("tags", "a,b,c")},
+		},
+		{
+			desc: "encoded equals",
+			in:   "query=a%3Db,other=x%3D%3D",
+			want: []attribute.KeyValue{
+				attribute.String("query", "a=b"),
+				attribute.String("other", "x=="),
+			},
+		},
+		{
+			desc: "plus and spaces",
+			in:   "formula=1+1,phrase=hello%20world",
+			want: []attribute.KeyValue{
+				attribute.String("formula", "1+1"),
+				attribute.String("phrase", "hello world"),
+			},
+		},
+		{
+			desc: "unencoded equals",
+			in:   "url=http://host/?a=b",
+			want: []attribute.KeyValue{attribute.String("url", "http://host/?a=b")},
+		},
+		{
+			desc: "whitespace",
+			in:   " key1 = value1 ,, key2=value2 ,",
+			want: []attribute.KeyValue{
+				attribute.String("key1", "value1"),
+				attribute.String("key2", "value2"),
+			},
+		},
+		{
+			desc:    "invalid",
+			in:      "key=value,missing,=empty,bad=%zz",
+			want:    []attribute.KeyValue{attribute.String("key", "value")},
+			invalid: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := parseResourceAttributes(tc.in)
+			assert.Equal(t, tc.want, got)
+			if tc.invalid {
+				assert.ErrorIs(t, err, resource.ErrPartialResource)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEnvDetector(t *testing.T) {
+	t.Setenv(otelResourceAttributesKey, "service.name=attr,tags=a%2Cb")
+	t.Setenv(otelServiceNameKey, " env ")
+	t.Setenv(splunkOTelEnvKey, "")
+	t.Setenv(deploymentEnvironmentKey, "staging")
+
+	res, err := envDetector{}.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("deployment.environment", "staging"),
+		attribute.String("service.name", "env"),
+		attribute.String("tags", "a,b"),
+	}, res.Attributes())
+	assert.Empty(t, res.SchemaURL())
+}
+
+func TestNewResourceSchemaURL(t *testing.T) {
+	const schemaURL = "https://opentelemetry.io/schemas/1.20.0"
+
+	t.Run("default", func(t *testing.T) {
+		res, err := newResource(context.Background(), newTestConfig(t))
+		require.NoError(t, err)
+		assert.Equal(t, semconv.SchemaURL, res.SchemaURL())
+	})
+
+	t.Run("option", func(t *testing.T) {
+		res, err := newResource(context.Background(), newTestConfig(t,
+			WithSchemaURL(schemaURL),
+			WithServiceName("svc"),
+		))
+		require.NoError(t, err)
+		assert.Equal(t, schemaURL, res.SchemaURL())
+		v, ok := res.Set().Value(distroVerAttr)
+		assert.True(t, ok, "detected attributes must be kept")
+		assert.Equal(t, Version(), v.AsString())
+		v, _ = res.Set().Value(semconv.ServiceNameKey)
+		assert.Equal(t, "svc", v.AsString())
+	})
+
+	t.Run("same schema URL resource", func(t *testing.T) {
+		user := resource.NewWithAttributes(schemaURL, attribute.String("business.unit", "payments"))
+		res, err := newResource(context.Background(), newTestConfig(t,
+			WithSchemaURL(schemaURL),
+			WithResource(user),
+		))
+		require.NoError(t, err)
+		assert.Equal(t, schemaURL, res.SchemaURL())
+		assert.True(t, res.Set().HasValue("business.unit"))
+	})
+
+	t.Run("other schema URL resource", func(t *testing.T) {
+		user := resource.NewWithAttributes(semconv.SchemaURL, attribute.String("business.unit", "payments"))
+		_, err := newResource(context.Background(), newTestConfig(t,
+			WithSchemaURL(schemaURL),
+			WithResource(user),
+		))
+		assert.ErrorContains(t, err, "failed to merge user-provided resource")
+	})
+}
+
+func TestValidateSchemaURL(t *testing.T) {
+	assert.NoError(t, validateSchemaURL("https://opentelemetry.io/schemas/1.17.0"))
+	assert.NoError(t, validateSchemaURL("http://example.com/schema"))
+	for _, u := range []string{"opentelemetry.io/schemas/1.17.0", "ftp://example.com/schema", "https://", "https://exa mple.com", "%"} {
+		assert.Error(t, validateSchemaURL(u), u)
+	}
+}
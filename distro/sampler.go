@@ -0,0 +1,374 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	traceapi "go.opentelemetry.io/otel/trace"
+)
+
+// SamplingRule is a rule of the sampler returned by NewRuleBasedSampler. It
+// matches a span if all of its conditions match. A rule without any
+// condition matches all spans.
+type SamplingRule struct {
+	// SpanName matches the spans with this name. It is ignored if empty.
+	SpanName string
+	// Attributes matches the spans started with all of these attributes
+	// (e.g. semconv.HTTPRoute("/healthz")). Only the attributes passed when
+	// the span is started are evaluated.
+	Attributes []attribute.KeyValue
+	// Ratio is the ratio of the matched traces to sample. It is handled as
+	// by trace.TraceIDRatioBased: a ratio >= 1 samples all the traces, and a
+	// ratio <= 0 samples none.
+	Ratio float64
+}
+
+func (r SamplingRule) matches(p trace.SamplingParameters) bool {
+	if r.SpanName != "" && r.SpanName != p.Name {
+		return false
+	}
+	for _, want := range r.Attributes {
+		if !hasAttribute(p.Attributes, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, a := range attrs {
+		if a.Key == want.Key && a.Value == want.Value {
+			return true
+		}
+	}
+	return false
+}
+
+type ruleBasedSampler struct {
+	rules       []SamplingRule
+	samplers    []trace.Sampler
+	fallback    trace.Sampler
+	description string
+}
+
+var _ trace.Sampler = (*ruleBasedSampler)(nil)
+
+// NewRuleBasedSampler returns a Sampler sampling the traces with the ratio of
+// the first of the rules matching the span, evaluated in order. The traces
+// of the spans not matched by any rule are sampled with defaultRatio. The
+// sampler can be passed to WithSampler, for example, to sample all the spans
+// of a route and none of the health checks:
+//
+//	distro.WithSampler(distro.NewRuleBasedSampler(0.1,
+//		distro.SamplingRule{Attributes: []attribute.KeyValue{semconv.HTTPRoute("/healthz")}, Ratio: 0},
+//		distro.SamplingRule{SpanName: "POST /checkout", Ratio: 1},
+//	))
+//
+// The sampling decision of the parent span is not considered. Wrap the
+// sampler with trace.ParentBased to respect it.
+func NewRuleBasedSampler(defaultRatio float64, rules ...SamplingRule) trace.Sampler {
+	s := &ruleBasedSampler{
+		rules:    append([]SamplingRule(nil), rules...),
+		samplers: make([]trace.Sampler, len(rules)),
+		fallback: trace.TraceIDRatioBased(defaultRatio),
+	}
+	for i, r := range rules {
+		s.samplers[i] = trace.TraceIDRatioBased(r.Ratio)
+	}
+	s.description = fmt.Sprintf("RuleBasedSampler{rules:%d,default:%g}", len(rules), defaultRatio)
+	return s
+}
+
+func (s *ruleBasedSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	for i, r := range s.rules {
+		if r.matches(p) {
+			return s.samplers[i].ShouldSample(p)
+		}
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *ruleBasedSampler) Description() string {
+	return s.description
+}
+
+type rateLimitingSampler struct {
+	rate        float64 // Tokens added per second.
+	burst       float64 // Maximum number of tokens.
+	description string
+	now         func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+var _ trace.Sampler = (*rateLimitingSampler)(nil)
+
+// NewRateLimitingSampler returns a Sampler sampling at most spansPerSecond
+// root spans per second on average, with bursts of up to burst root spans.
+// It uses a token bucket holding up to burst tokens, initially full, refilled
+// with spansPerSecond tokens per second. Each sampled root span consumes a
+// token. The sampler is safe for concurrent use.
+//
+// The spans with a parent are not limited, they are sampled if the parent is
+// sampled. A non-positive spansPerSecond or burst samples no root spans.
+func NewRateLimitingSampler(spansPerSecond float64, burst int) trace.Sampler {
+	return newRateLimitingSampler(spansPerSecond, burst, time.Now)
+}
+
+func newRateLimitingSampler(spansPerSecond float64, burst int, now func() time.Time) *rateLimitingSampler {
+	rate := math.Max(spansPerSecond, 0)
+	b := math.Max(float64(burst), 0)
+	if rate == 0 {
+		// Without a refill no root span is sampled, not even a first burst.
+		b = 0
+	}
+	return &rateLimitingSampler{
+		rate:        rate,
+		burst:       b,
+		description: fmt.Sprintf("RateLimitingSampler{%g/s,burst:%d}", rate, int(b)),
+		now:         now,
+		tokens:      b,
+		last:        now(),
+	}
+}
+
+func (s *rateLimitingSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	psc := traceapi.SpanContextFromContext(p.ParentContext)
+	if psc.IsValid() {
+		decision := trace.Drop
+		if psc.IsSampled() {
+			decision = trace.RecordAndSample
+		}
+		return trace.SamplingResult{Decision: decision, Tracestate: psc.TraceState()}
+	}
+
+	decision := trace.Drop
+	if s.take() {
+		decision = trace.RecordAndSample
+	}
+	return trace.SamplingResult{Decision: decision, Tracestate: psc.TraceState()}
+}
+
+// take returns true and consumes a token if one is available.
+func (s *rateLimitingSampler) take() bool {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elapsed := now.Sub(s.last); elapsed > 0 {
+		s.tokens = math.Min(s.burst, s.tokens+elapsed.Seconds()*s.rate)
+		s.last = now
+	}
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+func (s *rateLimitingSampler) Description() string {
+	return s.description
+}
+
+// forceSampler samples the spans forced to be sampled and delegates the
+// decision for the other spans to base.
+type forceSampler struct {
+	base   trace.Sampler
+	forced func(context.Context) bool
+}
+
+var _ trace.Sampler = forceSampler{}
+
+// NewForceSampler returns a Sampler recording and sampling the spans started
+// in a context for which forced returns true, regardless of base and of the
+// sampling decision of the parent span, and delegating the decision for the
+// other spans to base.
+//
+// Pass it the function reporting the requests marked by an instrumentation,
+// e.g. ForceSampled of the
+// github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp
+// package for the requests with the force-sample header:
+//
+//	distro.Run(distro.WithSampler(distro.NewForceSampler(
+//		trace.ParentBased(trace.TraceIDRatioBased(0.01)),
+//		splunkhttp.ForceSampled,
+//	)))
+func NewForceSampler(base trace.Sampler, forced func(context.Context) bool) trace.Sampler {
+	return forceSampler{base: base, forced: forced}
+}
+
+// ShouldSample returns a RecordAndSample decision if the span is forced to
+// be sampled, otherwise the decision of the base sampler.
+func (s forceSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if s.forced != nil && s.forced(p.ParentContext) {
+		return trace.SamplingResult{
+			Decision:   trace.RecordAndSample,
+			Tracestate: traceapi.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.base.ShouldSample(p)
+}
+
+// Description returns the description of the sampler.
+func (s forceSampler) Description() string {
+	return fmt.Sprintf("ForceSampler{%s}", s.base.Description())
+}
+
+// syntheticSampler delegates the decision for the synthetic spans to
+// synthetic and for the other spans to base.
+type syntheticSampler struct {
+	base        trace.Sampler
+	synthetic   trace.Sampler
+	isSynthetic func(context.Context) bool
+}
+
+var _ trace.Sampler = syntheticSampler{}
+
+// NewSyntheticSampler returns a Sampler delegating the decision for the spans
+// started in a context for which isSynthetic returns true to synthetic, and
+// for the other spans to base (e.g. to sample less of the monitoring probe
+// requests).
+//
+// Pass it the function reporting the synthetic requests marked by an
+// instrumentation, e.g. Synthetic of the
+// github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp
+// package for the requests detected by its WithSyntheticDetector option:
+//
+//	distro.Run(distro.WithSampler(distro.NewSyntheticSampler(
+//		trace.ParentBased(trace.AlwaysSample()),
+//		trace.TraceIDRatioBased(0.01),
+//		splunkhttp.Synthetic,
+//	)))
+func NewSyntheticSampler(base, synthetic trace.Sampler, isSynthetic func(context.Context) bool) trace.Sampler {
+	return syntheticSampler{base: base, synthetic: synthetic, isSynthetic: isSynthetic}
+}
+
+// ShouldSample returns the decision of the synthetic sampler if the span is
+// synthetic, otherwise the decision of the base sampler.
+func (s syntheticSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if s.isSynthetic != nil && s.isSynthetic(p.ParentContext) {
+		return s.synthetic.ShouldSample(p)
+	}
+	return s.base.ShouldSample(p)
+}
+
+// Description returns the description of the sampler.
+func (s syntheticSampler) Description() string {
+	return fmt.Sprintf("SyntheticSampler{%s,%s}", s.base.Description(), s.synthetic.Description())
+}
+
+// errNoDynamicSampler is returned by SDK.SetSamplingRatio if the SDK was not
+// configured with WithDynamicSamplingRatio.
+var errNoDynamicSampler = errors.New("dynamic sampling ratio not configured: use WithDynamicSamplingRatio")
+
+// dynamicRatioSampler samples the traces with a ratio that can be updated
+// while spans are started.
+type dynamicRatioSampler struct {
+	// sampler holds the ratioSampler of the current ratio.
+	sampler atomic.Value
+}
+
+// ratioSampler holds a trace.TraceIDRatioBased sampler, which has a
+// different type for some ratios, to be stored in an atomic.Value.
+type ratioSampler struct {
+	trace.Sampler
+}
+
+var _ trace.Sampler = (*dynamicRatioSampler)(nil)
+
+func newDynamicRatioSampler(ratio float64) *dynamicRatioSampler {
+	s := &dynamicRatioSampler{}
+	// The ratio was validated before.
+	_ = s.setRatio(ratio)
+	return s
+}
+
+// setRatio sets the ratio of the traces sampled by the subsequent
+// decisions. It is handled as by trace.TraceIDRatioBased: a ratio >= 1
+// samples all the traces, and a ratio <= 0 samples none.
+func (s *dynamicRatioSampler) setRatio(ratio float64) error {
+	if math.IsNaN(ratio) {
+		return errors.New("invalid sampling ratio: NaN")
+	}
+	s.sampler.Store(ratioSampler{trace.TraceIDRatioBased(ratio)})
+	return nil
+}
+
+func (s *dynamicRatioSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return s.sampler.Load().(ratioSampler).ShouldSample(p)
+}
+
+func (s *dynamicRatioSampler) Description() string {
+	return fmt.Sprintf("DynamicRatioSampler{%s}", s.sampler.Load().(ratioSampler).Description())
+}
+
+// reloadSamplingRatio sets the ratio of s to the one returned by load each
+// time a signal is received on sigCh, until done is closed. The errors of
+// load are passed to the OpenTelemetry error handler and the ratio is kept.
+func reloadSamplingRatio(s *dynamicRatioSampler, load func() (float64, error), sigCh <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-sigCh:
+			ratio, err := load()
+			if err == nil {
+				err = s.setRatio(ratio)
+			}
+			if err != nil {
+				otel.Handle(fmt.Errorf("failed to reload the sampling ratio: %w", err))
+			}
+		}
+	}
+}
+
+// startSamplingRatioReload starts reloading the ratio of s with load on
+// SIGHUP. The returned function stops the reload.
+func startSamplingRatioReload(s *dynamicRatioSampler, load func() (float64, error)) shutdownFunc {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		reloadSamplingRatio(s, load, sigCh, done)
+	}()
+
+	var once sync.Once
+	return func(context.Context) error {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(done)
+			<-stopped
+		})
+		return nil
+	}
+}
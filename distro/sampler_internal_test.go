@@ -0,0 +1,267 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func sampledCount(s trace.Sampler, n int) int {
+	var sampled int
+	for i := 0; i < n; i++ {
+		if s.ShouldSample(trace.SamplingParameters{}).Decision == trace.RecordAndSample {
+			sampled++
+		}
+	}
+	return sampled
+}
+
+func TestRateLimitingSamplerRate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := newRateLimitingSampler(10, 5, clock.Now)
+
+	assert.Equal(t, 5, sampledCount(s, 100), "should sample a full burst")
+
+	clock.Advance(100 * time.Millisecond)
+	assert.Equal(t, 1, sampledCount(s, 100), "should sample the refilled tokens")
+
+	clock.Advance(250 * time.Millisecond)
+	assert.Equal(t, 2, sampledCount(s, 100), "should keep fractional tokens")
+	clock.Advance(50 * time.Millisecond)
+	assert.Equal(t, 1, sampledCount(s, 100), "should keep fractional tokens")
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, 5, sampledCount(s, 100), "should not exceed the burst")
+}
+
+func TestRateLimitingSamplerDisabled(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	for _, s := range []*rateLimitingSampler{
+		newRateLimitingSampler(0, 10, clock.Now),
+		newRateLimitingSampler(10, 0, clock.Now),
+		newRateLimitingSampler(-1, -1, clock.Now),
+	} {
+		clock.Advance(time.Minute)
+		assert.Zero(t, sampledCount(s, 100), s.Description())
+	}
+}
+
+func TestRateLimitingSamplerConcurrent(t *testing.T) {
+	const (
+		goroutines = 16
+		perTick    = 100
+		ticks      = 10
+		rate       = 50
+		burst      = 20
+	)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := newRateLimitingSampler(rate, burst, clock.Now)
+
+	var sampled int64
+	for tick := 0; tick < ticks; tick++ {
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				atomic.AddInt64(&sampled, int64(sampledCount(s, perTick)))
+			}()
+		}
+		wg.Wait()
+		clock.Advance(100 * time.Millisecond)
+	}
+
+	// The initial burst and the tokens refilled every 100ms.
+	want := burst + (ticks-1)*rate/10
+	assert.Equal(t, int64(want), sampled)
+}
+
+func TestRateLimitingSamplerRealClock(t *testing.T) {
+	s := NewRateLimitingSampler(100, 1)
+
+	var sampled int64
+	var wg sync.WaitGroup
+	stop := time.Now().Add(200 * time.Millisecond)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(stop) {
+				if s.ShouldSample(trace.SamplingParameters{}).Decision == trace.RecordAndSample {
+					atomic.AddInt64(&sampled, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// About 20 root spans are expected in 200ms at 100/s. Allow for
+	// scheduling delays of the test.
+	assert.LessOrEqual(t, sampled, int64(25))
+	assert.GreaterOrEqual(t, sampled, int64(5))
+}
+
+func BenchmarkRateLimitingSampler(b *testing.B) {
+	s := NewRateLimitingSampler(1000, 100)
+	p := trace.SamplingParameters{}
+
+	b.Run("Serial", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s.ShouldSample(p)
+		}
+	})
+
+	b.Run("Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.ShouldSample(p)
+			}
+		})
+	})
+}
+
+func TestDynamicRatioSampler(t *testing.T) {
+	s := newDynamicRatioSampler(0)
+	assert.Equal(t, 0, sampledCount(s, 100))
+	assert.Equal(t, "DynamicRatioSampler{TraceIDRatioBased{0}}", s.Description())
+
+	assert.NoError(t, s.setRatio(1))
+	assert.Equal(t, 100, sampledCount(s, 100))
+	assert.Equal(t, "DynamicRatioSampler{AlwaysOnSampler}", s.Description())
+
+	assert.Error(t, s.setRatio(math.NaN()))
+	assert.Equal(t, 100, sampledCount(s, 100), "ratio must be kept")
+
+	assert.NoError(t, s.setRatio(-1))
+	assert.Equal(t, 0, sampledCount(s, 100))
+}
+
+func TestDynamicRatioSamplerConcurrent(t *testing.T) {
+	s := newDynamicRatioSampler(0)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					sampledCount(s, 10)
+					_ = s.Description()
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, s.setRatio(float64(i%2)))
+	}
+	close(done)
+	wg.Wait()
+
+	assert.NoError(t, s.setRatio(1))
+	assert.Equal(t, 10, sampledCount(s, 10), "last ratio must be used")
+}
+
+func TestReloadSamplingRatio(t *testing.T) {
+	s := newDynamicRatioSampler(0)
+	sigCh := make(chan os.Signal)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	var (
+		mu      sync.Mutex
+		ratio   float64
+		loadErr error
+	)
+	load := func() (float64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return ratio, loadErr
+	}
+	set := func(r float64, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ratio, loadErr = r, err
+	}
+
+	go func() {
+		defer close(stopped)
+		reloadSamplingRatio(s, load, sigCh, done)
+	}()
+
+	set(1, nil)
+	// The unbuffered send returns once the previous signal is handled.
+	sigCh <- syscall.SIGHUP
+	sigCh <- syscall.SIGHUP
+	assert.Equal(t, 10, sampledCount(s, 10), "ratio must be reloaded")
+
+	set(0, errors.New("invalid file"))
+	sigCh <- syscall.SIGHUP
+	sigCh <- syscall.SIGHUP
+	assert.Equal(t, 10, sampledCount(s, 10), "ratio must be kept on error")
+
+	set(math.NaN(), nil)
+	sigCh <- syscall.SIGHUP
+	sigCh <- syscall.SIGHUP
+	assert.Equal(t, 10, sampledCount(s, 10), "invalid ratio must be ignored")
+
+	set(0, nil)
+	sigCh <- syscall.SIGHUP
+	sigCh <- syscall.SIGHUP
+	assert.Equal(t, 0, sampledCount(s, 10), "ratio must be reloaded")
+
+	close(done)
+	<-stopped
+}
+
+func TestStartSamplingRatioReloadStop(t *testing.T) {
+	stop := startSamplingRatioReload(newDynamicRatioSampler(0), func() (float64, error) { return 1, nil })
+	assert.NoError(t, stop(context.Background()))
+	assert.NoError(t, stop(context.Background()), "stop must be idempotent")
+}
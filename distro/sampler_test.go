@@ -0,0 +1,247 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+func TestRuleBasedSampler(t *testing.T) {
+	sampler := distro.NewRuleBasedSampler(1,
+		distro.SamplingRule{Attributes: []attribute.KeyValue{semconv.HTTPRoute("/healthz")}, Ratio: 0},
+		distro.SamplingRule{SpanName: "GET /metrics", Ratio: 0},
+		// Never reached for "GET /metrics": the rules are evaluated in order.
+		distro.SamplingRule{SpanName: "GET /metrics", Ratio: 1},
+		distro.SamplingRule{
+			SpanName:   "GET /users",
+			Attributes: []attribute.KeyValue{semconv.HTTPMethod("GET"), attribute.Bool("internal", true)},
+			Ratio:      0,
+		},
+	)
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(sr))
+	tracer := tp.Tracer(t.Name())
+
+	start := func(name string, attrs ...attribute.KeyValue) {
+		_, span := tracer.Start(context.Background(), name, trace.WithAttributes(attrs...))
+		span.End()
+	}
+	start("GET /healthz", semconv.HTTPRoute("/healthz"))
+	start("GET /metrics")
+	// Only one of the attributes of the rule.
+	start("GET /users", semconv.HTTPMethod("GET"))
+	start("GET /users", semconv.HTTPMethod("GET"), attribute.Bool("internal", true))
+	start("GET /orders", semconv.HTTPRoute("/orders"))
+
+	var names []string
+	for _, s := range sr.Ended() {
+		names = append(names, s.Name())
+	}
+	assert.Equal(t, []string{"GET /users", "GET /orders"}, names)
+}
+
+func TestRuleBasedSamplerDefaultRatio(t *testing.T) {
+	sampler := distro.NewRuleBasedSampler(0,
+		distro.SamplingRule{SpanName: "important", Ratio: 1},
+	)
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(sr))
+
+	for _, name := range []string{"important", "other", "important"} {
+		_, span := tp.Tracer(t.Name()).Start(context.Background(), name)
+		span.End()
+	}
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	for _, s := range spans {
+		assert.Equal(t, "important", s.Name())
+	}
+}
+
+func TestRuleBasedSamplerDescription(t *testing.T) {
+	sampler := distro.NewRuleBasedSampler(0.25, distro.SamplingRule{SpanName: "a", Ratio: 1})
+	assert.Equal(t, "RuleBasedSampler{rules:1,default:0.25}", sampler.Description())
+}
+
+func TestRateLimitingSamplerParent(t *testing.T) {
+	sampler := distro.NewRateLimitingSampler(1, 1)
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(sr))
+	tracer := tp.Tracer(t.Name())
+
+	// Consumes the only token.
+	ctx, root := tracer.Start(context.Background(), "root")
+	// Children of a sampled parent are not limited.
+	for i := 0; i < 10; i++ {
+		_, child := tracer.Start(ctx, "child")
+		child.End()
+	}
+	root.End()
+
+	// Limited: the bucket is empty.
+	ctx, dropped := tracer.Start(context.Background(), "dropped root")
+	// Children of a not sampled parent are not sampled.
+	_, child := tracer.Start(ctx, "dropped child")
+	child.End()
+	dropped.End()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 11)
+	for _, s := range spans {
+		assert.NotContains(t, s.Name(), "dropped")
+	}
+}
+
+func TestRateLimitingSamplerDescription(t *testing.T) {
+	sampler := distro.NewRateLimitingSampler(2.5, 10)
+	assert.Equal(t, "RateLimitingSampler{2.5/s,burst:10}", sampler.Description())
+}
+
+type forceKey struct{}
+
+func forced(ctx context.Context) bool {
+	v, _ := ctx.Value(forceKey{}).(bool)
+	return v
+}
+
+func TestForceSampler(t *testing.T) {
+	sampler := distro.NewForceSampler(sdktrace.NeverSample(), forced)
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(sr))
+	tracer := tp.Tracer(t.Name())
+
+	_, dropped := tracer.Start(context.Background(), "dropped")
+	dropped.End()
+
+	ts, err := trace.ParseTraceState("key=value")
+	require.NoError(t, err)
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceState: ts,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+	ctx = context.WithValue(ctx, forceKey{}, true)
+	_, span := tracer.Start(ctx, "forced")
+	span.End()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "forced", spans[0].Name())
+	assert.True(t, spans[0].SpanContext().IsSampled())
+	assert.Equal(t, ts, spans[0].SpanContext().TraceState(), "should keep the parent tracestate")
+}
+
+func TestForceSamplerDescription(t *testing.T) {
+	sampler := distro.NewForceSampler(sdktrace.NeverSample(), forced)
+	assert.Equal(t, "ForceSampler{AlwaysOffSampler}", sampler.Description())
+}
+
+type syntheticKey struct{}
+
+func synthetic(ctx context.Context) bool {
+	v, _ := ctx.Value(syntheticKey{}).(bool)
+	return v
+}
+
+func TestSyntheticSampler(t *testing.T) {
+	sampler := distro.NewSyntheticSampler(sdktrace.AlwaysSample(), sdktrace.NeverSample(), synthetic)
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(sr))
+	tracer := tp.Tracer(t.Name())
+
+	_, user := tracer.Start(context.Background(), "user")
+	user.End()
+	ctx := context.WithValue(context.Background(), syntheticKey{}, true)
+	_, dropped := tracer.Start(ctx, "synthetic")
+	dropped.End()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "user", spans[0].Name())
+}
+
+func TestSyntheticSamplerDescription(t *testing.T) {
+	sampler := distro.NewSyntheticSampler(sdktrace.AlwaysSample(), sdktrace.NeverSample(), synthetic)
+	assert.Equal(t, "SyntheticSampler{AlwaysOnSampler,AlwaysOffSampler}", sampler.Description())
+}
+
+func TestRunWithDynamicSamplingRatio(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	sdk, err := distroRun(t,
+		distro.WithDynamicSamplingRatio(0),
+		distro.WithAdditionalSpanProcessor(sr),
+		distro.WithGlobalRegistration(false),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+	tracer := sdk.TracerProvider().Tracer(t.Name())
+
+	startRoots := func(n int) {
+		for i := 0; i < n; i++ {
+			_, span := tracer.Start(context.Background(), "root")
+			span.End()
+		}
+	}
+
+	startRoots(10)
+	assert.Empty(t, sr.Ended(), "no root span must be sampled")
+
+	require.NoError(t, sdk.SetSamplingRatio(1))
+	startRoots(10)
+	assert.Len(t, sr.Ended(), 10, "all root spans must be sampled")
+
+	// The children follow the decision of their parent.
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	require.NoError(t, sdk.SetSamplingRatio(0))
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	parent.End()
+	startRoots(10)
+	assert.Len(t, sr.Ended(), 12, "only the child of the sampled parent must be sampled")
+
+	assert.Error(t, sdk.SetSamplingRatio(math.NaN()))
+}
+
+func TestSetSamplingRatioNotConfigured(t *testing.T) {
+	assert.Error(t, distro.SDK{}.SetSamplingRatio(1))
+
+	sdk, err := distroRun(t, distro.WithDynamicSamplingRatio(1), distro.WithSampler(sdktrace.AlwaysSample()))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, sdk.Shutdown(context.Background())) })
+	assert.Error(t, sdk.SetSamplingRatio(1), "WithSampler must replace the dynamic sampler")
+}
+
+func TestRunWithDynamicSamplingRatioInvalid(t *testing.T) {
+	_, err := distroRun(t, distro.WithDynamicSamplingRatio(math.NaN()))
+	assert.ErrorContains(t, err, "invalid dynamic sampling ratio")
+
+	load := func() (float64, error) { return 1, nil }
+	_, err = distroRun(t, distro.WithSamplingRatioReloadOnSIGHUP(load))
+	assert.ErrorContains(t, err, "requires a dynamic sampling ratio")
+}
@@ -0,0 +1,168 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// redacted replaces the sensitive values matched by the scrubber returned by
+// NewPIIScrubber.
+const redacted = "[REDACTED]"
+
+var (
+	emailRegexp = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// cardRegexp matches 13 to 19 digits, optionally separated by single
+	// spaces or dashes. The matches are only redacted if they pass the Luhn
+	// check.
+	cardRegexp = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// NewPIIScrubber returns a scrubber to pass to WithAttributeScrubber that
+// redacts the email addresses and credit card numbers in the string and
+// string slice values of the attributes with the passed keys. If no key is
+// passed, the values of all the attributes are redacted. The redacted parts
+// are replaced by "[REDACTED]", the attributes are never dropped.
+func NewPIIScrubber(keys ...string) func(attribute.KeyValue) (attribute.KeyValue, bool) {
+	set := make(map[attribute.Key]struct{}, len(keys))
+	for _, k := range keys {
+		set[attribute.Key(k)] = struct{}{}
+	}
+	return func(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+		if len(set) > 0 {
+			if _, ok := set[kv.Key]; !ok {
+				return kv, true
+			}
+		}
+		switch kv.Value.Type() {
+		case attribute.STRING:
+			return kv.Key.String(redactPII(kv.Value.AsString())), true
+		case attribute.STRINGSLICE:
+			v := kv.Value.AsStringSlice()
+			for i := range v {
+				v[i] = redactPII(v[i])
+			}
+			return kv.Key.StringSlice(v), true
+		default:
+			return kv, true
+		}
+	}
+}
+
+func redactPII(s string) string {
+	s = emailRegexp.ReplaceAllString(s, redacted)
+	return cardRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		if luhnValid(m) {
+			return redacted
+		}
+		return m
+	})
+}
+
+// luhnValid returns if the digits of s pass the Luhn check. The other
+// characters are ignored.
+func luhnValid(s string) bool {
+	var sum int
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// scrubbingProcessor is a SpanProcessor passing the ended spans to the
+// wrapped processor with their attributes scrubbed.
+//
+// The ended spans are read-only and the same span is passed to all the
+// processors registered with the TracerProvider, so the attributes cannot be
+// modified in place. Instead, each processor the spans are exported with is
+// wrapped and passed a view of the span with the scrubbed attributes.
+type scrubbingProcessor struct {
+	trace.SpanProcessor
+
+	scrubbers []func(attribute.KeyValue) (attribute.KeyValue, bool)
+}
+
+var _ trace.SpanProcessor = (*scrubbingProcessor)(nil)
+
+// newScrubbingProcessor returns sp wrapped to scrub the attributes of the
+// ended spans with the scrubbers, or sp if there are no scrubbers.
+func newScrubbingProcessor(sp trace.SpanProcessor, scrubbers []func(attribute.KeyValue) (attribute.KeyValue, bool)) trace.SpanProcessor {
+	if len(scrubbers) == 0 {
+		return sp
+	}
+	return &scrubbingProcessor{SpanProcessor: sp, scrubbers: scrubbers}
+}
+
+// OnEnd passes the span with its scrubbed attributes to the wrapped
+// processor.
+func (p *scrubbingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	attrs := s.Attributes()
+	scrubbed := make([]attribute.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		if kv, keep := p.scrub(kv); keep {
+			scrubbed = append(scrubbed, kv)
+		}
+	}
+	p.SpanProcessor.OnEnd(scrubbedSpan{
+		ReadOnlySpan: s,
+		attrs:        scrubbed,
+		dropped:      len(attrs) - len(scrubbed),
+	})
+}
+
+// scrub applies the scrubbers in order until one drops the attribute.
+func (p *scrubbingProcessor) scrub(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+	for _, s := range p.scrubbers {
+		var keep bool
+		if kv, keep = s(kv); !keep {
+			return kv, false
+		}
+	}
+	return kv, true
+}
+
+// scrubbedSpan is a ReadOnlySpan with scrubbed attributes.
+type scrubbedSpan struct {
+	trace.ReadOnlySpan
+
+	attrs   []attribute.KeyValue
+	dropped int
+}
+
+// Attributes returns the scrubbed attributes of the span.
+func (s scrubbedSpan) Attributes() []attribute.KeyValue {
+	return s.attrs
+}
+
+// DroppedAttributes returns the number of attributes dropped by the span
+// limits and the scrubbers.
+func (s scrubbedSpan) DroppedAttributes() int {
+	return s.ReadOnlySpan.DroppedAttributes() + s.dropped
+}
@@ -0,0 +1,66 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestRedactPII(t *testing.T) {
+	testCases := []struct {
+		in, want string
+	}{
+		{in: "no pii", want: "no pii"},
+		{in: "mail bob.smith+test@mail.example.com now", want: "mail [REDACTED] now"},
+		{in: "card 4111111111111111", want: "card [REDACTED]"},
+		{in: "card 4111 1111 1111 1111.", want: "card [REDACTED]."},
+		{in: "card 5500-0000-0000-0004", want: "card [REDACTED]"},
+		{in: "order 1234567890123456", want: "order 1234567890123456"},
+		{in: "short 411111111111", want: "short 411111111111"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, redactPII(tc.in), tc.in)
+	}
+}
+
+func TestNewPIIScrubber(t *testing.T) {
+	scrub := NewPIIScrubber("email", "cards")
+
+	got, keep := scrub(attribute.String("email", "bob@example.com"))
+	assert.True(t, keep)
+	assert.Equal(t, attribute.String("email", "[REDACTED]"), got)
+
+	got, keep = scrub(attribute.StringSlice("cards", []string{"4111111111111111", "none"}))
+	assert.True(t, keep)
+	assert.Equal(t, attribute.StringSlice("cards", []string{"[REDACTED]", "none"}), got)
+
+	kv := attribute.String("name", "bob@example.com")
+	got, keep = scrub(kv)
+	assert.True(t, keep)
+	assert.Equal(t, kv, got, "attribute not listed must not be redacted")
+
+	kv = attribute.Int("email", 1)
+	got, _ = scrub(kv)
+	assert.Equal(t, kv, got, "non-string attribute must not be redacted")
+}
+
+func TestNewPIIScrubberAllKeys(t *testing.T) {
+	got, keep := NewPIIScrubber()(attribute.String("name", "bob@example.com"))
+	assert.True(t, keep)
+	assert.Equal(t, attribute.String("name", "[REDACTED]"), got)
+}
@@ -0,0 +1,62 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+
+package distro_test
+
+import (
+	"context"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+func TestRunWithSamplingRatioReloadOnSIGHUP(t *testing.T) {
+	var loads int64
+	load := func() (float64, error) {
+		atomic.AddInt64(&loads, 1)
+		return 1, nil
+	}
+	sr := tracetest.NewSpanRecorder()
+	sdk, err := distroRun(t,
+		distro.WithDynamicSamplingRatio(0),
+		distro.WithSamplingRatioReloadOnSIGHUP(load),
+		distro.WithAdditionalSpanProcessor(sr),
+		distro.WithGlobalRegistration(false),
+	)
+	require.NoError(t, err)
+	tracer := sdk.TracerProvider().Tracer(t.Name())
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&loads) == 1
+	}, 5*time.Second, time.Millisecond, "ratio must be reloaded on SIGHUP")
+
+	// The ratio is set once load returns.
+	assert.Eventually(t, func() bool {
+		_, span := tracer.Start(context.Background(), "root")
+		span.End()
+		return len(sr.Ended()) > 0
+	}, 5*time.Second, time.Millisecond, "root spans must be sampled with the reloaded ratio")
+
+	assert.NoError(t, sdk.Shutdown(context.Background()))
+}
@@ -0,0 +1,77 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanStartHook is called with the parent context and the span when a span
+// is started, before the span is returned to the instrumentation. It can set
+// attributes on the span (e.g. from the baggage of ctx).
+type SpanStartHook func(ctx context.Context, span trace.ReadWriteSpan)
+
+// BaggageAttributesHook returns a SpanStartHook setting the members of the
+// baggage of the parent context with the passed keys (e.g.
+// TenantIDBaggageKey) as string attributes of the started spans. The
+// attributes are named after the keys. Members not in the baggage are
+// ignored.
+func BaggageAttributesHook(keys ...string) SpanStartHook {
+	keys = append([]string(nil), keys...)
+	return func(ctx context.Context, span trace.ReadWriteSpan) {
+		b := baggage.FromContext(ctx)
+		if b.Len() == 0 {
+			return
+		}
+		var attrs []attribute.KeyValue
+		for _, key := range keys {
+			if m := b.Member(key); m.Key() != "" {
+				attrs = append(attrs, attribute.String(key, m.Value()))
+			}
+		}
+		if len(attrs) > 0 {
+			span.SetAttributes(attrs...)
+		}
+	}
+}
+
+// spanStartHookProcessor is a SpanProcessor calling the hooks passed with
+// WithSpanStartHook when a span is started.
+type spanStartHookProcessor struct {
+	hooks []SpanStartHook
+}
+
+var _ trace.SpanProcessor = spanStartHookProcessor{}
+
+// OnStart calls the hooks with the parent context and the started span, in
+// order.
+func (p spanStartHookProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	for _, hook := range p.hooks {
+		hook(parent, s)
+	}
+}
+
+// OnEnd does nothing.
+func (spanStartHookProcessor) OnEnd(trace.ReadOnlySpan) {}
+
+// Shutdown does nothing.
+func (spanStartHookProcessor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush does nothing.
+func (spanStartHookProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,80 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+func TestRunWithSpanStartHook(t *testing.T) {
+	var (
+		calls   []string
+		started []attribute.KeyValue
+	)
+	rec := tracetest.NewSpanRecorder()
+	sdk, err := distroRun(t,
+		distro.WithAdditionalSpanProcessor(rec),
+		distro.WithSpanStartHook(distro.BaggageAttributesHook(distro.TenantIDBaggageKey, "missing")),
+		distro.WithSpanStartHook(nil),
+		distro.WithSpanStartHook(func(ctx context.Context, span sdktrace.ReadWriteSpan) {
+			calls = append(calls, span.Name())
+			// The attributes of the previous hooks are set when the span
+			// is started.
+			started = span.Attributes()
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, err := distro.SetBaggage(context.Background(), distro.TenantIDBaggageKey, "tenant-1")
+	require.NoError(t, err)
+	ctx, err = distro.SetBaggage(ctx, "other", "value")
+	require.NoError(t, err)
+	_, span := sdk.TracerProvider().Tracer(t.Name()).Start(ctx, spanName)
+	span.End()
+	require.NoError(t, sdk.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{spanName}, calls)
+	want := []attribute.KeyValue{attribute.String(distro.TenantIDBaggageKey, "tenant-1")}
+	assert.Equal(t, want, started, "baggage must be copied on start")
+	ended := rec.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, want, ended[0].Attributes(), "only selected members must be copied")
+}
+
+func TestBaggageAttributesHookNoBaggage(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	sdk, err := distroRun(t,
+		distro.WithAdditionalSpanProcessor(rec),
+		distro.WithSpanStartHook(distro.BaggageAttributesHook(distro.TenantIDBaggageKey)),
+	)
+	require.NoError(t, err)
+
+	_, span := sdk.TracerProvider().Tracer(t.Name()).Start(context.Background(), spanName)
+	span.End()
+	require.NoError(t, sdk.Shutdown(context.Background()))
+
+	ended := rec.Ended()
+	require.Len(t, ended, 1)
+	assert.Empty(t, ended[0].Attributes())
+}
@@ -0,0 +1,22 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+// Package splunkslog provides a log/slog Handler adding the trace context of
+// the active span to the log records (e.g. to correlate logs and traces in
+// Splunk Observability Cloud).
+//
+// The package requires Go 1.21 or later.
+package splunkslog // import "github.com/signalfx/splunk-otel-go/distro/splunkslog"
@@ -0,0 +1,38 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package splunkslog_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/signalfx/splunk-otel-go/distro/splunkslog"
+)
+
+func Example() {
+	logger := slog.New(splunkslog.NewHandler(slog.NewJSONHandler(os.Stdout, nil)))
+
+	ctx, span := otel.Tracer("my-service").Start(context.Background(), "operation")
+	defer span.End()
+
+	// The record has the trace_id, span_id, and trace_flags attributes if the
+	// span is recorded by an SDK (e.g. set up with distro.Run).
+	logger.InfoContext(ctx, "handling operation")
+}
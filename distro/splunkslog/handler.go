@@ -0,0 +1,79 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package splunkslog
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Keys of the attributes added to the log records.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// Handler is a slog.Handler adding the trace context of the span in the
+// context passed to Handle to the log records. The trace ID, span ID, and
+// trace flags are added as the trace_id, span_id, and trace_flags hex-encoded
+// string attributes. The records are passed unchanged to the wrapped handler
+// if the context does not contain a valid span context.
+//
+// The attributes are added to the group opened with WithGroup, if any.
+type Handler struct {
+	handler slog.Handler
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler wrapping h.
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{handler: h}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle adds the trace context of the span in ctx to r and passes it to the
+// wrapped handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		// The record must not be modified as it can be shared.
+		r = r.Clone()
+		r.AddAttrs(
+			slog.String(TraceIDKey, sc.TraceID().String()),
+			slog.String(SpanIDKey, sc.SpanID().String()),
+			slog.String(TraceFlagsKey, sc.TraceFlags().String()),
+		)
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a Handler wrapping the wrapped handler with attrs.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewHandler(h.handler.WithAttrs(attrs))
+}
+
+// WithGroup returns a Handler wrapping the wrapped handler with the group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return NewHandler(h.handler.WithGroup(name))
+}
@@ -0,0 +1,116 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package splunkslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/distro/splunkslog"
+)
+
+func TestHandlerWithSpan(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(splunkslog.NewHandler(slog.NewJSONHandler(&buf, nil)))
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer(t.Name()).Start(context.Background(), "span")
+	defer span.End()
+	logger.InfoContext(ctx, "message", "key", "value")
+
+	got := decode(t, &buf)
+	sc := span.SpanContext()
+	assert.Equal(t, "message", got["msg"])
+	assert.Equal(t, "value", got["key"])
+	assert.Equal(t, sc.TraceID().String(), got[splunkslog.TraceIDKey])
+	assert.Equal(t, sc.SpanID().String(), got[splunkslog.SpanIDKey])
+	assert.Equal(t, "01", got[splunkslog.TraceFlagsKey])
+}
+
+func TestHandlerWithoutSpan(t *testing.T) {
+	ctxs := map[string]context.Context{
+		"no span": context.Background(),
+		// E.g. the context of a non-recording span without a parent.
+		"invalid span context": trace.ContextWithSpanContext(context.Background(), trace.SpanContext{}),
+	}
+	for name, ctx := range ctxs {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(splunkslog.NewHandler(slog.NewJSONHandler(&buf, nil)))
+
+			logger.InfoContext(ctx, "message")
+
+			got := decode(t, &buf)
+			assert.Equal(t, "message", got["msg"])
+			assert.NotContains(t, got, splunkslog.TraceIDKey)
+			assert.NotContains(t, got, splunkslog.SpanIDKey)
+			assert.NotContains(t, got, splunkslog.TraceFlagsKey)
+		})
+	}
+}
+
+func TestHandlerWithAttrsAndGroup(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(splunkslog.NewHandler(slog.NewJSONHandler(&buf, nil)))
+	logger = logger.With("key", "value").WithGroup("group")
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer(t.Name()).Start(context.Background(), "span")
+	defer span.End()
+	logger.InfoContext(ctx, "message")
+
+	got := decode(t, &buf)
+	assert.Equal(t, "value", got["key"])
+	require.IsType(t, map[string]interface{}{}, got["group"])
+	group := got["group"].(map[string]interface{})
+	assert.Equal(t, span.SpanContext().TraceID().String(), group[splunkslog.TraceIDKey])
+}
+
+func TestHandlerEnabled(t *testing.T) {
+	h := splunkslog.NewHandler(slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelError))
+}
+
+func TestHandlerDoesNotModifyRecord(t *testing.T) {
+	var buf bytes.Buffer
+	h := splunkslog.NewHandler(slog.NewJSONHandler(&buf, nil))
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer(t.Name()).Start(context.Background(), "span")
+	defer span.End()
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+	require.NoError(t, h.Handle(ctx, r))
+	assert.Equal(t, 0, r.NumAttrs())
+}
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var got map[string]interface{}
+	require.NoError(t, json.NewDecoder(buf).Decode(&got))
+	return got
+}
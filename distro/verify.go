@@ -0,0 +1,162 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// verifyTracerName is the name of the Tracer of the span exported by
+	// Verify.
+	verifyTracerName = "github.com/signalfx/splunk-otel-go/distro"
+	// verifySpanName is the name of the span exported by Verify.
+	verifySpanName = "splunk-otel-go connectivity test"
+)
+
+var (
+	// ErrVerifyNoExporter is returned by Verify if no traces exporter is
+	// configured (i.e. OTEL_TRACES_EXPORTER is set to "none").
+	ErrVerifyNoExporter = errors.New("no traces exporter configured")
+	// ErrVerifyHostNotFound is wrapped by the error returned by Verify if the
+	// host of the endpoint cannot be resolved.
+	ErrVerifyHostNotFound = errors.New("endpoint host not found")
+	// ErrVerifyTLS is wrapped by the error returned by Verify if the TLS
+	// connection to the endpoint cannot be established (e.g. the certificate
+	// of the endpoint is not trusted).
+	ErrVerifyTLS = errors.New("TLS handshake failed")
+	// ErrVerifyUnauthorized is wrapped by the error returned by Verify if the
+	// export is rejected with a 401 or 403 status (e.g. the access token is
+	// missing or invalid).
+	ErrVerifyUnauthorized = errors.New("export unauthorized, check the access token")
+)
+
+// Verify verifies the traces exporter configured by the options and the
+// environment variables, as Run does, can export spans. It creates the
+// exporter, exports a single test span, and shuts the exporter down. Nothing
+// is registered globally.
+//
+// A nil error is returned if the span is exported. Otherwise, the returned
+// error describes the failure, and wraps ErrVerifyHostNotFound,
+// ErrVerifyTLS, or ErrVerifyUnauthorized for the common failures. The export
+// is not retried and is bounded by ctx. It is meant to verify the
+// connectivity to the collector or Splunk Observability Cloud when
+// bootstrapping new environments.
+func Verify(ctx context.Context, opts ...Option) error {
+	c, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+	if c.TracesExporterFunc == nil {
+		return ErrVerifyNoExporter
+	}
+	// The export has to fail fast.
+	c.ExportConfig.RetryConfig = &RetryConfig{Enabled: false}
+
+	res, err := newResource(ctx, c)
+	if err != nil {
+		return err
+	}
+	if !serviceNameDefined(res) {
+		// The merge of a schemaless resource cannot fail.
+		res, _ = resource.Merge(res, resource.NewSchemaless(semconv.ServiceNameKey.String(derivedServiceName(os.Args))))
+	}
+
+	exp, err := c.TracesExporterFunc(ctx, c.ExportConfig.signalConfig(c.ExportConfig.TracesEndpoint))
+	if err != nil {
+		return err
+	}
+
+	// The span is recorded to be exported synchronously, the errors of the
+	// span processors are not returned.
+	rec := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithResource(res), trace.WithSpanProcessor(rec))
+	tracer := tp.Tracer(verifyTracerName,
+		traceapi.WithInstrumentationVersion(Version()),
+		traceapi.WithSchemaURL(res.SchemaURL()),
+	)
+	_, span := tracer.Start(ctx, verifySpanName)
+	span.End()
+	_ = tp.Shutdown(ctx) // The recorder never fails.
+
+	err = exp.ExportSpans(ctx, rec.Ended())
+	if shutdownErr := exp.Shutdown(context.Background()); err == nil {
+		err = shutdownErr
+	}
+	if err != nil {
+		return verifyError(err)
+	}
+	return nil
+}
+
+// verifyError returns err wrapped in an error describing the failure of the
+// export.
+func verifyError(err error) error {
+	if cause := verifyCause(err); cause != nil {
+		return fmt.Errorf("%w: %v", cause, err)
+	}
+	return fmt.Errorf("export failed: %w", err)
+}
+
+// verifyCause returns the sentinel error of the cause of the export failure
+// err, or nil if it is unknown. The errors of the gRPC exporter are only
+// available as messages.
+func verifyCause(err error) error {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrVerifyHostNotFound
+	}
+	var (
+		unknownAuthErr x509.UnknownAuthorityError
+		hostnameErr    x509.HostnameError
+		certErr        x509.CertificateInvalidError
+	)
+	if errors.As(err, &unknownAuthErr) || errors.As(err, &hostnameErr) || errors.As(err, &certErr) {
+		return ErrVerifyTLS
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return ErrVerifyUnauthorized
+		}
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "no such host"), strings.Contains(msg, "produced zero addresses"):
+		return ErrVerifyHostNotFound
+	case strings.Contains(msg, "x509:"), strings.Contains(msg, "tls:"):
+		return ErrVerifyTLS
+	case strings.Contains(msg, "401 Unauthorized"), strings.Contains(msg, "403 Forbidden"),
+		// The Jaeger exporter.
+		strings.Contains(msg, "HTTP status code: 401"), strings.Contains(msg, "HTTP status code: 403"):
+		return ErrVerifyUnauthorized
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	ctpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/signalfx/splunk-otel-go/distro"
+)
+
+func TestVerifyOTLPHTTP(t *testing.T) {
+	testCases := []struct {
+		status  int
+		wantErr error
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusUnauthorized, wantErr: distro.ErrVerifyUnauthorized},
+		{status: http.StatusForbidden, wantErr: distro.ErrVerifyUnauthorized},
+		{status: http.StatusInternalServerError},
+	}
+	for _, tc := range testCases {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				_, _ = io.Copy(io.Discard, r.Body)
+				w.WriteHeader(tc.status)
+			}))
+			t.Cleanup(srv.Close)
+			t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+			err := distro.Verify(context.Background(),
+				distro.WithOTLPProtocol("http/protobuf"),
+				distro.WithEndpoint(srv.URL),
+			)
+			assert.Equal(t, 1, requests, "a single export must be sent")
+			switch {
+			case tc.status == http.StatusOK:
+				assert.NoError(t, err)
+			case tc.wantErr != nil:
+				assert.ErrorIs(t, err, tc.wantErr)
+			default:
+				assert.ErrorContains(t, err, "export failed")
+			}
+		})
+	}
+}
+
+func TestVerifyOTLPGRPC(t *testing.T) {
+	coll := &collector{}
+	coll.Start(t)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+coll.Endpoint)
+
+	require.NoError(t, distro.Verify(context.Background(), distro.WithServiceName("verify")))
+
+	got := coll.ExportedSpans()
+	require.NotNil(t, got)
+	require.Len(t, got.Spans, 1)
+	assert.Equal(t, "splunk-otel-go connectivity test", got.Spans[0].Name)
+	assert.Contains(t, got.Resource.GetAttributes(), strKeyValue("service.name", "verify"))
+}
+
+// rejectingTraceService rejects the exports with its code.
+type rejectingTraceService struct {
+	ctpb.UnimplementedTraceServiceServer
+
+	code codes.Code
+}
+
+func (s rejectingTraceService) Export(context.Context, *ctpb.ExportTraceServiceRequest) (*ctpb.ExportTraceServiceResponse, error) {
+	return nil, status.Error(s.code, "rejected")
+}
+
+func TestVerifyOTLPGRPCRejected(t *testing.T) {
+	testCases := []struct {
+		code    codes.Code
+		wantErr error
+	}{
+		{code: codes.Unauthenticated, wantErr: distro.ErrVerifyUnauthorized},
+		{code: codes.PermissionDenied, wantErr: distro.ErrVerifyUnauthorized},
+		{code: codes.Unavailable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.code.String(), func(t *testing.T) {
+			ln, err := net.Listen("tcp", "localhost:0")
+			require.NoError(t, err)
+			srv := grpc.NewServer()
+			ctpb.RegisterTraceServiceServer(srv, rejectingTraceService{code: tc.code})
+			go func() { _ = srv.Serve(ln) }()
+			t.Cleanup(srv.Stop)
+			t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+ln.Addr().String())
+
+			err = distro.Verify(context.Background())
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.ErrorContains(t, err, "export failed")
+			}
+		})
+	}
+}
+
+func TestVerifyHostNotFound(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+	err := distro.Verify(context.Background(),
+		distro.WithOTLPProtocol("http/protobuf"),
+		distro.WithEndpoint("http://collector.invalid:4318"),
+	)
+	assert.ErrorIs(t, err, distro.ErrVerifyHostNotFound)
+}
+
+func TestVerifyTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+	// The certificate of the server is not trusted.
+	err := distro.Verify(context.Background(),
+		distro.WithOTLPProtocol("http/protobuf"),
+		distro.WithEndpoint(srv.URL),
+	)
+	assert.ErrorIs(t, err, distro.ErrVerifyTLS)
+}
+
+func TestVerifyNoExporter(t *testing.T) {
+	assert.ErrorIs(t, distro.Verify(context.Background()), distro.ErrVerifyNoExporter)
+}
+
+func TestVerifyNotRegistered(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+	tp := otel.GetTracerProvider()
+	prop := otel.GetTextMapPropagator()
+	require.NoError(t, distro.Verify(context.Background(),
+		distro.WithOTLPProtocol("http/protobuf"),
+		distro.WithEndpoint(srv.URL),
+	))
+	assert.Equal(t, tp, otel.GetTracerProvider(), "TracerProvider must not be registered")
+	assert.Equal(t, prop, otel.GetTextMapPropagator(), "propagator must not be registered")
+}
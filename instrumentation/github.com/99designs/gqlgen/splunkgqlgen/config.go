@@ -0,0 +1,93 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkgqlgen
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
+)
+
+// instrumentationName is the instrumentation library identifier for a Tracer.
+const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/github.com/99designs/gqlgen/splunkgqlgen"
+
+type config struct {
+	*internal.Config
+
+	fieldSpans bool
+}
+
+func newConfig(options ...Option) *config {
+	c := config{
+		Config: internal.NewConfig(instrumentationName, internal.OptionFunc(
+			func(c *internal.Config) {
+				c.Version = Version()
+			}),
+		),
+	}
+
+	for _, o := range options {
+		if o != nil {
+			o.apply(&c)
+		}
+	}
+
+	return &c
+}
+
+// Option applies options to a configuration.
+type Option interface {
+	apply(*config)
+}
+
+type optionConv struct {
+	iOpt internal.Option
+}
+
+func (o optionConv) apply(c *config) {
+	o.iOpt.Apply(c.Config)
+}
+
+// WithTracerProvider returns an Option that sets the TracerProvider used with
+// this instrumentation library.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionConv{iOpt: internal.WithTracerProvider(tp)}
+}
+
+// WithAttributes returns an Option that appends attr to the attributes set
+// for every span created with this instrumentation library.
+func WithAttributes(attr []attribute.KeyValue) Option {
+	return optionConv{iOpt: internal.WithAttributes(attr)}
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithFieldSpans returns an Option that sets whether a span is created for
+// the resolution of each field with a resolver (i.e. a resolver function or
+// a method of the model). As a span is created for each resolved element of
+// a list, this can create a large number of spans. The field spans are
+// children of the operation span.
+//
+// By default, only the operations are traced.
+func WithFieldSpans(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.fieldSpans = enabled
+	})
+}
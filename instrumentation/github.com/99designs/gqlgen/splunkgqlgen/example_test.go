@@ -0,0 +1,43 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkgqlgen_test
+
+import (
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/99designs/gqlgen/splunkgqlgen"
+)
+
+func Example() {
+	// The schema generated by gqlgen, e.g.:
+	//
+	//	schema := generated.NewExecutableSchema(generated.Config{Resolvers: &graph.Resolver{}})
+	var schema graphql.ExecutableSchema
+
+	srv := handler.NewDefaultServer(schema)
+	srv.Use(splunkgqlgen.NewTracer())
+	http.Handle("/query", srv)
+
+	/*
+		if err := http.ListenAndServe(":8080", nil); err != nil {
+			panic(err)
+		}
+
+		...
+	*/
+}
@@ -0,0 +1,23 @@
+module github.com/signalfx/splunk-otel-go/instrumentation/github.com/99designs/gqlgen/splunkgqlgen
+
+go 1.19
+
+require (
+	github.com/99designs/gqlgen v0.17.36
+	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.7.0
+	github.com/vektah/gqlparser/v2 v2.5.8
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/agnivade/levenshtein v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/gorilla/websocket v1.5.0 // indirect
+	github.com/hashicorp/golang-lru/v2 v2.0.3 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+)
+
+replace github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../internal/
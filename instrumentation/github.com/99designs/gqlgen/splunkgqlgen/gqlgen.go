@@ -0,0 +1,168 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunkgqlgen provides OpenTelemetry instrumentation for the
+// github.com/99designs/gqlgen module.
+package splunkgqlgen
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GraphQL field attributes.
+const (
+	fieldNameKey  = attribute.Key("graphql.field.name")
+	fieldPathKey  = attribute.Key("graphql.field.path")
+	fieldTypeKey  = attribute.Key("graphql.field.type")
+	fieldAliasKey = attribute.Key("graphql.field.alias")
+)
+
+// Tracer is a gqlgen extension tracing the GraphQL operations, and
+// optionally the field resolutions, with OpenTelemetry. Add it to a gqlgen
+// server with its Use method.
+type Tracer struct {
+	cfg *config
+}
+
+var (
+	_ graphql.HandlerExtension    = (*Tracer)(nil)
+	_ graphql.ResponseInterceptor = (*Tracer)(nil)
+	_ graphql.FieldInterceptor    = (*Tracer)(nil)
+)
+
+// NewTracer returns a new Tracer.
+//
+// A span is created for each GraphQL operation response. It is named after
+// the operation type and name (e.g. "query GetUser"), and has the
+// graphql.operation.type, graphql.operation.name, and graphql.document
+// attributes. For subscriptions, a span is created for each response sent.
+// The errors of the response are recorded as exception events of the span.
+//
+// Use WithFieldSpans to also create a span for each field resolution.
+func NewTracer(opts ...Option) *Tracer {
+	return &Tracer{cfg: newConfig(opts...)}
+}
+
+// ExtensionName returns the name of the extension.
+func (*Tracer) ExtensionName() string {
+	return "SplunkOpenTelemetryTracer"
+}
+
+// Validate validates the schema of the server. Any schema is supported.
+func (*Tracer) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse traces the creation of a GraphQL operation response.
+func (t *Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+
+	name := "GraphQL operation"
+	attrs := []attribute.KeyValue{semconv.GraphqlDocument(oc.RawQuery)}
+	if oc.Operation != nil {
+		opType := string(oc.Operation.Operation)
+		attrs = append(attrs, semconv.GraphqlOperationTypeKey.String(opType))
+		name = opType
+		if oc.Operation.Name != "" {
+			name += " " + oc.Operation.Name
+		}
+	}
+	if opName := operationName(oc); opName != "" {
+		attrs = append(attrs, semconv.GraphqlOperationName(opName))
+	}
+
+	opts := t.cfg.MergedSpanStartOptions(
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+	ctx, span := t.cfg.ResolveTracer(ctx).Start(ctx, name, opts...)
+	defer span.End()
+
+	resp := next(ctx)
+	if resp != nil {
+		recordErrors(span, resp.Errors)
+	}
+	return resp
+}
+
+// operationName returns the name of the executed operation. It is the name
+// of the operation in the document, or the one requested by the client if
+// the operation could not be resolved.
+func operationName(oc *graphql.OperationContext) string {
+	if oc.Operation != nil && oc.Operation.Name != "" {
+		return oc.Operation.Name
+	}
+	return oc.OperationName
+}
+
+// InterceptField traces the resolution of a GraphQL field if field spans
+// are enabled. Only the fields with a resolver are traced.
+func (t *Tracer) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	if !t.cfg.fieldSpans {
+		return next(ctx)
+	}
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || (!fc.IsResolver && !fc.IsMethod) {
+		return next(ctx)
+	}
+
+	opts := t.cfg.MergedSpanStartOptions(
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			fieldNameKey.String(fc.Field.Name),
+			fieldPathKey.String(fc.Path().String()),
+			fieldTypeKey.String(fc.Object),
+		),
+	)
+	ctx, span := t.cfg.ResolveTracer(ctx).Start(ctx, fc.Object+"."+fc.Field.Name, opts...)
+	defer span.End()
+	if fc.Field.Alias != "" && fc.Field.Alias != fc.Field.Name {
+		span.SetAttributes(fieldAliasKey.String(fc.Field.Alias))
+	}
+
+	res, err := next(ctx)
+	errs := graphql.GetFieldErrors(ctx, fc)
+	if err != nil {
+		errs = append(gqlerror.List{gqlerror.WrapPath(fc.Path(), err)}, errs...)
+	}
+	recordErrors(span, errs)
+	return res, err
+}
+
+// recordErrors records errs as exception events of span and sets the span
+// status to Error if there is any.
+func recordErrors(span trace.Span, errs gqlerror.List) {
+	for _, err := range errs {
+		span.RecordError(err)
+	}
+	switch n := len(errs); n {
+	case 0:
+		// Nothing to do.
+	case 1:
+		span.SetStatus(codes.Error, errs[0].Message)
+	default:
+		span.SetStatus(codes.Error, fmt.Sprintf("%s (and %d more errors)", errs[0].Message, n-1))
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const testSchema = `
+	type Query {
+		user(id: Int!): User
+		users: [User!]!
+	}
+	type Mutation {
+		rename(id: Int!, name: String!): User
+	}
+	type User {
+		id: Int!
+		name: String!
+		friends: [User!]!
+	}
+`
+
+type user struct {
+	ID      int64
+	Name    string
+	Friends []int64
+}
+
+var users = map[int64]*user{
+	1: {ID: 1, Name: "alice", Friends: []int64{2, 3}},
+	2: {ID: 2, Name: "bob", Friends: []int64{1}},
+	3: {ID: 3, Name: "carol"},
+}
+
+var errNotFound = errors.New("user not found")
+
+func findUser(id int64) (*user, error) {
+	if u, ok := users[id]; ok {
+		return u, nil
+	}
+	return nil, errNotFound
+}
+
+type resolverFunc func(ctx context.Context, obj *user, args map[string]interface{}) (interface{}, error)
+
+// resolvers are the resolvers of the fields that are not resolved from the
+// fields of their object.
+var resolvers = map[string]resolverFunc{
+	"Query.user": func(_ context.Context, _ *user, args map[string]interface{}) (interface{}, error) {
+		return findUser(args["id"].(int64))
+	},
+	"Query.users": func(context.Context, *user, map[string]interface{}) (interface{}, error) {
+		return []*user{users[1], users[2], users[3]}, nil
+	},
+	"Mutation.rename": func(_ context.Context, _ *user, args map[string]interface{}) (interface{}, error) {
+		u, err := findUser(args["id"].(int64))
+		if err != nil {
+			return nil, err
+		}
+		return &user{ID: u.ID, Name: args["name"].(string), Friends: u.Friends}, nil
+	},
+	"User.friends": func(_ context.Context, obj *user, _ map[string]interface{}) (interface{}, error) {
+		friends := make([]*user, 0, len(obj.Friends))
+		for _, id := range obj.Friends {
+			friends = append(friends, users[id])
+		}
+		return friends, nil
+	},
+}
+
+// executableSchema is a minimal graphql.ExecutableSchema for the test schema
+// simulating the field execution of the code generated by gqlgen.
+type executableSchema struct {
+	schema *ast.Schema
+}
+
+var _ graphql.ExecutableSchema = (*executableSchema)(nil)
+
+func newExecutableSchema() *executableSchema {
+	return &executableSchema{
+		schema: gqlparser.MustLoadSchema(&ast.Source{Input: testSchema}),
+	}
+}
+
+func (e *executableSchema) Schema() *ast.Schema {
+	return e.schema
+}
+
+func (e *executableSchema) Complexity(string, string, int, map[string]interface{}) (int, bool) {
+	return 0, false
+}
+
+func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
+	oc := graphql.GetOperationContext(ctx)
+	var typeName string
+	switch oc.Operation.Operation {
+	case ast.Query:
+		typeName = "Query"
+	case ast.Mutation:
+		typeName = "Mutation"
+	default:
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "unsupported GraphQL operation"))
+	}
+
+	var ran bool
+	return func(ctx context.Context) *graphql.Response {
+		if ran {
+			return nil
+		}
+		ran = true
+
+		data := e.object(ctx, typeName, oc.Operation.SelectionSet, nil)
+		// The errors are added to the response by the gqlgen executor.
+		return &graphql.Response{Data: data}
+	}
+}
+
+func (e *executableSchema) object(ctx context.Context, typeName string, sel ast.SelectionSet, obj *user) json.RawMessage {
+	oc := graphql.GetOperationContext(ctx)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range graphql.CollectFields(oc, sel, []string{typeName}) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Alias)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(e.field(ctx, typeName, f, obj))
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+func (e *executableSchema) field(ctx context.Context, typeName string, f graphql.CollectedField, obj *user) json.RawMessage {
+	oc := graphql.GetOperationContext(ctx)
+	resolver, isResolver := resolvers[typeName+"."+f.Name]
+	fc := &graphql.FieldContext{
+		Object:     typeName,
+		Field:      f,
+		Args:       f.ArgumentMap(oc.Variables),
+		IsResolver: isResolver,
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+
+	res, err := oc.ResolverMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		if isResolver {
+			return resolver(ctx, obj, fc.Args)
+		}
+		switch f.Name {
+		case "id":
+			return obj.ID, nil
+		case "name":
+			return obj.Name, nil
+		}
+		return nil, fmt.Errorf("unknown field %s.%s", typeName, f.Name)
+	})
+	if err != nil {
+		graphql.AddError(ctx, err)
+		return json.RawMessage("null")
+	}
+	fc.Result = res
+
+	switch v := res.(type) {
+	case *user:
+		return e.object(ctx, "User", f.Selections, v)
+	case []*user:
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, u := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			i := i
+			ctx := graphql.WithFieldContext(ctx, &graphql.FieldContext{Index: &i, Result: u})
+			buf.Write(e.object(ctx, "User", f.Selections, u))
+		}
+		buf.WriteByte(']')
+		return buf.Bytes()
+	default:
+		data, _ := json.Marshal(v)
+		return data
+	}
+}
@@ -0,0 +1,33 @@
+module github.com/signalfx/splunk-otel-go/instrumentation/github.com/99designs/gqlgen/splunkgqlgen/test
+
+go 1.19
+
+require (
+	github.com/99designs/gqlgen v0.17.36
+	github.com/signalfx/splunk-otel-go/instrumentation/github.com/99designs/gqlgen/splunkgqlgen v1.7.0
+	github.com/stretchr/testify v1.8.4
+	github.com/vektah/gqlparser/v2 v2.5.8
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/sdk v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/agnivade/levenshtein v1.1.1 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/gorilla/websocket v1.5.0 // indirect
+	github.com/hashicorp/golang-lru/v2 v2.0.3 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.7.0 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+	golang.org/x/sys v0.8.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/signalfx/splunk-otel-go/instrumentation/github.com/99designs/gqlgen/splunkgqlgen => ../
+	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../../internal/
+)
@@ -0,0 +1,255 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package test provides end-to-end testing of the splunkgqlgen instrumentation
+with the default SDK.
+
+This package is in a separate module from the instrumentation it tests to
+isolate the dependency of the default SDK and not impose this as a transitive
+dependency for users.
+*/
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/99designs/gqlgen/splunkgqlgen"
+)
+
+func fixtures(t *testing.T, opts ...splunkgqlgen.Option) (*tracetest.SpanRecorder, http.Handler) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	srv := handler.New(newExecutableSchema())
+	srv.AddTransport(transport.POST{})
+	srv.Use(splunkgqlgen.NewTracer(append(opts, splunkgqlgen.WithTracerProvider(tp))...))
+	return sr, srv
+}
+
+func do(t *testing.T, h http.Handler, query string, vars map[string]interface{}) string {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Body.String()
+}
+
+// spansByName returns the ended spans by name. The test fails if two spans
+// have the same name.
+func spansByName(t *testing.T, sr *tracetest.SpanRecorder) map[string]trace.ReadOnlySpan {
+	spans := make(map[string]trace.ReadOnlySpan)
+	for _, s := range sr.Ended() {
+		require.NotContains(t, spans, s.Name(), "duplicate span")
+		spans[s.Name()] = s
+	}
+	return spans
+}
+
+func assertChildOf(t *testing.T, parent, child trace.ReadOnlySpan) {
+	t.Helper()
+	assert.Equal(t, parent.SpanContext().TraceID(), child.SpanContext().TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), child.Parent().SpanID(), "%s is not a child of %s", child.Name(), parent.Name())
+}
+
+const getUser = `query GetUser($id: Int!) { user(id: $id) { id name friends { name } } }`
+
+func TestOperation(t *testing.T) {
+	sr, h := fixtures(t)
+
+	got := do(t, h, getUser, map[string]interface{}{"id": 1})
+	assert.JSONEq(t, `{"data":{"user":{"id":1,"name":"alice","friends":[{"name":"bob"},{"name":"carol"}]}}}`, got)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1, "field spans are disabled by default")
+	s := spans[0]
+	assert.Equal(t, "query GetUser", s.Name())
+	assert.Equal(t, traceapi.SpanKindServer, s.SpanKind())
+	assert.Equal(t, splunkgqlgen.Version(), s.InstrumentationLibrary().Version)
+	assert.Contains(t, s.Attributes(), semconv.GraphqlOperationTypeQuery)
+	assert.Contains(t, s.Attributes(), semconv.GraphqlOperationName("GetUser"))
+	assert.Contains(t, s.Attributes(), semconv.GraphqlDocument(getUser))
+	assert.Equal(t, codes.Unset, s.Status().Code)
+	assert.Empty(t, s.Events())
+}
+
+func TestOperationAnonymous(t *testing.T) {
+	sr, h := fixtures(t)
+
+	do(t, h, "{ users { id } }", nil)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "query", spans[0].Name())
+	for _, a := range spans[0].Attributes() {
+		assert.NotEqual(t, semconv.GraphqlOperationNameKey, a.Key)
+	}
+}
+
+func TestOperationMutation(t *testing.T) {
+	sr, h := fixtures(t)
+
+	got := do(t, h, `mutation Rename { rename(id: 2, name: "robert") { name } }`, nil)
+	assert.JSONEq(t, `{"data":{"rename":{"name":"robert"}}}`, got)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "mutation Rename", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), semconv.GraphqlOperationTypeMutation)
+	assert.Contains(t, spans[0].Attributes(), semconv.GraphqlOperationName("Rename"))
+}
+
+func TestOperationParseError(t *testing.T) {
+	sr, h := fixtures(t)
+
+	do(t, h, "query Broken {", nil)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "GraphQL operation", s.Name())
+	assert.Contains(t, s.Attributes(), semconv.GraphqlDocument("query Broken {"))
+	assert.Equal(t, codes.Error, s.Status().Code)
+	require.Len(t, s.Events(), 1)
+	assert.Equal(t, "exception", s.Events()[0].Name)
+}
+
+func TestFieldSpans(t *testing.T) {
+	sr, h := fixtures(t, splunkgqlgen.WithFieldSpans(true))
+
+	do(t, h, getUser, map[string]interface{}{"id": 1})
+
+	spans := spansByName(t, sr)
+	// Trivial fields (id and name) are not traced.
+	require.Len(t, spans, 3)
+	op, user, friends := spans["query GetUser"], spans["Query.user"], spans["User.friends"]
+	require.NotNil(t, op)
+	require.NotNil(t, user)
+	require.NotNil(t, friends)
+
+	// The gqlgen executor resolves the nested fields once the parent field
+	// resolver returns, so all the field spans are children of the operation
+	// span.
+	assert.False(t, op.Parent().IsValid())
+	assertChildOf(t, op, user)
+	assertChildOf(t, op, friends)
+
+	assert.Equal(t, traceapi.SpanKindInternal, user.SpanKind())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("graphql.field.name", "user"),
+		attribute.String("graphql.field.path", "user"),
+		attribute.String("graphql.field.type", "Query"),
+	}, user.Attributes())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("graphql.field.name", "friends"),
+		attribute.String("graphql.field.path", "user.friends"),
+		attribute.String("graphql.field.type", "User"),
+	}, friends.Attributes())
+}
+
+func TestFieldSpansList(t *testing.T) {
+	sr, h := fixtures(t, splunkgqlgen.WithFieldSpans(true))
+
+	do(t, h, "query { users { friends { id } } }", nil)
+
+	var paths []string
+	for _, s := range sr.Ended() {
+		if s.Name() != "User.friends" {
+			continue
+		}
+		for _, a := range s.Attributes() {
+			if a.Key == "graphql.field.path" {
+				paths = append(paths, a.Value.AsString())
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"users[0].friends", "users[1].friends", "users[2].friends"}, paths)
+}
+
+func TestFieldSpansAlias(t *testing.T) {
+	sr, h := fixtures(t, splunkgqlgen.WithFieldSpans(true))
+
+	do(t, h, "{ first: user(id: 1) { name } }", nil)
+
+	user := spansByName(t, sr)["Query.user"]
+	require.NotNil(t, user)
+	assert.Contains(t, user.Attributes(), attribute.String("graphql.field.alias", "first"))
+	assert.Contains(t, user.Attributes(), attribute.String("graphql.field.path", "first"))
+}
+
+func TestErrors(t *testing.T) {
+	sr, h := fixtures(t, splunkgqlgen.WithFieldSpans(true))
+
+	got := do(t, h, "query Missing { a: user(id: 4) { name } b: user(id: 5) { name } }", nil)
+	assert.Contains(t, got, "user not found")
+
+	var op trace.ReadOnlySpan
+	var fields []trace.ReadOnlySpan
+	for _, s := range sr.Ended() {
+		if s.Name() == "query Missing" {
+			op = s
+		} else {
+			fields = append(fields, s)
+		}
+	}
+	require.NotNil(t, op)
+	require.Len(t, fields, 2)
+
+	assert.Equal(t, codes.Error, op.Status().Code)
+	assert.Equal(t, "user not found (and 1 more errors)", op.Status().Description)
+	require.Len(t, op.Events(), 2)
+	for _, e := range op.Events() {
+		assert.Equal(t, "exception", e.Name)
+	}
+
+	for _, s := range fields {
+		assertChildOf(t, op, s)
+		assert.Equal(t, codes.Error, s.Status().Code)
+		assert.Equal(t, "user not found", s.Status().Description)
+		require.Len(t, s.Events(), 1)
+		assert.Equal(t, "exception", s.Events()[0].Name)
+	}
+}
+
+func TestWithAttributes(t *testing.T) {
+	attr := attribute.String("key", "value")
+	sr, h := fixtures(t, splunkgqlgen.WithAttributes([]attribute.KeyValue{attr}), splunkgqlgen.WithFieldSpans(true))
+
+	do(t, h, getUser, map[string]interface{}{"id": 1})
+
+	spans := sr.Ended()
+	require.NotEmpty(t, spans)
+	for _, s := range spans {
+		assert.Contains(t, s.Attributes(), attr, s.Name())
+	}
+}
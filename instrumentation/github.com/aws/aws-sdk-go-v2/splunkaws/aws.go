@@ -0,0 +1,43 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkaws
+
+import (
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// AppendMiddlewares appends the otelaws tracing middlewares to apiOptions
+// (e.g. the APIOptions of an aws.Config). Spans are created for every API
+// call with the rpc.system, rpc.service, rpc.method, and aws.request_id
+// attributes.
+//
+// In addition to the global TextMapPropagator, the span context is propagated
+// in the X-Amzn-Trace-Id header so that services connected to AWS X-Ray can
+// correlate the calls. The otelaws.WithTextMapPropagator option can be passed
+// with WithOTelOpts to override this.
+func AppendMiddlewares(apiOptions *[]func(*middleware.Stack) error, opts ...Option) {
+	cfg := newConfig(opts...)
+	otelOpts := append([]otelaws.Option{
+		otelaws.WithTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+			otel.GetTextMapPropagator(),
+			xray.Propagator{},
+		)),
+	}, cfg.OTelOpts...)
+	otelaws.AppendMiddlewares(apiOptions, otelOpts...)
+}
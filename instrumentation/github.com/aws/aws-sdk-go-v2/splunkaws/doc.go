@@ -0,0 +1,19 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunkaws provides functions to trace the
+// github.com/aws/aws-sdk-go-v2 package. It wraps the
+// go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws
+// instrumentation with Splunk specific defaults.
+package splunkaws // import "github.com/signalfx/splunk-otel-go/instrumentation/github.com/aws/aws-sdk-go-v2/splunkaws"
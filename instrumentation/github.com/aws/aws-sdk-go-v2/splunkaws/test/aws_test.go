@@ -0,0 +1,102 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceapi "go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/aws/aws-sdk-go-v2/splunkaws"
+)
+
+// stubClient is an aws.HTTPClient returning a successful response.
+type stubClient struct {
+	req *http.Request
+}
+
+func (c *stubClient) Do(req *http.Request) (*http.Response, error) {
+	c.req = req
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type":     []string{"application/x-amz-json-1.0"},
+			"X-Amzn-Requestid": []string{"request-id"},
+		},
+		Body:    io.NopCloser(strings.NewReader(`{"TableNames":[]}`)),
+		Request: req,
+	}, nil
+}
+
+func listTables(t *testing.T, opts ...splunkaws.Option) (*stubClient, []trace.ReadOnlySpan) {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	client := &stubClient{}
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  client,
+	}
+	opts = append([]splunkaws.Option{splunkaws.WithOTelOpts(otelaws.WithTracerProvider(tp))}, opts...)
+	splunkaws.AppendMiddlewares(&cfg.APIOptions, opts...)
+
+	_, err := dynamodb.NewFromConfig(cfg).ListTables(context.Background(), &dynamodb.ListTablesInput{})
+	require.NoError(t, err)
+	return client, sr.Ended()
+}
+
+func TestAppendMiddlewares(t *testing.T) {
+	client, spans := listTables(t)
+
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "DynamoDB.ListTables", span.Name())
+	assert.Equal(t, traceapi.SpanKindClient, span.SpanKind())
+	assert.Subset(t, span.Attributes(), []attribute.KeyValue{
+		attribute.String("rpc.system", "aws-api"),
+		attribute.String("rpc.service", "DynamoDB"),
+		attribute.String("rpc.method", "ListTables"),
+		attribute.String("aws.request_id", "request-id"),
+	})
+
+	require.NotNil(t, client.req)
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	want := "Root=1-" + traceID[:8] + "-" + traceID[8:] + ";Parent=" + spanID + ";Sampled=1"
+	assert.Equal(t, want, client.req.Header.Get("X-Amzn-Trace-Id"))
+}
+
+func TestAppendMiddlewaresPropagatorOverride(t *testing.T) {
+	client, spans := listTables(t, splunkaws.WithOTelOpts(otelaws.WithTextMapPropagator(propagation.TraceContext{})))
+
+	require.Len(t, spans, 1)
+	require.NotNil(t, client.req)
+	assert.Empty(t, client.req.Header.Get("X-Amzn-Trace-Id"))
+	assert.NotEmpty(t, client.req.Header.Get("traceparent"))
+}
@@ -0,0 +1,110 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkgin
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
+)
+
+// instrumentationName is the instrumentation library identifier for a Tracer.
+const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/github.com/gin-gonic/gin/splunkgin"
+
+// envVarTraceResponseHeaderEnabled is the environment variable disabling the
+// Server-Timing header when set to "false", as for the splunkhttp package.
+const envVarTraceResponseHeaderEnabled = "SPLUNK_TRACE_RESPONSE_HEADER_ENABLED"
+
+type config struct {
+	*internal.Config
+
+	traceResponseHeaderEnabled bool
+	filters                    []func(*http.Request) bool
+}
+
+func newConfig(options ...Option) *config {
+	c := config{
+		Config: internal.NewConfig(instrumentationName, internal.OptionFunc(
+			func(c *internal.Config) {
+				c.Version = Version()
+				c.DefaultStartOpts = append(c.DefaultStartOpts, trace.WithSpanKind(trace.SpanKindServer))
+			}),
+		),
+		traceResponseHeaderEnabled: !strings.EqualFold(os.Getenv(envVarTraceResponseHeaderEnabled), "false"),
+	}
+
+	for _, o := range options {
+		if o != nil {
+			o.apply(&c)
+		}
+	}
+
+	return &c
+}
+
+// Option applies options to a configuration.
+type Option interface {
+	apply(*config)
+}
+
+type optionConv struct {
+	iOpt internal.Option
+}
+
+func (o optionConv) apply(c *config) {
+	o.iOpt.Apply(c.Config)
+}
+
+// WithTracerProvider returns an Option that sets the TracerProvider used with
+// this instrumentation library.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionConv{iOpt: internal.WithTracerProvider(tp)}
+}
+
+// WithAttributes returns an Option that appends attr to the attributes set
+// for every span created with this instrumentation library.
+func WithAttributes(attr []attribute.KeyValue) Option {
+	return optionConv{iOpt: internal.WithAttributes(attr)}
+}
+
+// WithPropagator returns an Option that sets p as the TextMapPropagator used
+// when propagating a span context.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return optionConv{iOpt: internal.WithPropagator(p)}
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithFilter returns an Option that excludes the requests for which f returns
+// false from the instrumentation (e.g. health checks). Multiple filters can be
+// used, a request is traced only if all of them return true. A nil filter is
+// ignored.
+func WithFilter(f func(*http.Request) bool) Option {
+	return optionFunc(func(c *config) {
+		if f != nil {
+			c.filters = append(c.filters, f)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkgin_test
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/gin-gonic/gin/splunkgin"
+)
+
+func Example() {
+	router := gin.New()
+	router.Use(splunkgin.Middleware(splunkgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/healthz"
+	})))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "Hello %s!\n", c.Param("id"))
+	})
+	if err := router.Run(":8080"); err != nil {
+		panic(err)
+	}
+}
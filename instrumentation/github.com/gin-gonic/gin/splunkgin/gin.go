@@ -0,0 +1,95 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunkgin provides OpenTelemetry instrumentation for the
+// github.com/gin-gonic/gin package.
+package splunkgin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware returns github.com/gin-gonic/gin middleware that traces served
+// requests.
+//
+// The span of each request is named after the request method and the route
+// matched by the gin engine (e.g. "HTTP GET /users/:id"), which is also
+// recorded as the http.route attribute. The request of the gin.Context has
+// the span in its context. The errors attached to the gin.Context by the
+// handlers are recorded on the span.
+//
+// As for the splunkhttp package, the trace context is added to the
+// Server-Timing response header for the trace linkage with Splunk RUM,
+// unless the SPLUNK_TRACE_RESPONSE_HEADER_ENABLED environment variable is set
+// to "false".
+func Middleware(options ...Option) gin.HandlerFunc {
+	cfg := newConfig(options...)
+
+	return func(c *gin.Context) {
+		r := c.Request
+		for _, f := range cfg.filters {
+			if !f(r) {
+				c.Next()
+				return
+			}
+		}
+
+		tracer := cfg.ResolveTracer(r.Context())
+		carrier := propagation.HeaderCarrier(r.Header)
+		ctx := cfg.Propagator.Extract(r.Context(), carrier)
+		// The route is matched by the engine before the middleware is
+		// called.
+		name := "HTTP " + r.Method
+		attrs := httpconv.ServerRequest("", r)
+		if route := c.FullPath(); route != "" {
+			name += " " + route
+			attrs = append(attrs, semconv.HTTPRouteKey.String(route))
+		}
+		opts := cfg.MergedSpanStartOptions(trace.WithAttributes(attrs...))
+		ctx, span := tracer.Start(ctx, name, opts...)
+		defer span.End()
+		c.Request = r.WithContext(ctx)
+
+		if cfg.traceResponseHeaderEnabled {
+			addServerTimingHeader(c.Writer.Header(), span.SpanContext())
+		}
+
+		c.Next()
+
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+		status := c.Writer.Status()
+		if status > 0 {
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		}
+		span.SetStatus(httpconv.ServerStatus(status))
+	}
+}
+
+// addServerTimingHeader adds the trace context to the Server-Timing header in
+// the format used by the splunkhttp package.
+func addServerTimingHeader(h http.Header, sc trace.SpanContext) {
+	if !sc.IsValid() {
+		return
+	}
+	h.Add("Access-Control-Expose-Headers", "Server-Timing")
+	h.Add("Server-Timing", "traceparent;desc=\"00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01\"")
+}
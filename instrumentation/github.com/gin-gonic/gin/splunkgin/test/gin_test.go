@@ -0,0 +1,198 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package test provides end-to-end testing of the splunkgin instrumentation with
+the default SDK.
+
+This package is in a separate module from the instrumentation it tests to
+isolate the dependency of the default SDK and not impose this as a transitive
+dependency for users.
+*/
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/gin-gonic/gin/splunkgin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func newFixtures(t *testing.T, opts ...splunkgin.Option) (*tracetest.SpanRecorder, *gin.Engine) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	router := gin.New()
+	router.Use(splunkgin.Middleware(append([]splunkgin.Option{splunkgin.WithTracerProvider(tp)}, opts...)...))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Param("id"))
+	})
+	router.GET("/error", func(c *gin.Context) {
+		_ = c.Error(errors.New("boom"))
+		c.Status(http.StatusInternalServerError)
+	})
+	router.GET("/healthz", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return sr, router
+}
+
+func TestMiddleware(t *testing.T) {
+	sr, router := newFixtures(t)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assert.Equal(t, "HTTP GET /users/:id", span.Name())
+	assert.Equal(t, traceapi.SpanKindServer, span.SpanKind())
+	assert.Equal(t, splunkgin.Version(), span.InstrumentationLibrary().Version)
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	attrs := span.Attributes()
+	assert.Contains(t, attrs, semconv.HTTPMethodKey.String(http.MethodGet))
+	assert.Contains(t, attrs, semconv.HTTPRouteKey.String("/users/:id"))
+	assert.Contains(t, attrs, semconv.HTTPStatusCodeKey.Int(http.StatusOK))
+
+	sc := span.SpanContext()
+	assert.Equal(t, "traceparent;desc=\"00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01\"", w.Header().Get("Server-Timing"))
+	assert.Contains(t, w.Header().Values("Access-Control-Expose-Headers"), "Server-Timing")
+}
+
+func TestMiddlewareConcurrent(t *testing.T) {
+	sr, router := newFixtures(t,
+		splunkgin.WithAttributes([]attribute.KeyValue{attribute.String("a", "1")}),
+		splunkgin.WithAttributes([]attribute.KeyValue{attribute.String("b", "2")}),
+	)
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "/users/"+strconv.Itoa(i), http.NoBody)
+			// The peer port identifies the request in the span attributes.
+			req.RemoteAddr = "192.0.2.1:" + strconv.Itoa(1000+i)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}(i)
+	}
+	wg.Wait()
+
+	spans := sr.Ended()
+	require.Len(t, spans, n)
+	ports := make(map[int64]struct{}, n)
+	for _, span := range spans {
+		var count int
+		for _, a := range span.Attributes() {
+			if a.Key == semconv.NetSockPeerPortKey {
+				count++
+				ports[a.Value.AsInt64()] = struct{}{}
+			}
+		}
+		assert.Equal(t, 1, count, "each span should have its own request attributes")
+		assert.Contains(t, span.Attributes(), attribute.String("a", "1"))
+		assert.Contains(t, span.Attributes(), attribute.String("b", "2"))
+	}
+	assert.Len(t, ports, n, "requests should not share attributes")
+}
+
+func TestMiddlewareTraceResponseHeaderDisabled(t *testing.T) {
+	t.Setenv("SPLUNK_TRACE_RESPONSE_HEADER_ENABLED", "false")
+
+	sr, router := newFixtures(t)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	assert.Empty(t, w.Header().Get("Server-Timing"))
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	sr, router := newFixtures(t)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/error", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusInternalServerError))
+	events := span.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, semconv.ExceptionEventName, events[0].Name)
+	assert.Contains(t, events[0].Attributes, semconv.ExceptionMessageKey.String("boom"))
+}
+
+func TestMiddlewareContext(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	var got traceapi.SpanContext
+	router := gin.New()
+	router.Use(splunkgin.Middleware(splunkgin.WithTracerProvider(tp)))
+	router.GET("/", func(c *gin.Context) {
+		got = traceapi.SpanContextFromContext(c.Request.Context())
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, sr.Ended()[0].SpanContext(), got)
+}
+
+func TestMiddlewareWithFilter(t *testing.T) {
+	sr, router := newFixtures(t,
+		splunkgin.WithFilter(func(r *http.Request) bool { return r.URL.Path != "/healthz" }),
+		splunkgin.WithFilter(nil),
+	)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, sr.Ended(), "filtered request must not be traced")
+	assert.Empty(t, w.Header().Get("Server-Timing"))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", http.NoBody))
+	assert.Len(t, sr.Ended(), 1)
+}
+
+func TestMiddlewareNotFound(t *testing.T) {
+	sr, router := newFixtures(t)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assert.Equal(t, "HTTP GET", span.Name())
+	assert.Contains(t, span.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusNotFound))
+}
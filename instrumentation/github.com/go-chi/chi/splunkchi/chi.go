@@ -65,7 +65,7 @@ func Middleware(options ...Option) func(http.Handler) http.Handler {
 
 			next.ServeHTTP(ww, r)
 
-			path := chi.RouteContext(r.Context()).RoutePattern()
+			path := RoutePattern(r)
 			if path != "" {
 				span.SetAttributes(semconv.HTTPRouteKey.String(path))
 				span.SetName(name + " " + path)
@@ -79,3 +79,17 @@ func Middleware(options ...Option) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// RoutePattern returns the route pattern (e.g. "/users/{id}") matched by the
+// github.com/go-chi/chi router for r. An empty string is returned if r is not
+// routed by a chi router or no route has been matched yet.
+//
+// It can be used with the WithRouteFunc option of
+// github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp.
+func RoutePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
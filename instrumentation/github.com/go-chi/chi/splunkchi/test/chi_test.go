@@ -139,3 +139,16 @@ func assertSpan(path string, otelCode codes.Code, httpCode int) func(*testing.T,
 		}
 	}
 }
+
+func TestRoutePattern(t *testing.T) {
+	var got string
+	r := chi.NewRouter()
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		got = splunkchi.RoutePattern(r)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", http.NoBody))
+	assert.Equal(t, "/users/{id}", got)
+
+	assert.Equal(t, "", splunkchi.RoutePattern(httptest.NewRequest(http.MethodGet, "/users/42", http.NoBody)), "should handle not routed requests")
+}
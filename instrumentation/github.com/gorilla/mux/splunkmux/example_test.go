@@ -0,0 +1,41 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkmux_test
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/gorilla/mux/splunkmux"
+	"github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp"
+)
+
+func Example() {
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		// Set the http.route attribute of the server span.
+		return splunkhttp.NewHandler(next, splunkhttp.WithRouteFunc(splunkmux.RoutePattern))
+	})
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Hello World!"))
+	})
+
+	handler := otelhttp.NewHandler(router, "my-service")
+	if err := http.ListenAndServe(":8080", handler); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunkmux provides helpers to instrument the github.com/gorilla/mux
+// package.
+package splunkmux
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RoutePattern returns the path template (e.g. "/users/{id}") of the route
+// matched by the github.com/gorilla/mux router for r. An empty string is
+// returned if r is not routed by a mux router.
+//
+// It can be used with the WithRouteFunc option of
+// github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp when
+// the splunkhttp handler is used as middleware of the router.
+func RoutePattern(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return tmpl
+}
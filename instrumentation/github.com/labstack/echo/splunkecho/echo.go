@@ -0,0 +1,95 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunkecho provides OpenTelemetry instrumentation for the
+// github.com/labstack/echo/v4 package.
+package splunkecho
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
+)
+
+// instrumentationName is the instrumentation library identifier for a Tracer.
+const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/github.com/labstack/echo/splunkecho"
+
+// Middleware returns github.com/labstack/echo/v4 middleware that traces
+// served requests.
+//
+// The span of each request is named after the request method and the route
+// matched by the echo router (e.g. "HTTP GET /users/:id"), which is also
+// recorded as the http.route attribute. The request passed to the next
+// handler has the span in its context. The errors returned by the next
+// handler are recorded on the span and passed to the HTTP error handler of
+// echo to write the response before the span ends. They are still returned
+// to the previous middleware.
+func Middleware(options ...Option) echo.MiddlewareFunc {
+	o := append([]internal.Option{
+		internal.OptionFunc(func(c *internal.Config) {
+			c.Version = Version()
+			c.DefaultStartOpts = append(c.DefaultStartOpts, trace.WithSpanKind(trace.SpanKindServer))
+		}),
+	}, localToInternal(options)...)
+
+	cfg := internal.NewConfig(instrumentationName, o...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			tracer := cfg.ResolveTracer(r.Context())
+			carrier := propagation.HeaderCarrier(r.Header)
+			ctx := cfg.Propagator.Extract(r.Context(), carrier)
+			// The route is matched before the middleware registered with
+			// Echo.Use is called, but after the one registered with
+			// Echo.Pre. Name the span again once the request is handled for
+			// the latter.
+			name := "HTTP " + r.Method
+			attrs := httpconv.ServerRequest("", r)
+			if path := c.Path(); path != "" {
+				attrs = append(attrs, semconv.HTTPRouteKey.String(path))
+				name += " " + path
+			}
+			opts := cfg.MergedSpanStartOptions(trace.WithAttributes(attrs...))
+			ctx, span := tracer.Start(ctx, name, opts...)
+			defer span.End()
+			c.SetRequest(r.WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				span.RecordError(err)
+				// Write the response for the status to be known.
+				c.Error(err)
+			}
+
+			if path := c.Path(); path != "" {
+				span.SetAttributes(semconv.HTTPRouteKey.String(path))
+				span.SetName("HTTP " + r.Method + " " + path)
+			}
+
+			status := c.Response().Status
+			if status > 0 {
+				span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+			}
+			span.SetStatus(httpconv.ServerStatus(status))
+			// The response is committed, the error handler called again for
+			// the error by echo does not write it twice.
+			return err
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkecho_test
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/labstack/echo/splunkecho"
+)
+
+func Example() {
+	e := echo.New()
+	e.Use(splunkecho.Middleware())
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, "Hello "+c.Param("id")+"!\n")
+	})
+	if err := e.Start(":8080"); err != nil {
+		panic(err)
+	}
+}
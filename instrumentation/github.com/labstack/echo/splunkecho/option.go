@@ -0,0 +1,63 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkecho
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
+)
+
+func localToInternal(opts []Option) []internal.Option {
+	out := make([]internal.Option, len(opts))
+	for i, o := range opts {
+		o := o // Each closure has to apply its own option.
+		out[i] = internal.OptionFunc(func(c *internal.Config) { o.apply(c) })
+	}
+	return out
+}
+
+// Option applies options to a configuration.
+type Option interface {
+	apply(*internal.Config)
+}
+
+type optionConv struct {
+	iOpt internal.Option
+}
+
+func (o optionConv) apply(c *internal.Config) {
+	o.iOpt.Apply(c)
+}
+
+// WithTracerProvider returns an Option that sets the TracerProvider used for
+// a configuration.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionConv{iOpt: internal.WithTracerProvider(tp)}
+}
+
+// WithAttributes returns an Option that appends attr to the attributes set
+// for every span created.
+func WithAttributes(attr []attribute.KeyValue) Option {
+	return optionConv{iOpt: internal.WithAttributes(attr)}
+}
+
+// WithPropagator returns an Option that sets p as the TextMapPropagator used
+// when propagating a span context.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return optionConv{iOpt: internal.WithPropagator(p)}
+}
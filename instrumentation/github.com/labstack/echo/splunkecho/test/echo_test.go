@@ -0,0 +1,254 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package test provides end-to-end testing of the splunkecho instrumentation
+with the default SDK.
+
+This package is in a separate module from the instrumentation it tests to
+isolate the dependency of the default SDK and not impose this as a transitive
+dependency for users.
+*/
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/labstack/echo/splunkecho"
+)
+
+var errBoom = errors.New("boom")
+
+func newTestServer(tp *trace.TracerProvider) *echo.Echo {
+	e := echo.New()
+	e.Use(splunkecho.Middleware(splunkecho.WithTracerProvider(tp)))
+	users := e.Group("/users")
+	users.GET("/:user", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	users.PUT("/:user", func(c echo.Context) error {
+		return c.NoContent(http.StatusAccepted)
+	})
+	e.GET("/error", func(c echo.Context) error {
+		return errBoom
+	})
+	e.GET("/teapot", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot)
+	})
+	return e
+}
+
+func newFixtures(t *testing.T) (*tracetest.SpanRecorder, *echo.Echo) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+	return sr, newTestServer(tp)
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		method    string
+		target    string
+		assertion func(*testing.T, string, string, trace.ReadOnlySpan)
+	}{
+		{
+			method:    http.MethodGet,
+			target:    "/users/bob",
+			assertion: assertSpan("/users/:user", codes.Unset, http.StatusOK),
+		},
+		{
+			method:    http.MethodPut,
+			target:    "/users/bob",
+			assertion: assertSpan("/users/:user", codes.Unset, http.StatusAccepted),
+		},
+		{
+			method:    http.MethodGet,
+			target:    "/error",
+			assertion: assertSpan("/error", codes.Error, http.StatusInternalServerError),
+		},
+		{
+			method:    http.MethodGet,
+			target:    "/teapot",
+			assertion: assertSpan("/teapot", codes.Unset, http.StatusTeapot),
+		},
+	}
+
+	for _, test := range tests {
+		sr, e := newFixtures(t)
+		req := httptest.NewRequest(test.method, test.target, http.NoBody)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		require.Len(t, sr.Ended(), 1)
+		test.assertion(t, test.method, test.target, sr.Ended()[0])
+	}
+}
+
+func assertSpan(path string, otelCode codes.Code, httpCode int) func(*testing.T, string, string, trace.ReadOnlySpan) {
+	return func(t *testing.T, method, target string, span trace.ReadOnlySpan) {
+		name := "HTTP " + method
+		if path != "" {
+			name += " " + path
+		}
+		assert.Equal(t, name, span.Name())
+		assert.Equal(t, traceapi.SpanKindServer, span.SpanKind())
+		assert.Equal(t, splunkecho.Version(), span.InstrumentationLibrary().Version)
+
+		status := span.Status()
+		assert.Equal(t, otelCode, status.Code)
+		assert.Equal(t, "", status.Description)
+
+		attrs := span.Attributes()
+		assert.Contains(t, attrs, semconv.HTTPMethodKey.String(method))
+		assert.Contains(t, attrs, semconv.HTTPRouteKey.String(path))
+		assert.Contains(t, attrs, semconv.HTTPSchemeHTTP)
+		assert.Contains(t, attrs, semconv.HTTPStatusCodeKey.Int(httpCode))
+		assert.Contains(t, attrs, semconv.HTTPFlavorHTTP11)
+
+		keys := make(map[attribute.Key]struct{}, len(attrs))
+		for _, a := range attrs {
+			keys[a.Key] = struct{}{}
+		}
+
+		// These key values are potentially dynamic. Test an attribute
+		// with this key is set regardless of its value.
+		wantKeys := []attribute.Key{
+			semconv.NetHostNameKey,
+			semconv.NetSockPeerAddrKey,
+			semconv.NetSockPeerPortKey,
+		}
+		for _, k := range wantKeys {
+			assert.Contains(t, keys, k)
+		}
+	}
+}
+
+func TestMiddlewareConcurrent(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+	e := echo.New()
+	e.Use(splunkecho.Middleware(
+		splunkecho.WithTracerProvider(tp),
+		splunkecho.WithAttributes([]attribute.KeyValue{attribute.String("a", "1")}),
+		splunkecho.WithAttributes([]attribute.KeyValue{attribute.String("b", "2")}),
+	))
+	e.GET("/users/:user", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "/users/"+strconv.Itoa(i), http.NoBody)
+			// The peer port identifies the request in the span attributes.
+			req.RemoteAddr = "192.0.2.1:" + strconv.Itoa(1000+i)
+			e.ServeHTTP(httptest.NewRecorder(), req)
+		}(i)
+	}
+	wg.Wait()
+
+	spans := sr.Ended()
+	require.Len(t, spans, n)
+	ports := make(map[int64]struct{}, n)
+	for _, span := range spans {
+		var count int
+		for _, a := range span.Attributes() {
+			if a.Key == semconv.NetSockPeerPortKey {
+				count++
+				ports[a.Value.AsInt64()] = struct{}{}
+			}
+		}
+		assert.Equal(t, 1, count, "each span should have its own request attributes")
+		assert.Contains(t, span.Attributes(), attribute.String("a", "1"))
+		assert.Contains(t, span.Attributes(), attribute.String("b", "2"))
+	}
+	assert.Len(t, ports, n, "requests should not share attributes")
+}
+
+func TestMiddlewareRecordsError(t *testing.T) {
+	sr, e := newFixtures(t)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/error", http.NoBody))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "error handler must write the response")
+	require.Len(t, sr.Ended(), 1)
+	events := sr.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, semconv.ExceptionEventName, events[0].Name)
+	assert.Contains(t, events[0].Attributes, semconv.ExceptionMessageKey.String("boom"))
+}
+
+func TestMiddlewarePropagatesContext(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	var got traceapi.SpanContext
+	e := echo.New()
+	e.Use(splunkecho.Middleware(splunkecho.WithTracerProvider(tp)))
+	e.GET("/", func(c echo.Context) error {
+		got = traceapi.SpanContextFromContext(c.Request().Context())
+		return nil
+	})
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, sr.Ended()[0].SpanContext(), got)
+}
+
+func TestMiddlewarePre(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+	// The route is not matched yet when the middleware is called.
+	e := echo.New()
+	e.Pre(splunkecho.Middleware(splunkecho.WithTracerProvider(tp)))
+	e.GET("/users/:user", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/bob", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	assertSpan("/users/:user", codes.Unset, http.StatusOK)(t, http.MethodGet, "/users/bob", sr.Ended()[0])
+}
+
+func TestMiddlewareNotFound(t *testing.T) {
+	sr, e := newFixtures(t)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assert.Equal(t, "HTTP GET", span.Name())
+	assert.Contains(t, span.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusNotFound))
+}
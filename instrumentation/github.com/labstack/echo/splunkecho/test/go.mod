@@ -0,0 +1,36 @@
+module github.com/signalfx/splunk-otel-go/instrumentation/github.com/labstack/echo/splunkecho/test
+
+go 1.19
+
+require (
+	github.com/labstack/echo/v4 v4.11.1
+	github.com/signalfx/splunk-otel-go/instrumentation/github.com/labstack/echo/splunkecho v1.7.0
+	github.com/stretchr/testify v1.8.4
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/sdk v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/labstack/gommon v0.4.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.19 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.7.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+	golang.org/x/crypto v0.11.0 // indirect
+	golang.org/x/net v0.12.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/signalfx/splunk-otel-go/instrumentation/github.com/labstack/echo/splunkecho => ../
+	github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../../internal/
+)
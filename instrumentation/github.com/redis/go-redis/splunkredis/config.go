@@ -0,0 +1,98 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkredis
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
+)
+
+// instrumentationName is the instrumentation library identifier for a Tracer.
+const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/github.com/redis/go-redis/splunkredis"
+
+// config contains configuration options.
+type config struct {
+	*internal.Config
+
+	ArgsCaptured bool
+}
+
+func newConfig(options ...Option) config {
+	c := config{
+		Config: internal.NewConfig(instrumentationName,
+			internal.OptionFunc(
+				func(c *internal.Config) {
+					c.Version = Version()
+					c.DefaultStartOpts = []trace.SpanStartOption{
+						// From the specification: span kind MUST always be CLIENT.
+						trace.WithSpanKind(trace.SpanKindClient),
+						trace.WithAttributes(semconv.DBSystemRedis),
+					}
+				}),
+		),
+	}
+
+	for _, o := range options {
+		if o != nil {
+			o.apply(&c)
+		}
+	}
+
+	return c
+}
+
+// Option applies options to a tracing configuration.
+type Option interface {
+	apply(*config)
+}
+
+type optionConv struct {
+	iOpt internal.Option
+}
+
+func (o optionConv) apply(c *config) {
+	o.iOpt.Apply(c.Config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithTracerProvider returns an Option that sets the TracerProvider used with
+// this instrumentation library.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionConv{iOpt: internal.WithTracerProvider(tp)}
+}
+
+// WithAttributes returns an Option that appends attr to the attributes set
+// for every span created with this instrumentation library.
+func WithAttributes(attr []attribute.KeyValue) Option {
+	return optionConv{iOpt: internal.WithAttributes(attr)}
+}
+
+// WithArgsCaptured returns an Option that sets if the command arguments are
+// included in the db.statement attribute. The arguments contain the keys and
+// values of the commands, therefore only the command name is recorded by
+// default. This is meant to be used for debugging.
+func WithArgsCaptured(captured bool) Option {
+	return optionFunc(func(c *config) {
+		c.ArgsCaptured = captured
+	})
+}
@@ -0,0 +1,33 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkredis_test
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/redis/go-redis/splunkredis"
+)
+
+func ExampleInstrumentClient() {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer client.Close()
+	splunkredis.InstrumentClient(client)
+
+	if err := client.Set(context.Background(), "key", "value", 0).Err(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,24 @@
+module github.com/signalfx/splunk-otel-go/instrumentation/github.com/redis/go-redis/splunkredis
+
+go 1.19
+
+require (
+	github.com/redis/go-redis/v9 v9.0.5
+	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.7.0
+	github.com/stretchr/testify v1.8.4
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../internal
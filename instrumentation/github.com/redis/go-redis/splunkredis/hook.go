@@ -0,0 +1,147 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/netconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentClient adds a hook to client that traces all the commands it
+// sends. A span is created for every command, and a span with a child span
+// for every queued command is created for every pipeline.
+func InstrumentClient(client redis.UniversalClient, opts ...Option) {
+	cfg := newConfig(opts...)
+	if c, ok := client.(*redis.Client); ok {
+		// Only a single server client has a known peer.
+		o := c.Options()
+		cfg.DefaultStartOpts = append(
+			cfg.DefaultStartOpts,
+			trace.WithAttributes(netAttributes(o.Network, o.Addr)...),
+			trace.WithAttributes(semconv.DBRedisDBIndexKey.Int(o.DB)),
+		)
+	}
+	client.AddHook(&hook{cfg: cfg})
+}
+
+type hook struct {
+	cfg config
+}
+
+var _ redis.Hook = (*hook)(nil)
+
+func (h *hook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.start(ctx, cmd.FullName(), h.attrs(cmd)...)
+		err := next(ctx, cmd)
+		end(span, err)
+		return err
+	}
+}
+
+func (h *hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		name := "pipeline"
+		if len(cmds) > 0 && cmds[0].Name() == "multi" {
+			// The commands are wrapped in a MULTI/EXEC transaction.
+			name = "multi"
+		}
+		ctx, span := h.start(ctx, name)
+
+		spans := make([]trace.Span, len(cmds))
+		for i, cmd := range cmds {
+			_, spans[i] = h.start(ctx, cmd.FullName(), h.attrs(cmd)...)
+		}
+
+		err := next(ctx, cmds)
+
+		for i, cmd := range cmds {
+			end(spans[i], cmd.Err())
+		}
+		end(span, err)
+		return err
+	}
+}
+
+func (h *hook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	opts := h.cfg.MergedSpanStartOptions(trace.WithAttributes(attrs...))
+	return h.cfg.ResolveTracer(ctx).Start(ctx, name, opts...)
+}
+
+// attrs returns the attributes of cmd.
+func (h *hook) attrs(cmd redis.Cmder) []attribute.KeyValue {
+	stmt := cmd.FullName()
+	if h.cfg.ArgsCaptured {
+		args := make([]string, len(cmd.Args()))
+		for i, arg := range cmd.Args() {
+			args[i] = fmt.Sprint(arg)
+		}
+		stmt = strings.Join(args, " ")
+	}
+	return []attribute.KeyValue{
+		semconv.DBOperationKey.String(cmd.Name()),
+		semconv.DBStatementKey.String(stmt),
+	}
+}
+
+// end ends span recording err. The redis.Nil reply is not an error.
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// netAttributes returns the network attributes of a connection to address.
+func netAttributes(network, address string) []attribute.KeyValue {
+	if network == "" {
+		network = "tcp"
+	}
+	attrs := []attribute.KeyValue{netconv.Transport(network)}
+
+	host, p, err := net.SplitHostPort(address)
+	if err != nil {
+		host, p = address, ""
+	}
+	port, _ := strconv.Atoi(p)
+	if ip := net.ParseIP(host); ip != nil {
+		attrs = append(attrs, semconv.NetSockPeerAddrKey.String(ip.String()))
+		if port != 0 {
+			attrs = append(attrs, semconv.NetSockPeerPortKey.Int(port))
+		}
+	} else if host != "" {
+		attrs = append(attrs, semconv.NetPeerNameKey.String(host))
+		if port != 0 {
+			attrs = append(attrs, semconv.NetPeerPortKey.Int(port))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,63 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkredis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+func TestNetAttributes(t *testing.T) {
+	testcases := []struct {
+		network string
+		address string
+		want    []attribute.KeyValue
+	}{
+		{
+			address: "localhost:6379",
+			want: []attribute.KeyValue{
+				semconv.NetTransportTCP,
+				semconv.NetPeerNameKey.String("localhost"),
+				semconv.NetPeerPortKey.Int(6379),
+			},
+		},
+		{
+			network: "tcp",
+			address: "[::1]:6380",
+			want: []attribute.KeyValue{
+				semconv.NetTransportTCP,
+				semconv.NetSockPeerAddrKey.String("::1"),
+				semconv.NetSockPeerPortKey.Int(6380),
+			},
+		},
+		{
+			network: "unix",
+			address: "/tmp/redis.sock",
+			want: []attribute.KeyValue{
+				semconv.NetTransportInProc,
+				semconv.NetPeerNameKey.String("/tmp/redis.sock"),
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.address, func(t *testing.T) {
+			assert.Equal(t, tc.want, netAttributes(tc.network, tc.address))
+		})
+	}
+}
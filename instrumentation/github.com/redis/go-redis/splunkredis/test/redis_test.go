@@ -0,0 +1,168 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/redis/go-redis/splunkredis"
+)
+
+func newClient(t *testing.T, sr *tracetest.SpanRecorder, opts ...splunkredis.Option) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	t.Cleanup(func() { assert.NoError(t, client.Close()) })
+
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	splunkredis.InstrumentClient(client, append([]splunkredis.Option{splunkredis.WithTracerProvider(tp)}, opts...)...)
+	return client, s
+}
+
+// spans returns the ended spans, excluding the ones of the commands sent
+// when a connection is initialized.
+func spans(sr *tracetest.SpanRecorder) []trace.ReadOnlySpan {
+	var out []trace.ReadOnlySpan
+	for _, s := range sr.Ended() {
+		if s.Name() != "hello" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestGetSet(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	client, s := newClient(t, sr)
+	ctx := context.Background()
+
+	require.NoError(t, client.Set(ctx, "key", "value", 0).Err())
+	got, err := client.Get(ctx, "key").Result()
+	require.NoError(t, err)
+	assert.Equal(t, "value", got)
+
+	ended := spans(sr)
+	require.Len(t, ended, 2)
+	port, err := strconv.Atoi(s.Port())
+	require.NoError(t, err)
+	for i, name := range []string{"set", "get"} {
+		span := ended[i]
+		assert.Equal(t, name, span.Name())
+		assert.Equal(t, traceapi.SpanKindClient, span.SpanKind())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		assert.Subset(t, span.Attributes(), []attribute.KeyValue{
+			semconv.DBSystemRedis,
+			semconv.DBOperationKey.String(name),
+			// Only the command name is recorded by default.
+			semconv.DBStatementKey.String(name),
+			semconv.DBRedisDBIndexKey.Int(0),
+			semconv.NetSockPeerAddrKey.String(s.Host()),
+			semconv.NetSockPeerPortKey.Int(port),
+		})
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	client, _ := newClient(t, sr)
+
+	err := client.Get(context.Background(), "missing").Err()
+	require.ErrorIs(t, err, redis.Nil)
+
+	ended := spans(sr)
+	require.Len(t, ended, 1)
+	// A nil reply is not an error.
+	assert.Equal(t, codes.Unset, ended[0].Status().Code)
+}
+
+func TestArgsCaptured(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	client, _ := newClient(t, sr, splunkredis.WithArgsCaptured(true))
+
+	require.NoError(t, client.Set(context.Background(), "key", "value", 0).Err())
+
+	ended := spans(sr)
+	require.Len(t, ended, 1)
+	assert.Contains(t, ended[0].Attributes(), semconv.DBStatementKey.String("set key value"))
+}
+
+func TestTxPipeline(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	client, _ := newClient(t, sr)
+
+	_, err := client.TxPipelined(context.Background(), func(p redis.Pipeliner) error {
+		p.Set(context.Background(), "key", "value", 0)
+		p.Incr(context.Background(), "key")
+		return nil
+	})
+	require.Error(t, err, "INCR of a non-integer value")
+
+	ended := spans(sr)
+	require.Len(t, ended, 5)
+	parent := ended[len(ended)-1]
+	assert.Equal(t, "multi", parent.Name())
+	assert.Equal(t, codes.Error, parent.Status().Code)
+
+	want := map[string]codes.Code{
+		"multi": codes.Unset,
+		"set":   codes.Unset,
+		"incr":  codes.Error,
+		"exec":  codes.Unset,
+	}
+	for _, span := range ended[:len(ended)-1] {
+		code, ok := want[span.Name()]
+		require.Truef(t, ok, "unexpected span %q", span.Name())
+		assert.Equalf(t, code, span.Status().Code, "span %q", span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Contains(t, span.Attributes(), semconv.DBStatementKey.String(span.Name()))
+		delete(want, span.Name())
+	}
+	assert.Empty(t, want)
+}
+
+func TestPipeline(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	client, _ := newClient(t, sr)
+
+	_, err := client.Pipelined(context.Background(), func(p redis.Pipeliner) error {
+		p.Set(context.Background(), "key", "value", 0)
+		p.Get(context.Background(), "key")
+		return nil
+	})
+	require.NoError(t, err)
+
+	ended := spans(sr)
+	require.Len(t, ended, 3)
+	parent := ended[2]
+	assert.Equal(t, "pipeline", parent.Name())
+	assert.Equal(t, "set", ended[0].Name())
+	assert.Equal(t, "get", ended[1].Name())
+	for _, span := range ended[:2] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkkafka
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageCarrier(t *testing.T) {
+	msg := &kafka.Message{Headers: []kafka.Header{{Key: "foo", Value: []byte("bar")}}}
+	c := NewMessageCarrier(msg)
+
+	assert.Equal(t, "bar", c.Get("foo"))
+	assert.Equal(t, "", c.Get("baz"))
+
+	c.Set("baz", "qux")
+	c.Set("foo", "quux")
+	assert.Equal(t, "quux", c.Get("foo"))
+	assert.Equal(t, "qux", c.Get("baz"))
+	assert.ElementsMatch(t, []string{"foo", "baz"}, c.Keys())
+	assert.Len(t, msg.Headers, 2)
+}
@@ -0,0 +1,111 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkkafka
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
+)
+
+// instrumentationName is the instrumentation library identifier for a Tracer.
+const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/github.com/segmentio/kafka-go/splunkkafka"
+
+type config struct {
+	*internal.Config
+
+	producerLink bool
+}
+
+func newConfig(options ...Option) *config {
+	c := config{
+		Config: internal.NewConfig(instrumentationName, internal.OptionFunc(
+			func(c *internal.Config) {
+				c.Version = Version()
+				c.DefaultStartOpts = []trace.SpanStartOption{
+					trace.WithAttributes(semconv.MessagingSystemKey.String("kafka")),
+				}
+			}),
+		),
+	}
+
+	for _, o := range options {
+		if o != nil {
+			o.apply(&c)
+		}
+	}
+
+	return &c
+}
+
+// Option applies options to a configuration.
+type Option interface {
+	apply(*config)
+}
+
+type optionConv struct {
+	iOpt internal.Option
+}
+
+func (o optionConv) apply(c *config) {
+	o.iOpt.Apply(c.Config)
+}
+
+// WithTracerProvider returns an Option that sets the TracerProvider used for
+// a configuration.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionConv{iOpt: internal.WithTracerProvider(tp)}
+}
+
+// WithAttributes returns an Option that appends attr to the attributes set
+// for every span created.
+func WithAttributes(attr []attribute.KeyValue) Option {
+	return optionConv{iOpt: internal.WithAttributes(attr)}
+}
+
+// WithPropagator returns an Option that sets p as the TextMapPropagator used
+// when propagating a span context.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return optionConv{iOpt: internal.WithPropagator(p)}
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithProducerLink returns an Option that sets whether the Reader starts the
+// span of a read message as the root of a new trace linked to the span
+// propagated by the producer, instead of as its child.
+//
+// A link is preferred when the message is not processed as part of the
+// operation that produced it: e.g. when the consumer processes the messages
+// long after they are produced, or in batches of messages from different
+// producers. The traces of the producer and the consumer are then not merged,
+// which keeps them short and with a meaningful duration. A parent is
+// preferred when the message is processed as a direct continuation of the
+// producer operation (e.g. a request handled asynchronously).
+//
+// By default, the span is a child of the producer span. The option is
+// ignored by the Writer.
+func WithProducerLink(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.producerLink = enabled
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkkafka_test
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/segmentio/kafka-go/splunkkafka"
+)
+
+func ExampleWrapWriter() {
+	w := splunkkafka.WrapWriter(&kafka.Writer{
+		Addr:  kafka.TCP("localhost:9092"),
+		Topic: "myTopic",
+	})
+	defer w.Close()
+
+	// The span context of ctx is propagated in the message headers.
+	ctx := context.Background()
+	err := w.WriteMessages(ctx, kafka.Message{Value: []byte("Hello World!")})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleWrapReader() {
+	r := splunkkafka.WrapReader(kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{"localhost:9092"},
+		GroupID: "myGroup",
+		Topic:   "myTopic",
+	}))
+	defer r.Close()
+
+	msg, err := r.ReadMessage(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	// Continue the trace from the received message.
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), splunkkafka.NewMessageCarrier(&msg))
+	_, span := otel.Tracer("myTracer").Start(ctx, "process")
+	defer span.End()
+}
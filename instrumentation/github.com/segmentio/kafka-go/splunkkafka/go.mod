@@ -0,0 +1,24 @@
+module github.com/signalfx/splunk-otel-go/instrumentation/github.com/segmentio/kafka-go/splunkkafka
+
+go 1.19
+
+require (
+	github.com/segmentio/kafka-go v0.4.42
+	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.7.0
+	github.com/stretchr/testify v1.8.4
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../internal
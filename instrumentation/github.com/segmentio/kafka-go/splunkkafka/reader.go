@@ -0,0 +1,141 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkkafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Reader wraps a kafka.Reader and traces its operations.
+type Reader struct {
+	*kafka.Reader
+	cfg *config
+
+	// readMessage and fetchMessage are the wrapped ReadMessage and
+	// FetchMessage methods.
+	readMessage  func(context.Context) (kafka.Message, error)
+	fetchMessage func(context.Context) (kafka.Message, error)
+}
+
+// WrapReader wraps a kafka.Reader so that any read messages are traced.
+func WrapReader(r *kafka.Reader, opts ...Option) *Reader {
+	cfg := newConfig(opts...)
+	// Common attributes for all spans this reader will produce.
+	attrs := []trace.SpanStartOption{
+		trace.WithAttributes(
+			semconv.MessagingSourceKindTopic,
+			semconv.MessagingOperationReceive,
+		),
+	}
+	if groupID := r.Config().GroupID; groupID != "" {
+		attrs = append(attrs, trace.WithAttributes(
+			semconv.MessagingKafkaConsumerGroupKey.String(groupID),
+		))
+	}
+	cfg.DefaultStartOpts = append(cfg.DefaultStartOpts, attrs...)
+	return &Reader{
+		Reader:       r,
+		cfg:          cfg,
+		readMessage:  r.ReadMessage,
+		fetchMessage: r.FetchMessage,
+	}
+}
+
+// ReadMessage calls the wrapped Reader.ReadMessage and traces the received
+// message. The span is started as a child of the span context propagated in
+// the message headers (or linked to it, see WithProducerLink), and is
+// injected into the returned message headers so it can be used to continue
+// the trace (e.g. by extracting it with NewMessageCarrier).
+func (r *Reader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	msg, err := r.readMessage(ctx)
+	if err != nil {
+		return msg, err
+	}
+	r.traceMessage(&msg)
+	return msg, nil
+}
+
+// FetchMessage calls the wrapped Reader.FetchMessage and traces the received
+// message. The span is started as a child of the span context propagated in
+// the message headers (or linked to it, see WithProducerLink), and is
+// injected into the returned message headers so it can be used to continue
+// the trace (e.g. by extracting it with NewMessageCarrier).
+func (r *Reader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	msg, err := r.fetchMessage(ctx)
+	if err != nil {
+		return msg, err
+	}
+	r.traceMessage(&msg)
+	return msg, nil
+}
+
+func (r *Reader) traceMessage(msg *kafka.Message) {
+	carrier := NewMessageCarrier(msg)
+	psc := r.cfg.Propagator.Extract(context.Background(), carrier)
+
+	const base10 = 10
+	offset := strconv.FormatInt(msg.Offset, base10)
+	opts := r.cfg.MergedSpanStartOptions(
+		trace.WithAttributes(
+			semconv.MessagingSourceNameKey.String(msg.Topic),
+			semconv.MessagingMessageIDKey.String(offset),
+			semconv.MessagingKafkaMessageKeyKey.String(string(msg.Key)),
+			semconv.MessagingKafkaSourcePartitionKey.Int(msg.Partition),
+		),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+	)
+	if r.cfg.producerLink {
+		// The span context is only a link, the span starts a new trace.
+		opts = append(opts, trace.WithNewRoot())
+		if link := trace.LinkFromContext(psc); link.SpanContext.IsValid() {
+			opts = append(opts, trace.WithLinks(link))
+		}
+	}
+
+	name := fmt.Sprintf("%s receive", msg.Topic)
+	ctx, span := r.cfg.Tracer.Start(psc, name, opts...)
+	// The message has been received, there is nothing more to trace.
+	span.End()
+
+	// Inject the current span into the original message so it can be used to
+	// propagate the span.
+	r.cfg.Propagator.Inject(ctx, carrier)
+}
+
+// ProducerLinks returns the links to the span contexts propagated in the
+// headers of msgs, e.g. to start the span processing a batch of messages
+// read from different traces:
+//
+//	ctx, span := tracer.Start(ctx, "process batch", trace.WithLinks(splunkkafka.ProducerLinks(msgs)...))
+//
+// The headers are read with the propagator of the options (or the global
+// propagator). The messages without a valid span context are skipped.
+func ProducerLinks(msgs []kafka.Message, opts ...Option) []trace.Link {
+	cfg := newConfig(opts...)
+	var links []trace.Link
+	for i := range msgs {
+		ctx := cfg.Propagator.Extract(context.Background(), NewMessageCarrier(&msgs[i]))
+		if link := trace.LinkFromContext(ctx); link.SpanContext.IsValid() {
+			links = append(links, link)
+		}
+	}
+	return links
+}
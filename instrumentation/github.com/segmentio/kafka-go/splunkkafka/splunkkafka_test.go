@@ -0,0 +1,382 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkkafka
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queue is an in-memory kafka topic.
+// spanRecorder is a TracerProvider recording the ended spans, so the
+// instrumentation can be tested without the SDK.
+type spanRecorder struct {
+	mu    sync.Mutex
+	ids   uint64
+	ended []*recordedSpan
+}
+
+func newSpanRecorder() *spanRecorder {
+	return &spanRecorder{}
+}
+
+func (r *spanRecorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return r
+}
+
+func (r *spanRecorder) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	var parent trace.SpanContext
+	if !cfg.NewRoot() {
+		parent = trace.SpanContextFromContext(ctx)
+	}
+
+	r.mu.Lock()
+	r.ids++
+	id := r.ids
+	r.mu.Unlock()
+
+	var spanID trace.SpanID
+	binary.BigEndian.PutUint64(spanID[:], id)
+	traceID := parent.TraceID()
+	if !parent.IsValid() {
+		binary.BigEndian.PutUint64(traceID[:], id)
+	}
+
+	_, noop := trace.NewNoopTracerProvider().Tracer("").Start(ctx, name)
+	s := &recordedSpan{
+		Span:     noop,
+		recorder: r,
+		name:     name,
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}),
+		parent: parent,
+		kind:   cfg.SpanKind(),
+		attrs:  cfg.Attributes(),
+		links:  cfg.Links(),
+	}
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+// Ended returns the ended spans in the order they ended.
+func (r *spanRecorder) Ended() []*recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*recordedSpan{}, r.ended...)
+}
+
+type status struct {
+	Code        codes.Code
+	Description string
+}
+
+// recordedSpan is a span recorded by a spanRecorder.
+type recordedSpan struct {
+	trace.Span
+
+	recorder *spanRecorder
+	name     string
+	sc       trace.SpanContext
+	parent   trace.SpanContext
+	kind     trace.SpanKind
+	attrs    []attribute.KeyValue
+	links    []trace.Link
+	status   status
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext { return s.sc }
+
+func (s *recordedSpan) IsRecording() bool { return true }
+
+func (s *recordedSpan) SetStatus(code codes.Code, description string) {
+	s.status = status{Code: code, Description: description}
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.attrs = append(s.attrs, kv...)
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.ended = append(s.recorder.ended, s)
+}
+
+func (s *recordedSpan) Name() string                     { return s.name }
+func (s *recordedSpan) Parent() trace.SpanContext        { return s.parent }
+func (s *recordedSpan) SpanKind() trace.SpanKind         { return s.kind }
+func (s *recordedSpan) Attributes() []attribute.KeyValue { return s.attrs }
+func (s *recordedSpan) Links() []trace.Link              { return s.links }
+func (s *recordedSpan) Status() status                   { return s.status }
+
+type queue []kafka.Message
+
+func (q *queue) write(_ context.Context, msgs ...kafka.Message) error {
+	*q = append(*q, msgs...)
+	return nil
+}
+
+func (q *queue) read(context.Context) (kafka.Message, error) {
+	if len(*q) == 0 {
+		return kafka.Message{}, errors.New("empty queue")
+	}
+	msg := (*q)[0]
+	if msg.Topic == "" {
+		msg.Topic = "topic"
+	}
+	*q = (*q)[1:]
+	return msg, nil
+}
+
+func newTestWriter(t *testing.T, tp trace.TracerProvider, write func(context.Context, ...kafka.Message) error) *Writer {
+	t.Helper()
+	kw := &kafka.Writer{Topic: "topic"}
+	t.Cleanup(func() { assert.NoError(t, kw.Close()) })
+	w := WrapWriter(kw, WithTracerProvider(tp), WithPropagator(propagation.TraceContext{}))
+	w.writeMessages = write
+	return w
+}
+
+func newTestReader(t *testing.T, tp trace.TracerProvider, read func(context.Context) (kafka.Message, error), opts ...Option) *Reader {
+	t.Helper()
+	kr := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{"localhost:9092"},
+		GroupID: "group",
+		Topic:   "topic",
+	})
+	t.Cleanup(func() { assert.NoError(t, kr.Close()) })
+	opts = append([]Option{WithTracerProvider(tp), WithPropagator(propagation.TraceContext{})}, opts...)
+	r := WrapReader(kr, opts...)
+	r.readMessage = read
+	r.fetchMessage = read
+	return r
+}
+
+func spanByName(t *testing.T, spans []*recordedSpan, name string) *recordedSpan {
+	t.Helper()
+	for _, s := range spans {
+		if s.Name() == name {
+			return s
+		}
+	}
+	t.Fatalf("span %q not found", name)
+	return nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	testcases := []struct {
+		name string
+		read func(*Reader) func(context.Context) (kafka.Message, error)
+	}{
+		{
+			name: "ReadMessage",
+			read: func(r *Reader) func(context.Context) (kafka.Message, error) { return r.ReadMessage },
+		},
+		{
+			name: "FetchMessage",
+			read: func(r *Reader) func(context.Context) (kafka.Message, error) { return r.FetchMessage },
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp := newSpanRecorder()
+			q := new(queue)
+			w := newTestWriter(t, tp, q.write)
+			r := newTestReader(t, tp, q.read)
+
+			ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+			err := w.WriteMessages(ctx, kafka.Message{Key: []byte("key"), Value: []byte("value")})
+			require.NoError(t, err)
+			parent.End()
+
+			msg, err := tc.read(r)(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, []byte("value"), msg.Value)
+
+			spans := tp.Ended()
+			require.Len(t, spans, 3)
+			send := spanByName(t, spans, "topic send")
+			receive := spanByName(t, spans, "topic receive")
+
+			assert.Equal(t, trace.SpanKindProducer, send.SpanKind())
+			assert.Equal(t, parent.SpanContext().SpanID(), send.Parent().SpanID())
+			assert.Subset(t, send.Attributes(), []attribute.KeyValue{
+				semconv.MessagingSystemKey.String("kafka"),
+				semconv.MessagingDestinationKindTopic,
+				semconv.MessagingDestinationNameKey.String("topic"),
+				semconv.MessagingOperationPublish,
+				semconv.MessagingKafkaMessageKeyKey.String("key"),
+			})
+
+			assert.Equal(t, trace.SpanKindConsumer, receive.SpanKind())
+			assert.Equal(t, send.SpanContext().TraceID(), receive.SpanContext().TraceID())
+			assert.Equal(t, send.SpanContext().SpanID(), receive.Parent().SpanID())
+			assert.Subset(t, receive.Attributes(), []attribute.KeyValue{
+				semconv.MessagingSystemKey.String("kafka"),
+				semconv.MessagingSourceKindTopic,
+				semconv.MessagingSourceNameKey.String("topic"),
+				semconv.MessagingOperationReceive,
+				semconv.MessagingMessageIDKey.String("0"),
+				semconv.MessagingKafkaMessageKeyKey.String("key"),
+				semconv.MessagingKafkaSourcePartitionKey.Int(0),
+				semconv.MessagingKafkaConsumerGroupKey.String("group"),
+			})
+
+			// The receive span is propagated to continue the trace.
+			got := propagation.TraceContext{}.Extract(context.Background(), NewMessageCarrier(&msg))
+			assert.Equal(t, receive.SpanContext().SpanID(), trace.SpanContextFromContext(got).SpanID())
+		})
+	}
+}
+
+func TestWriteMessagesError(t *testing.T) {
+	tp := newSpanRecorder()
+	errFail := errors.New("fail")
+	w := newTestWriter(t, tp, func(context.Context, ...kafka.Message) error {
+		return kafka.WriteErrors{nil, errFail}
+	})
+
+	err := w.WriteMessages(context.Background(),
+		kafka.Message{Key: []byte("ok")},
+		kafka.Message{Key: []byte("failed")},
+	)
+	require.Error(t, err)
+
+	spans := tp.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, errFail.Error(), spans[1].Status().Description)
+}
+
+func TestWriteMessagesFailure(t *testing.T) {
+	tp := newSpanRecorder()
+	errFail := errors.New("fail")
+	w := newTestWriter(t, tp, func(context.Context, ...kafka.Message) error {
+		return errFail
+	})
+
+	err := w.WriteMessages(context.Background(), kafka.Message{}, kafka.Message{Topic: "other"})
+	require.ErrorIs(t, err, errFail)
+
+	spans := tp.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "topic send", spans[0].Name())
+	assert.Equal(t, "other send", spans[1].Name())
+	for _, s := range spans {
+		assert.Equal(t, codes.Error, s.Status().Code)
+	}
+}
+
+func TestReadMessageError(t *testing.T) {
+	tp := newSpanRecorder()
+	q := new(queue)
+	r := newTestReader(t, tp, q.read)
+
+	_, err := r.ReadMessage(context.Background())
+	require.Error(t, err)
+	_, err = r.FetchMessage(context.Background())
+	require.Error(t, err)
+	assert.Empty(t, tp.Ended())
+}
+
+func TestReadMessageProducerLink(t *testing.T) {
+	tp := newSpanRecorder()
+	q := new(queue)
+	w := newTestWriter(t, tp, q.write)
+	r := newTestReader(t, tp, q.read, WithProducerLink(true))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	require.NoError(t, w.WriteMessages(ctx, kafka.Message{Value: []byte("value")}))
+	parent.End()
+
+	msg, err := r.ReadMessage(context.Background())
+	require.NoError(t, err)
+
+	spans := tp.Ended()
+	require.Len(t, spans, 3)
+	send := spanByName(t, spans, "topic send")
+	receive := spanByName(t, spans, "topic receive")
+
+	assert.False(t, receive.Parent().IsValid(), "receive span must not have a parent")
+	assert.NotEqual(t, send.SpanContext().TraceID(), receive.SpanContext().TraceID(), "receive span must start a new trace")
+	require.Len(t, receive.Links(), 1)
+	link := receive.Links()[0].SpanContext
+	assert.Equal(t, send.SpanContext().TraceID(), link.TraceID())
+	assert.Equal(t, send.SpanContext().SpanID(), link.SpanID())
+	assert.True(t, link.IsRemote())
+
+	// The receive span is propagated to continue its trace.
+	got := propagation.TraceContext{}.Extract(context.Background(), NewMessageCarrier(&msg))
+	assert.Equal(t, receive.SpanContext().SpanID(), trace.SpanContextFromContext(got).SpanID())
+}
+
+func TestReadMessageProducerLinkNotPropagated(t *testing.T) {
+	tp := newSpanRecorder()
+	q := &queue{{Topic: "topic", Value: []byte("value")}}
+	r := newTestReader(t, tp, q.read, WithProducerLink(true))
+
+	_, err := r.ReadMessage(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, tp.Ended(), 1)
+	receive := tp.Ended()[0]
+	assert.False(t, receive.Parent().IsValid())
+	assert.Empty(t, receive.Links(), "no link must be added without a propagated span context")
+}
+
+func TestProducerLinks(t *testing.T) {
+	tp := newSpanRecorder()
+	q := new(queue)
+	w := newTestWriter(t, tp, q.write)
+
+	for i := 0; i < 2; i++ {
+		ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+		require.NoError(t, w.WriteMessages(ctx, kafka.Message{Value: []byte("value")}))
+		parent.End()
+	}
+	msgs := append([]kafka.Message{}, *q...)
+	msgs = append(msgs, kafka.Message{Value: []byte("not traced")})
+
+	links := ProducerLinks(msgs, WithPropagator(propagation.TraceContext{}))
+	require.Len(t, links, 2)
+	var sends []trace.SpanContext
+	for _, s := range tp.Ended() {
+		if s.Name() == "topic send" {
+			sends = append(sends, s.SpanContext())
+		}
+	}
+	require.Len(t, sends, 2)
+	for i, l := range links {
+		assert.Equal(t, sends[i].TraceID(), l.SpanContext.TraceID())
+		assert.Equal(t, sends[i].SpanID(), l.SpanContext.SpanID())
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/segmentio/kafka-go/splunkkafka"
+)
+
+// unreachableAddr returns the address of a closed listener, so the messages
+// written to it fail without a broker.
+func unreachableAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+func TestWriteMessages(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	prop := propagation.TraceContext{}
+	w := splunkkafka.WrapWriter(&kafka.Writer{
+		Addr:         kafka.TCP(unreachableAddr(t)),
+		Topic:        "topic",
+		MaxAttempts:  1,
+		BatchTimeout: time.Millisecond,
+	}, splunkkafka.WithTracerProvider(tp), splunkkafka.WithPropagator(prop))
+	t.Cleanup(func() { assert.NoError(t, w.Close()) })
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	msgs := []kafka.Message{
+		{Key: []byte("key1"), Value: []byte("value")},
+		{Key: []byte("key2"), Value: []byte("value")},
+	}
+	err := w.WriteMessages(ctx, msgs...)
+	parent.End()
+	require.Error(t, err, "the broker is not reachable")
+
+	var sends []trace.ReadOnlySpan
+	for _, s := range sr.Ended() {
+		if s.Name() == "topic send" {
+			sends = append(sends, s)
+		}
+	}
+	require.Len(t, sends, 2)
+	for i, s := range sends {
+		assert.Equal(t, traceapi.SpanKindProducer, s.SpanKind())
+		assert.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID())
+		assert.Equal(t, codes.Error, s.Status().Code)
+		assert.Subset(t, s.Attributes(), []attribute.KeyValue{
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingDestinationNameKey.String("topic"),
+			semconv.MessagingOperationPublish,
+			semconv.MessagingKafkaMessageKeyKey.String(string(msgs[i].Key)),
+		})
+
+		// The span context of the send span is propagated in the headers.
+		got := prop.Extract(context.Background(), splunkkafka.NewMessageCarrier(&msgs[i]))
+		assert.Equal(t, s.SpanContext().SpanID(), traceapi.SpanContextFromContext(got).SpanID())
+	}
+
+	links := splunkkafka.ProducerLinks(msgs, splunkkafka.WithPropagator(prop))
+	require.Len(t, links, 2)
+	for i, l := range links {
+		assert.Equal(t, sends[i].SpanContext().SpanID(), l.SpanContext.SpanID())
+	}
+}
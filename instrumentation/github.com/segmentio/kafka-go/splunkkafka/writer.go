@@ -0,0 +1,105 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkkafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Writer wraps a kafka.Writer and traces its operations.
+type Writer struct {
+	*kafka.Writer
+	cfg *config
+
+	// writeMessages is the wrapped WriteMessages method.
+	writeMessages func(context.Context, ...kafka.Message) error
+}
+
+// WrapWriter wraps a kafka.Writer so that any written messages are traced.
+func WrapWriter(w *kafka.Writer, opts ...Option) *Writer {
+	cfg := newConfig(opts...)
+	// Common attributes for all spans this writer will produce.
+	cfg.DefaultStartOpts = append(
+		cfg.DefaultStartOpts,
+		trace.WithAttributes(
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingOperationPublish,
+		),
+	)
+	return &Writer{
+		Writer:        w,
+		cfg:           cfg,
+		writeMessages: w.WriteMessages,
+	}
+}
+
+// WriteMessages calls the wrapped Writer.WriteMessages and traces the
+// request. A span is started as a child of ctx for every message, and its
+// span context is injected into the message headers so consumers can continue
+// the trace.
+func (w *Writer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	spans := make([]trace.Span, len(msgs))
+	for i := range msgs {
+		spans[i] = w.startSpan(ctx, &msgs[i])
+	}
+
+	err := w.writeMessages(ctx, msgs...)
+
+	var writeErrs kafka.WriteErrors
+	isWriteErrs := errors.As(err, &writeErrs) && len(writeErrs) == len(msgs)
+	for i, span := range spans {
+		spanErr := err
+		if isWriteErrs {
+			// Only record the error for the message that failed.
+			spanErr = writeErrs[i]
+		}
+		if spanErr != nil {
+			span.RecordError(spanErr)
+			span.SetStatus(codes.Error, spanErr.Error())
+		}
+		span.End()
+	}
+	return err
+}
+
+func (w *Writer) startSpan(ctx context.Context, msg *kafka.Message) trace.Span {
+	topic := msg.Topic
+	if topic == "" {
+		topic = w.Topic
+	}
+
+	opts := w.cfg.MergedSpanStartOptions(
+		trace.WithAttributes(
+			semconv.MessagingDestinationNameKey.String(topic),
+			semconv.MessagingKafkaMessageKeyKey.String(string(msg.Key)),
+		),
+		trace.WithSpanKind(trace.SpanKindProducer),
+	)
+
+	name := fmt.Sprintf("%s send", topic)
+	ctx, span := w.cfg.Tracer.Start(ctx, name, opts...)
+
+	// Inject the current span into the original message so it can be used to
+	// propagate the span.
+	w.cfg.Propagator.Inject(ctx, NewMessageCarrier(msg))
+	return span
+}
@@ -0,0 +1,51 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunklogrus
+
+import "github.com/sirupsen/logrus"
+
+// config contains configuration options.
+type config struct {
+	Levels []logrus.Level
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		Levels: logrus.AllLevels,
+	}
+	for _, o := range opts {
+		o.apply(c)
+	}
+	return c
+}
+
+// Option applies options to a configuration.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) {
+	fn(c)
+}
+
+// WithLevels returns an Option that sets the levels of the entries the trace
+// context is added to. By default, it is added to the entries of all levels.
+func WithLevels(levels ...logrus.Level) Option {
+	return optionFunc(func(c *config) {
+		c.Levels = levels
+	})
+}
@@ -0,0 +1,18 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunklogrus provides a logrus hook adding the trace context of the
+// active span to the log entries (e.g. to correlate logs and traces in Splunk
+// Observability Cloud).
+package splunklogrus // import "github.com/signalfx/splunk-otel-go/instrumentation/github.com/sirupsen/logrus/splunklogrus"
@@ -0,0 +1,36 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunklogrus_test
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/sirupsen/logrus/splunklogrus"
+)
+
+func Example() {
+	logger := logrus.New()
+	logger.AddHook(splunklogrus.NewHook())
+
+	ctx, span := otel.Tracer("my-service").Start(context.Background(), "operation")
+	defer span.End()
+
+	// The context has to be passed to the entry for the trace_id, span_id,
+	// and trace_flags fields to be added.
+	logger.WithContext(ctx).Info("handling operation")
+}
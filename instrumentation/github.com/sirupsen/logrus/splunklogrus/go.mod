@@ -0,0 +1,17 @@
+module github.com/signalfx/splunk-otel-go/instrumentation/github.com/sirupsen/logrus/splunklogrus
+
+go 1.19
+
+require (
+	github.com/sirupsen/logrus v1.9.3
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/stretchr/testify v1.8.4 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+	golang.org/x/sys v0.8.0 // indirect
+)
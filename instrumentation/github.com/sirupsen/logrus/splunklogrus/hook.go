@@ -0,0 +1,67 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunklogrus
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Keys of the fields added to the log entries.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// Hook is a logrus.Hook adding the trace context of the span in the context
+// of the log entries. The trace ID, span ID, and trace flags are added as the
+// trace_id, span_id, and trace_flags hex-encoded string fields.
+//
+// Only the entries with a context (i.e. logged using an entry returned by
+// the WithContext method of logrus.Logger or logrus.Entry) containing a valid
+// span context are modified, the other entries are skipped.
+type Hook struct {
+	levels []logrus.Level
+}
+
+var _ logrus.Hook = (*Hook)(nil)
+
+// NewHook returns a new Hook configured with opts.
+func NewHook(opts ...Option) *Hook {
+	cfg := newConfig(opts...)
+	return &Hook{levels: cfg.Levels}
+}
+
+// Levels returns the levels of the entries the hook is fired for.
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire adds the trace context of the span in the entry context to the entry
+// fields.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(entry.Context)
+	if !sc.IsValid() {
+		return nil
+	}
+	entry.Data[TraceIDKey] = sc.TraceID().String()
+	entry.Data[SpanIDKey] = sc.SpanID().String()
+	entry.Data[TraceFlagsKey] = sc.TraceFlags().String()
+	return nil
+}
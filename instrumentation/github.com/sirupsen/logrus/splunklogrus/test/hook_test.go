@@ -0,0 +1,129 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/sirupsen/logrus/splunklogrus"
+)
+
+func newLogger(opts ...splunklogrus.Option) (*logrus.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(splunklogrus.NewHook(opts...))
+	return logger, &buf
+}
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	buf.Reset()
+	return got
+}
+
+func TestHookWithSpan(t *testing.T) {
+	logger, buf := newLogger()
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer(t.Name()).Start(context.Background(), "span")
+	defer span.End()
+	logger.WithContext(ctx).WithField("key", "value").Info("message")
+
+	got := decode(t, buf)
+	sc := span.SpanContext()
+	assert.Equal(t, "message", got["msg"])
+	assert.Equal(t, "value", got["key"])
+	assert.Equal(t, sc.TraceID().String(), got[splunklogrus.TraceIDKey])
+	assert.Equal(t, sc.SpanID().String(), got[splunklogrus.SpanIDKey])
+	assert.Equal(t, "01", got[splunklogrus.TraceFlagsKey])
+}
+
+func TestHookWithoutSpan(t *testing.T) {
+	testCases := []struct {
+		desc string
+		log  func(*logrus.Logger)
+	}{
+		{
+			desc: "no context",
+			log:  func(l *logrus.Logger) { l.Info("message") },
+		},
+		{
+			desc: "no span",
+			log:  func(l *logrus.Logger) { l.WithContext(context.Background()).Info("message") },
+		},
+		{
+			desc: "invalid span context",
+			log: func(l *logrus.Logger) {
+				ctx := trace.ContextWithSpanContext(context.Background(), trace.SpanContext{})
+				l.WithContext(ctx).Info("message")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			logger, buf := newLogger()
+			tc.log(logger)
+
+			got := decode(t, buf)
+			assert.Equal(t, "message", got["msg"])
+			assert.NotContains(t, got, splunklogrus.TraceIDKey)
+			assert.NotContains(t, got, splunklogrus.SpanIDKey)
+			assert.NotContains(t, got, splunklogrus.TraceFlagsKey)
+		})
+	}
+}
+
+func TestHookDoesNotModifyEntry(t *testing.T) {
+	logger, buf := newLogger()
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer(t.Name()).Start(context.Background(), "span")
+	defer span.End()
+	entry := logger.WithContext(ctx)
+	entry.Info("message")
+	assert.Contains(t, decode(t, buf), splunklogrus.TraceIDKey)
+
+	// The entry is reused with a context without a span.
+	entry.WithContext(context.Background()).Info("message")
+	assert.NotContains(t, decode(t, buf), splunklogrus.TraceIDKey)
+	assert.Empty(t, entry.Data)
+}
+
+func TestWithLevels(t *testing.T) {
+	logger, buf := newLogger(splunklogrus.WithLevels(logrus.ErrorLevel))
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer(t.Name()).Start(context.Background(), "span")
+	defer span.End()
+
+	logger.WithContext(ctx).Info("message")
+	assert.NotContains(t, decode(t, buf), splunklogrus.TraceIDKey)
+
+	logger.WithContext(ctx).Error("message")
+	assert.Contains(t, decode(t, buf), splunklogrus.TraceIDKey)
+}
@@ -0,0 +1,57 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkfasthttp_test
+
+import (
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/valyala/fasthttp/splunkfasthttp"
+)
+
+func Example() {
+	handler := func(ctx *fasthttp.RequestCtx) {
+		// The RequestCtx is reused once the handler returns, use the context
+		// returned by splunkfasthttp.Context to start child spans.
+		_, span := otel.Tracer("my-service").Start(splunkfasthttp.Context(ctx), "work")
+		defer span.End()
+
+		ctx.SetBodyString("Hello World!\n")
+	}
+	if err := fasthttp.ListenAndServe(":8080", splunkfasthttp.WrapHandler(handler)); err != nil {
+		panic(err)
+	}
+}
+
+func ExampleInject() {
+	handler := func(ctx *fasthttp.RequestCtx) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		resp := fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseResponse(resp)
+
+		req.SetRequestURI("http://backend:8080/")
+		// Propagate the trace context to the backend.
+		splunkfasthttp.Inject(splunkfasthttp.Context(ctx), &req.Header)
+		if err := fasthttp.Do(req, resp); err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusBadGateway)
+			return
+		}
+		ctx.SetBody(resp.Body())
+	}
+	if err := fasthttp.ListenAndServe(":8080", splunkfasthttp.WrapHandler(handler)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunkfasthttp provides OpenTelemetry instrumentation for the
+// github.com/valyala/fasthttp package.
+package splunkfasthttp
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/internal"
+)
+
+// instrumentationName is the instrumentation library identifier for a Tracer.
+const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/github.com/valyala/fasthttp/splunkfasthttp"
+
+// contextKey is the key of the user value of the RequestCtx holding the
+// context of the request span.
+type contextKey struct{}
+
+// WrapHandler returns a fasthttp.RequestHandler tracing the requests served
+// by handler.
+//
+// The trace context is extracted from the request headers with the
+// configured propagator, and the span is named "HTTP " followed by the
+// request method. The response status code is recorded once handler returns.
+//
+// fasthttp reuses the RequestCtx once the handler returns, so the span is
+// not stored in it as a context.Context. Use Context to get a context with
+// the span, e.g. to start child spans or to inject the trace context into
+// outgoing requests.
+func WrapHandler(handler fasthttp.RequestHandler, options ...Option) fasthttp.RequestHandler {
+	cfg := newConfig(options...)
+
+	return func(ctx *fasthttp.RequestCtx) {
+		tracer := cfg.ResolveTracer(context.Background())
+		// The context is not derived from the RequestCtx, which is reused
+		// for other requests once this one is handled.
+		parent := cfg.Propagator.Extract(context.Background(), requestHeaderCarrier{h: &ctx.Request.Header})
+		name := "HTTP " + string(ctx.Method())
+		opts := make([]trace.SpanStartOption, 0, len(cfg.DefaultStartOpts)+1)
+		opts = append(opts, cfg.DefaultStartOpts...)
+		opts = append(opts, trace.WithAttributes(serverRequestAttrs(ctx)...))
+		spanCtx, span := tracer.Start(parent, name, opts...)
+		defer span.End()
+
+		ctx.SetUserValue(contextKey{}, spanCtx)
+		defer ctx.RemoveUserValue(contextKey{})
+
+		handler(ctx)
+
+		status := ctx.Response.StatusCode()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		span.SetStatus(httpconv.ServerStatus(status))
+	}
+}
+
+// Context returns a context with the span of the request handled by the
+// handler returned by WrapHandler. The context is not canceled when the
+// request ends and can be used once the handler returns. If the request is
+// not traced, context.Background() is returned.
+func Context(ctx *fasthttp.RequestCtx) context.Context {
+	if c, ok := ctx.UserValue(contextKey{}).(context.Context); ok {
+		return c
+	}
+	return context.Background()
+}
+
+// Inject injects the trace context of ctx into the headers of an outgoing
+// request with the propagator of the options (or the global propagator).
+func Inject(ctx context.Context, h *fasthttp.RequestHeader, options ...Option) {
+	cfg := newConfig(options...)
+	cfg.Propagator.Inject(ctx, requestHeaderCarrier{h: h})
+}
+
+func newConfig(options ...Option) *internal.Config {
+	o := append([]internal.Option{
+		internal.OptionFunc(func(c *internal.Config) {
+			c.Version = Version()
+			c.DefaultStartOpts = append(c.DefaultStartOpts, trace.WithSpanKind(trace.SpanKindServer))
+		}),
+	}, localToInternal(options)...)
+	return internal.NewConfig(instrumentationName, o...)
+}
+
+// serverRequestAttrs returns the semantic convention attributes of the
+// request, as httpconv.ServerRequest for net/http requests.
+func serverRequestAttrs(ctx *fasthttp.RequestCtx) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPMethodKey.String(string(ctx.Method())),
+		semconv.HTTPTargetKey.String(string(ctx.RequestURI())),
+	}
+	if ctx.IsTLS() {
+		attrs = append(attrs, semconv.HTTPSchemeHTTPS)
+	} else {
+		attrs = append(attrs, semconv.HTTPSchemeHTTP)
+	}
+	switch string(ctx.Request.Header.Protocol()) {
+	case "HTTP/1.0":
+		attrs = append(attrs, semconv.HTTPFlavorHTTP10)
+	case "HTTP/1.1":
+		attrs = append(attrs, semconv.HTTPFlavorHTTP11)
+	}
+	if host, port, err := net.SplitHostPort(string(ctx.Host())); err == nil {
+		attrs = append(attrs, semconv.NetHostNameKey.String(host))
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.NetHostPortKey.Int(p))
+		}
+	} else if host := ctx.Host(); len(host) > 0 {
+		attrs = append(attrs, semconv.NetHostNameKey.String(string(host)))
+	}
+	if addr, ok := ctx.RemoteAddr().(*net.TCPAddr); ok {
+		attrs = append(attrs,
+			semconv.NetSockPeerAddrKey.String(addr.IP.String()),
+			semconv.NetSockPeerPortKey.Int(addr.Port),
+		)
+	}
+	if ua := ctx.UserAgent(); len(ua) > 0 {
+		attrs = append(attrs, semconv.HTTPUserAgentKey.String(string(ua)))
+	}
+	return attrs
+}
+
+// requestHeaderCarrier adapts the fasthttp request headers to a
+// propagation.TextMapCarrier.
+type requestHeaderCarrier struct {
+	h *fasthttp.RequestHeader
+}
+
+var _ propagation.TextMapCarrier = requestHeaderCarrier{}
+
+// Get returns the value of the header key.
+func (c requestHeaderCarrier) Get(key string) string {
+	return string(c.h.Peek(key))
+}
+
+// Set sets the header key to value.
+func (c requestHeaderCarrier) Set(key, value string) {
+	c.h.Set(key, value)
+}
+
+// Keys returns the keys of the headers.
+func (c requestHeaderCarrier) Keys() []string {
+	var keys []string
+	c.h.VisitAll(func(k, _ []byte) {
+		keys = append(keys, string(k))
+	})
+	return keys
+}
@@ -0,0 +1,21 @@
+module github.com/signalfx/splunk-otel-go/instrumentation/github.com/valyala/fasthttp/splunkfasthttp
+
+go 1.19
+
+require (
+	github.com/signalfx/splunk-otel-go/instrumentation/internal v1.7.0
+	github.com/valyala/fasthttp v1.44.0
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.0.4 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+)
+
+replace github.com/signalfx/splunk-otel-go/instrumentation/internal => ../../../../internal/
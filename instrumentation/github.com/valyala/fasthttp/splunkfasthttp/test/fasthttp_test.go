@@ -0,0 +1,158 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package test provides end-to-end testing of the splunkfasthttp instrumentation
+with the default SDK.
+
+This package is in a separate module from the instrumentation it tests to
+isolate the dependency of the default SDK and not impose this as a transitive
+dependency for users.
+*/
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/valyala/fasthttp/splunkfasthttp"
+)
+
+// serve serves the handler wrapped with the instrumentation on an in-memory
+// listener and returns a client sending requests to it.
+func serve(t *testing.T, tp traceapi.TracerProvider, handler fasthttp.RequestHandler) *fasthttp.Client {
+	ln := fasthttputil.NewInmemoryListener()
+	srv := &fasthttp.Server{Handler: splunkfasthttp.WrapHandler(handler,
+		splunkfasthttp.WithTracerProvider(tp),
+		splunkfasthttp.WithPropagator(propagation.TraceContext{}),
+	)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, srv.Serve(ln))
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Shutdown())
+		<-done
+	})
+
+	return &fasthttp.Client{
+		Dial: func(string) (net.Conn, error) { return ln.Dial() },
+	}
+}
+
+func newTracerProvider(t *testing.T) (*tracetest.SpanRecorder, *trace.TracerProvider) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+	return sr, tp
+}
+
+func do(t *testing.T, client *fasthttp.Client, method, uri string, setup func(*fasthttp.Request)) int {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	if setup != nil {
+		setup(req)
+	}
+	require.NoError(t, client.Do(req, resp))
+	return resp.StatusCode()
+}
+
+func TestWrapHandler(t *testing.T) {
+	sr, tp := newTracerProvider(t)
+	client := serve(t, tp, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusAccepted)
+	})
+
+	status := do(t, client, fasthttp.MethodPut, "http://example.com:8080/users/42?q=1", func(req *fasthttp.Request) {
+		req.Header.SetUserAgent("test-agent")
+	})
+	assert.Equal(t, fasthttp.StatusAccepted, status)
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assert.Equal(t, "HTTP PUT", span.Name())
+	assert.Equal(t, traceapi.SpanKindServer, span.SpanKind())
+	assert.Equal(t, splunkfasthttp.Version(), span.InstrumentationLibrary().Version)
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	assert.False(t, span.Parent().IsValid(), "span must be a root span")
+
+	attrs := span.Attributes()
+	assert.Contains(t, attrs, semconv.HTTPMethodKey.String("PUT"))
+	assert.Contains(t, attrs, semconv.HTTPTargetKey.String("/users/42?q=1"))
+	assert.Contains(t, attrs, semconv.HTTPSchemeHTTP)
+	assert.Contains(t, attrs, semconv.HTTPFlavorHTTP11)
+	assert.Contains(t, attrs, semconv.NetHostNameKey.String("example.com"))
+	assert.Contains(t, attrs, semconv.NetHostPortKey.Int(8080))
+	assert.Contains(t, attrs, semconv.HTTPUserAgentKey.String("test-agent"))
+	assert.Contains(t, attrs, semconv.HTTPStatusCodeKey.Int(fasthttp.StatusAccepted))
+}
+
+func TestWrapHandlerServerError(t *testing.T) {
+	sr, tp := newTracerProvider(t)
+	client := serve(t, tp, func(ctx *fasthttp.RequestCtx) {
+		ctx.Error("boom", fasthttp.StatusInternalServerError)
+	})
+
+	assert.Equal(t, fasthttp.StatusInternalServerError, do(t, client, fasthttp.MethodGet, "http://localhost/", nil))
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), semconv.HTTPStatusCodeKey.Int(fasthttp.StatusInternalServerError))
+}
+
+func TestWrapHandlerPropagation(t *testing.T) {
+	sr, tp := newTracerProvider(t)
+
+	var got traceapi.SpanContext
+	client := serve(t, tp, func(ctx *fasthttp.RequestCtx) {
+		got = traceapi.SpanContextFromContext(splunkfasthttp.Context(ctx))
+	})
+
+	// Inject the trace context of a client span into the request.
+	clientCtx, clientSpan := tp.Tracer(t.Name()).Start(context.Background(), "client")
+	do(t, client, fasthttp.MethodGet, "http://localhost/", func(req *fasthttp.Request) {
+		splunkfasthttp.Inject(clientCtx, &req.Header, splunkfasthttp.WithPropagator(propagation.TraceContext{}))
+	})
+	clientSpan.End()
+
+	require.Len(t, sr.Ended(), 2)
+	server := sr.Ended()[0]
+	assert.Equal(t, clientSpan.SpanContext().TraceID(), server.SpanContext().TraceID())
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), server.Parent().SpanID())
+	assert.True(t, server.Parent().IsRemote())
+	assert.Equal(t, server.SpanContext(), got, "context must contain the server span")
+}
+
+func TestContextNotTraced(t *testing.T) {
+	assert.Equal(t, context.Background(), splunkfasthttp.Context(&fasthttp.RequestCtx{}))
+}
@@ -0,0 +1,168 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkmongo
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultPort is the default port of a MongoDB server.
+const defaultPort = 27017
+
+// NewCommandMonitor returns an event.CommandMonitor that traces the commands
+// sent to a MongoDB server. It is meant to be set with the SetMonitor method
+// of the options.ClientOptions used to connect to the server.
+func NewCommandMonitor(opts ...Option) *event.CommandMonitor {
+	m := &monitor{
+		cfg:   newConfig(opts...),
+		spans: make(map[spanKey]trace.Span),
+	}
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+// spanKey identifies the span of a command.
+type spanKey struct {
+	ConnectionID string
+	RequestID    int64
+}
+
+type monitor struct {
+	cfg config
+
+	mu    sync.Mutex
+	spans map[spanKey]trace.Span
+}
+
+func (m *monitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	attrs := []attribute.KeyValue{
+		semconv.DBOperationKey.String(evt.CommandName),
+		semconv.NetTransportTCP,
+	}
+	if evt.DatabaseName != "" {
+		attrs = append(attrs, semconv.DBNameKey.String(evt.DatabaseName))
+	}
+	host, port := peerInfo(evt.ConnectionID)
+	if host != "" {
+		attrs = append(attrs,
+			semconv.NetPeerNameKey.String(host),
+			semconv.NetPeerPortKey.Int(port),
+		)
+	}
+	collection := collectionName(evt)
+	if collection != "" {
+		attrs = append(attrs, semconv.DBMongoDBCollectionKey.String(collection))
+	}
+	if m.cfg.StatementCaptured {
+		if stmt, err := bson.MarshalExtJSON(evt.Command, false, false); err == nil {
+			attrs = append(attrs, semconv.DBStatementKey.String(string(stmt)))
+		}
+	}
+
+	opts := m.cfg.MergedSpanStartOptions(trace.WithAttributes(attrs...))
+	_, span := m.cfg.ResolveTracer(ctx).Start(ctx, spanName(evt, collection), opts...)
+
+	m.mu.Lock()
+	m.spans[spanKey{ConnectionID: evt.ConnectionID, RequestID: evt.RequestID}] = span
+	m.mu.Unlock()
+}
+
+func (m *monitor) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	if span, ok := m.pop(&evt.CommandFinishedEvent); ok {
+		span.End()
+	}
+}
+
+func (m *monitor) failed(_ context.Context, evt *event.CommandFailedEvent) {
+	if span, ok := m.pop(&evt.CommandFinishedEvent); ok {
+		span.SetStatus(codes.Error, evt.Failure)
+		span.End()
+	}
+}
+
+// pop returns and forgets the span of the finished command.
+func (m *monitor) pop(evt *event.CommandFinishedEvent) (trace.Span, bool) {
+	key := spanKey{ConnectionID: evt.ConnectionID, RequestID: evt.RequestID}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	span, ok := m.spans[key]
+	if ok {
+		delete(m.spans, key)
+	}
+	return span, ok
+}
+
+// spanName returns the OpenTelemetry compliant span name:
+// `<db.operation> <db.name>.<db.mongodb.collection>`.
+func spanName(evt *event.CommandStartedEvent, collection string) string {
+	name := evt.CommandName
+	if evt.DatabaseName == "" {
+		return name
+	}
+	name += " " + evt.DatabaseName
+	if collection != "" {
+		name += "." + collection
+	}
+	return name
+}
+
+// collectionName returns the collection the command operates on. For CRUD
+// operations, this is the value of the first element of the command if its
+// key is the command name (e.g. {"find": "collection", ...}).
+func collectionName(evt *event.CommandStartedEvent) string {
+	elem, err := evt.Command.IndexErr(0)
+	if err != nil || elem.Key() != evt.CommandName {
+		return ""
+	}
+	val := elem.Value()
+	if val.Type != bsontype.String {
+		return ""
+	}
+	return val.StringValue()
+}
+
+// peerInfo returns the host and port of the server from the connection ID
+// (e.g. "localhost:27017[-2]").
+func peerInfo(connectionID string) (string, int) {
+	addr := connectionID
+	if i := strings.LastIndexByte(addr, '['); i >= 0 {
+		addr = addr[:i]
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		// No port in the address.
+		return addr, defaultPort
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return host, defaultPort
+	}
+	return host, port
+}
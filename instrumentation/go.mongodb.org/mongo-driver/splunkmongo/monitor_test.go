@@ -0,0 +1,42 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkmongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerInfo(t *testing.T) {
+	testcases := []struct {
+		connectionID string
+		host         string
+		port         int
+	}{
+		{connectionID: "localhost:27018[-2]", host: "localhost", port: 27018},
+		{connectionID: "localhost", host: "localhost", port: defaultPort},
+		{connectionID: "[::1]:27019[-1]", host: "::1", port: 27019},
+		{connectionID: "", host: "", port: defaultPort},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.connectionID, func(t *testing.T) {
+			host, port := peerInfo(tc.connectionID)
+			assert.Equal(t, tc.host, host)
+			assert.Equal(t, tc.port, port)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	traceapi "go.opentelemetry.io/otel/trace"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/go.mongodb.org/mongo-driver/splunkmongo"
+)
+
+func newMockT(t *testing.T, sr *tracetest.SpanRecorder, opts ...splunkmongo.Option) *mtest.T {
+	t.Helper()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	opts = append([]splunkmongo.Option{splunkmongo.WithTracerProvider(tp)}, opts...)
+	clientOpts := options.Client().SetMonitor(splunkmongo.NewCommandMonitor(opts...))
+	return mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock).ClientOptions(clientOpts))
+}
+
+func TestCommandMonitor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	mt := newMockT(t, sr)
+	defer mt.Close()
+
+	mt.Run("insert", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		_, err := mt.Coll.InsertOne(context.Background(), bson.D{{Key: "password", Value: "s3cr3t"}})
+		require.NoError(mt, err)
+
+		spans := sr.Ended()
+		require.Len(mt, spans, 1)
+		span := spans[0]
+		db, coll := mt.Coll.Database().Name(), mt.Coll.Name()
+		assert.Equal(mt, "insert "+db+"."+coll, span.Name())
+		assert.Equal(mt, traceapi.SpanKindClient, span.SpanKind())
+		assert.Equal(mt, codes.Unset, span.Status().Code)
+		assert.Subset(mt, span.Attributes(), []attribute.KeyValue{
+			semconv.DBSystemMongoDB,
+			semconv.DBOperationKey.String("insert"),
+			semconv.DBNameKey.String(db),
+			semconv.DBMongoDBCollectionKey.String(coll),
+			semconv.NetTransportTCP,
+		})
+		for _, a := range span.Attributes() {
+			assert.NotEqual(mt, semconv.DBStatementKey, a.Key, "statement captured by default")
+			if a.Key == semconv.NetPeerNameKey {
+				assert.NotEmpty(mt, a.Value.AsString())
+			}
+		}
+	})
+}
+
+func TestCommandMonitorFailure(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	mt := newMockT(t, sr)
+	defer mt.Close()
+
+	mt.Run("find", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    11000,
+			Message: "duplicate key",
+			Name:    "DuplicateKey",
+		}))
+		err := mt.Coll.FindOne(context.Background(), bson.D{}).Err()
+		require.Error(mt, err)
+
+		spans := sr.Ended()
+		require.Len(mt, spans, 1)
+		assert.Equal(mt, codes.Error, spans[0].Status().Code)
+	})
+}
+
+func TestCommandMonitorStatementCaptured(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	mt := newMockT(t, sr, splunkmongo.WithStatementCaptured(true))
+	defer mt.Close()
+
+	mt.Run("insert", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		_, err := mt.Coll.InsertOne(context.Background(), bson.D{{Key: "name", Value: "bob"}})
+		require.NoError(mt, err)
+
+		spans := sr.Ended()
+		require.Len(mt, spans, 1)
+		var stmt string
+		for _, a := range spans[0].Attributes() {
+			if a.Key == semconv.DBStatementKey {
+				stmt = a.Value.AsString()
+			}
+		}
+		assert.Contains(mt, stmt, `"insert":"`+mt.Coll.Name()+`"`)
+		assert.Contains(mt, stmt, `"name":"bob"`)
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkzap
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys of the fields added to the log entries.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// contextKey is the key of the fields created by Context.
+const contextKey = "splunkzap.context"
+
+// Context returns a field carrying ctx. A Core created by NewCore replaces it
+// with the trace_id, span_id, and trace_flags fields of the span context in
+// ctx, or drops it if ctx does not contain a valid span context. The field is
+// ignored by the other cores.
+func Context(ctx context.Context) zap.Field {
+	return zap.Field{Key: contextKey, Type: zapcore.SkipType, Interface: ctx}
+}
+
+// With returns a child logger of l adding the trace_id, span_id, and
+// trace_flags fields of the span context in ctx to all its entries. The logger
+// is returned unchanged if ctx does not contain a valid span context. The
+// core of l does not need to be created by NewCore.
+func With(ctx context.Context, l *zap.Logger) *zap.Logger {
+	fields := traceFields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// NewCore returns a zapcore.Core wrapping c that replaces the fields created
+// by Context with the trace context fields.
+func NewCore(c zapcore.Core) zapcore.Core {
+	return &core{Core: c}
+}
+
+type core struct {
+	zapcore.Core
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{Core: c.Core.With(expand(fields))}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, expand(fields))
+}
+
+// expand returns fields with the fields created by Context replaced with the
+// trace context fields. The passed slice is not modified.
+func expand(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		if f.Type != zapcore.SkipType || f.Key != contextKey {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields)+2)
+			copy(out, fields[:i])
+		}
+		if ctx, ok := f.Interface.(context.Context); ok {
+			out = append(out, traceFields(ctx)...)
+		}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// traceFields returns the trace context fields of the span context in ctx, or
+// nil if it is not valid.
+func traceFields(ctx context.Context) []zapcore.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zapcore.Field{
+		zap.String(TraceIDKey, sc.TraceID().String()),
+		zap.String(SpanIDKey, sc.SpanID().String()),
+		zap.String(TraceFlagsKey, sc.TraceFlags().String()),
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunkzap provides a zapcore.Core wrapper adding the trace context
+// of a span to the log entries (e.g. to correlate logs and traces in Splunk
+// Observability Cloud).
+//
+// Log entries in zap do not carry a context. The context containing the span
+// has to be passed explicitly to the logger, either for a single entry with
+// the Context field, or for all the entries of a logger with With.
+package splunkzap // import "github.com/signalfx/splunk-otel-go/instrumentation/go.uber.org/zap/splunkzap"
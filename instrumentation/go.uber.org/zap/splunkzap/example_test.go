@@ -0,0 +1,41 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkzap_test
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/go.uber.org/zap/splunkzap"
+)
+
+func Example() {
+	logger, err := zap.NewProduction(zap.WrapCore(splunkzap.NewCore))
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = logger.Sync() }()
+
+	ctx, span := otel.Tracer("my-service").Start(context.Background(), "operation")
+	defer span.End()
+
+	// Add the trace context to a single entry.
+	logger.Info("handling operation", splunkzap.Context(ctx))
+
+	// Add the trace context to all entries of a child logger.
+	splunkzap.With(ctx, logger).Info("handling operation")
+}
@@ -0,0 +1,134 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/go.uber.org/zap/splunkzap"
+)
+
+func spanContext(t *testing.T) (context.Context, trace.SpanContext) {
+	t.Helper()
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer(t.Name()).Start(context.Background(), "span")
+	t.Cleanup(func() { span.End() })
+	return ctx, span.SpanContext()
+}
+
+func assertTraceFields(t *testing.T, sc trace.SpanContext, fields map[string]interface{}) {
+	t.Helper()
+	assert.Equal(t, sc.TraceID().String(), fields[splunkzap.TraceIDKey])
+	assert.Equal(t, sc.SpanID().String(), fields[splunkzap.SpanIDKey])
+	assert.Equal(t, "01", fields[splunkzap.TraceFlagsKey])
+}
+
+func assertNoTraceFields(t *testing.T, fields map[string]interface{}) {
+	t.Helper()
+	assert.NotContains(t, fields, splunkzap.TraceIDKey)
+	assert.NotContains(t, fields, splunkzap.SpanIDKey)
+	assert.NotContains(t, fields, splunkzap.TraceFlagsKey)
+}
+
+func TestCoreContextField(t *testing.T) {
+	obs, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(splunkzap.NewCore(obs))
+	ctx, sc := spanContext(t)
+
+	logger.Info("message", zap.String("key", "value"), splunkzap.Context(ctx))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "value", fields["key"])
+	assert.NotContains(t, fields, "splunkzap.context")
+	assertTraceFields(t, sc, fields)
+}
+
+func TestCoreContextFieldWith(t *testing.T) {
+	obs, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(splunkzap.NewCore(obs))
+	ctx, sc := spanContext(t)
+
+	logger.With(splunkzap.Context(ctx)).Info("message")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	assertTraceFields(t, sc, entries[0].ContextMap())
+}
+
+func TestCoreWithoutSpan(t *testing.T) {
+	obs, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(splunkzap.NewCore(obs))
+
+	logger.Info("no context")
+	logger.Info("no span", splunkzap.Context(context.Background()))
+	invalid := trace.ContextWithSpanContext(context.Background(), trace.SpanContext{})
+	logger.Info("invalid span context", zap.Int("key", 1), splunkzap.Context(invalid))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 3)
+	for _, e := range entries {
+		fields := e.ContextMap()
+		assert.NotContains(t, fields, "splunkzap.context", e.Message)
+		assertNoTraceFields(t, fields)
+	}
+	assert.Equal(t, int64(1), entries[2].ContextMap()["key"])
+}
+
+func TestCoreLevel(t *testing.T) {
+	obs, logs := observer.New(zapcore.WarnLevel)
+	logger := zap.New(splunkzap.NewCore(obs))
+	ctx, _ := spanContext(t)
+
+	logger.Info("message", splunkzap.Context(ctx))
+	assert.Zero(t, logs.Len())
+}
+
+func TestContextFieldWithoutCore(t *testing.T) {
+	obs, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(obs)
+	ctx, _ := spanContext(t)
+
+	logger.Info("message", splunkzap.Context(ctx))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	assertNoTraceFields(t, entries[0].ContextMap())
+}
+
+func TestWith(t *testing.T) {
+	obs, logs := observer.New(zapcore.InfoLevel)
+	// With does not require the core created by NewCore.
+	logger := zap.New(obs)
+	ctx, sc := spanContext(t)
+
+	splunkzap.With(ctx, logger).Info("message")
+	splunkzap.With(context.Background(), logger).Info("message")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assertTraceFields(t, sc, entries[0].ContextMap())
+	assertNoTraceFields(t, entries[1].ContextMap())
+}
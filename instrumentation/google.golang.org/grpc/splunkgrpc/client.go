@@ -0,0 +1,123 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkgrpc
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Attribute keys of the server span context read from the server-timing
+// trailer.
+const (
+	linkTraceIDKey = attribute.Key("link.traceId")
+	linkSpanIDKey  = attribute.Key("link.spanId")
+)
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that traces
+// the sent requests with the otelgrpc instrumentation. The server span
+// context returned in the server-timing trailer (e.g. by
+// UnaryServerInterceptor) is recorded as the link.traceId and link.spanId
+// attributes of the client span.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts...)
+	interceptor := otelgrpc.UnaryClientInterceptor(cfg.OTelOpts...)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		return interceptor(ctx, method, req, reply, cc, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, callOpts ...grpc.CallOption) error {
+			var trailer metadata.MD
+			err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Trailer(&trailer))...)
+			setLinkAttributes(trace.SpanFromContext(ctx), trailer)
+			return err
+		}, callOpts...)
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that traces
+// the client streams with the otelgrpc instrumentation. The server span
+// context returned in the server-timing trailer (e.g. by
+// StreamServerInterceptor) is recorded as the link.traceId and link.spanId
+// attributes of the client span once the stream is done.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts...)
+	interceptor := otelgrpc.StreamClientInterceptor(cfg.OTelOpts...)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return interceptor(ctx, desc, cc, method, func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+			cs, err := streamer(ctx, desc, cc, method, callOpts...)
+			if err != nil {
+				return cs, err
+			}
+			return &clientStream{ClientStream: cs, span: trace.SpanFromContext(ctx)}, nil
+		}, callOpts...)
+	}
+}
+
+// clientStream records the server span context from the trailer on the span
+// once the stream is done.
+type clientStream struct {
+	grpc.ClientStream
+
+	span trace.Span
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		// The trailer is available once RecvMsg returns a non-nil error
+		// (including io.EOF).
+		setLinkAttributes(s.span, s.Trailer())
+	}
+	return err
+}
+
+// setLinkAttributes records the span context of the server-timing trailer as
+// the link attributes of span.
+func setLinkAttributes(span trace.Span, trailer metadata.MD) {
+	if !span.IsRecording() {
+		return
+	}
+	for _, v := range trailer.Get(serverTimingKey) {
+		for _, metric := range strings.Split(v, ",") {
+			name, desc, ok := strings.Cut(strings.TrimSpace(metric), ";desc=")
+			if !ok || name != "traceparent" {
+				continue
+			}
+			parts := strings.Split(strings.Trim(desc, `"`), "-")
+			if len(parts) != 4 || parts[0] != "00" {
+				continue
+			}
+			traceID, err := trace.TraceIDFromHex(parts[1])
+			if err != nil {
+				continue
+			}
+			spanID, err := trace.SpanIDFromHex(parts[2])
+			if err != nil {
+				continue
+			}
+			span.SetAttributes(
+				linkTraceIDKey.String(traceID.String()),
+				linkSpanIDKey.String(spanID.String()),
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkgrpc
+
+import (
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+)
+
+// Environmental variables used for configuration.
+const (
+	envVarTraceResponseHeaderEnabled = "SPLUNK_TRACE_RESPONSE_HEADER_ENABLED" // Adds `server-timing` trailer to gRPC responses
+)
+
+// config represents the available configuration options.
+type config struct {
+	TraceResponseHeaderEnabled bool
+	OTelOpts                   []otelgrpc.Option
+}
+
+// newConfig creates a new config struct.
+func newConfig(opts ...Option) *config {
+	traceResponseHeaderEnabled := true
+	if v := os.Getenv(envVarTraceResponseHeaderEnabled); strings.EqualFold(v, "false") {
+		traceResponseHeaderEnabled = false
+	}
+
+	c := &config{
+		TraceResponseHeaderEnabled: traceResponseHeaderEnabled,
+	}
+	for _, o := range opts {
+		o.apply(c)
+	}
+	return c
+}
+
+// Option configures the instrumentation.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) {
+	fn(c)
+}
+
+// WithOTelOpts returns an Option that passes the otelgrpc options to the
+// wrapped otelgrpc interceptors.
+func WithOTelOpts(opts ...otelgrpc.Option) Option {
+	return optionFunc(func(c *config) {
+		c.OTelOpts = append(c.OTelOpts, opts...)
+	})
+}
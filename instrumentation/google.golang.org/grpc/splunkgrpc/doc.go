@@ -0,0 +1,19 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package splunkgrpc provides gRPC interceptors that add Splunk specific
+// instrumentation on top of the
+// go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc
+// instrumentation.
+package splunkgrpc // import "github.com/signalfx/splunk-otel-go/instrumentation/google.golang.org/grpc/splunkgrpc"
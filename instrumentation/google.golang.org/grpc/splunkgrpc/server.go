@@ -0,0 +1,90 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkgrpc
+
+import (
+	"context"
+	"encoding/hex"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// serverTimingKey is the metadata key of the trailer the server span context
+// is added to.
+const serverTimingKey = "server-timing"
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that traces
+// the served requests with the otelgrpc instrumentation and adds the server
+// span context in traceparent form
+// (https://www.w3.org/TR/trace-context/#traceparent-header) as server-timing
+// trailer to the response. The trailer can be disabled by setting the
+// SPLUNK_TRACE_RESPONSE_HEADER_ENABLED environment variable to "false".
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts...)
+	interceptor := otelgrpc.UnaryServerInterceptor(cfg.OTelOpts...)
+	if !cfg.TraceResponseHeaderEnabled {
+		return interceptor
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if md, ok := serverTimingMD(ctx); ok {
+				// An error means the transport stream is done, there is
+				// nothing to correlate.
+				_ = grpc.SetTrailer(ctx, md)
+			}
+			return handler(ctx, req)
+		})
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that traces
+// the served streams with the otelgrpc instrumentation and adds the server
+// span context in traceparent form
+// (https://www.w3.org/TR/trace-context/#traceparent-header) as server-timing
+// trailer to the response. The trailer can be disabled by setting the
+// SPLUNK_TRACE_RESPONSE_HEADER_ENABLED environment variable to "false".
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts...)
+	interceptor := otelgrpc.StreamServerInterceptor(cfg.OTelOpts...)
+	if !cfg.TraceResponseHeaderEnabled {
+		return interceptor
+	}
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return interceptor(srv, ss, info, func(srv interface{}, ss grpc.ServerStream) error {
+			if md, ok := serverTimingMD(ss.Context()); ok {
+				ss.SetTrailer(md)
+			}
+			return handler(srv, ss)
+		})
+	}
+}
+
+// serverTimingMD returns the server-timing metadata of the span context in
+// ctx.
+func serverTimingMD(ctx context.Context) (metadata.MD, bool) {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil, false
+	}
+	traceID := spanCtx.TraceID()
+	spanID := spanCtx.SpanID()
+	traceParent := "traceparent;desc=\"00-" + hex.EncodeToString(traceID[:]) + "-" + hex.EncodeToString(spanID[:]) + "-01\""
+	return metadata.Pairs(serverTimingKey, traceParent), true
+}
@@ -0,0 +1,132 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/signalfx/splunk-otel-go/instrumentation/google.golang.org/grpc/splunkgrpc"
+)
+
+// setup starts a health service instrumented with the server interceptors
+// and returns a client connection to it via bufconn. Streams of unknown
+// services are closed by the server right away.
+func setup(t *testing.T, serverSR, clientSR *tracetest.SpanRecorder) *grpc.ClientConn {
+	t.Helper()
+
+	serverOpt := splunkgrpc.WithOTelOpts(otelgrpc.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(serverSR))))
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(splunkgrpc.UnaryServerInterceptor(serverOpt)),
+		grpc.StreamInterceptor(splunkgrpc.StreamServerInterceptor(serverOpt)),
+		grpc.UnknownServiceHandler(func(interface{}, grpc.ServerStream) error { return nil }),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	clientOpt := splunkgrpc.WithOTelOpts(otelgrpc.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(clientSR))))
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(splunkgrpc.UnaryClientInterceptor(clientOpt)),
+		grpc.WithStreamInterceptor(splunkgrpc.StreamClientInterceptor(clientOpt)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func traceParent(span sdktrace.ReadOnlySpan) string {
+	sc := span.SpanContext()
+	return `traceparent;desc="00-` + sc.TraceID().String() + "-" + sc.SpanID().String() + `-01"`
+}
+
+func assertLinked(t *testing.T, client, server sdktrace.ReadOnlySpan) {
+	t.Helper()
+	assert.Contains(t, client.Attributes(), attribute.String("link.traceId", server.SpanContext().TraceID().String()))
+	assert.Contains(t, client.Attributes(), attribute.String("link.spanId", server.SpanContext().SpanID().String()))
+}
+
+func TestUnaryTrailer(t *testing.T) {
+	serverSR, clientSR := tracetest.NewSpanRecorder(), tracetest.NewSpanRecorder()
+	client := grpc_health_v1.NewHealthClient(setup(t, serverSR, clientSR))
+
+	var trailer metadata.MD
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+
+	require.Len(t, serverSR.Ended(), 1)
+	serverSpan := serverSR.Ended()[0]
+	assert.Equal(t, []string{traceParent(serverSpan)}, trailer.Get("server-timing"))
+
+	require.Len(t, clientSR.Ended(), 1)
+	assertLinked(t, clientSR.Ended()[0], serverSpan)
+}
+
+func TestStreamTrailer(t *testing.T) {
+	serverSR, clientSR := tracetest.NewSpanRecorder(), tracetest.NewSpanRecorder()
+	conn := setup(t, serverSR, clientSR)
+
+	desc := &grpc.StreamDesc{ServerStreams: true}
+	stream, err := conn.NewStream(context.Background(), desc, "/test.Service/Stream")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&grpc_health_v1.HealthCheckRequest{}))
+	require.NoError(t, stream.CloseSend())
+	err = stream.RecvMsg(&grpc_health_v1.HealthCheckResponse{})
+	require.ErrorIs(t, err, io.EOF)
+
+	require.Eventually(t, func() bool { return len(serverSR.Ended()) == 1 }, time.Second, 10*time.Millisecond)
+	serverSpan := serverSR.Ended()[0]
+
+	trailer := stream.Trailer()
+	assert.Equal(t, []string{traceParent(serverSpan)}, trailer.Get("server-timing"))
+	require.Len(t, clientSR.Ended(), 1)
+	assertLinked(t, clientSR.Ended()[0], serverSpan)
+}
+
+func TestTrailerDisabled(t *testing.T) {
+	t.Setenv("SPLUNK_TRACE_RESPONSE_HEADER_ENABLED", "false")
+	serverSR, clientSR := tracetest.NewSpanRecorder(), tracetest.NewSpanRecorder()
+	client := grpc_health_v1.NewHealthClient(setup(t, serverSR, clientSR))
+
+	var trailer metadata.MD
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+
+	assert.Empty(t, trailer.Get("server-timing"))
+	require.Len(t, serverSR.Ended(), 1)
+	require.Len(t, clientSR.Ended(), 1)
+	for _, kv := range clientSR.Ended()[0].Attributes() {
+		assert.NotEqual(t, attribute.Key("link.traceId"), kv.Key)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// now returns the clock passed with WithClock, or time.Now.
+func (c *config) now() func() time.Time {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return time.Now
+}
+
+// clockTracerProvider is a TracerProvider starting the spans with the
+// resolved TracerProvider, as otelhttp does if none is configured (i.e. the
+// one of the parent span, or the global one), and setting their start, end,
+// and event timestamps with now.
+type clockTracerProvider struct {
+	now func() time.Time
+}
+
+var _ trace.TracerProvider = clockTracerProvider{}
+
+// Tracer returns a Tracer setting the span timestamps with the clock.
+func (p clockTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return clockTracer{name: name, opts: opts, now: p.now}
+}
+
+type clockTracer struct {
+	name string
+	opts []trace.TracerOption
+	now  func() time.Time
+}
+
+// Start starts a span with the start timestamp set with the clock.
+func (t clockTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tp := otel.GetTracerProvider()
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		tp = span.TracerProvider()
+	}
+	opts = append(opts[:len(opts):len(opts)], trace.WithTimestamp(t.now()))
+	ctx, span := tp.Tracer(t.name, t.opts...).Start(ctx, name, opts...)
+	s := clockSpan{Span: span, now: t.now}
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+// clockSpan is a Span with the end and event timestamps set with the clock.
+type clockSpan struct {
+	trace.Span
+
+	now func() time.Time
+}
+
+// End ends the span with the end timestamp set with the clock.
+func (s clockSpan) End(options ...trace.SpanEndOption) {
+	options = append(options[:len(options):len(options)], trace.WithTimestamp(s.now()))
+	s.Span.End(options...)
+}
+
+// AddEvent adds an event with the timestamp set with the clock.
+func (s clockSpan) AddEvent(name string, options ...trace.EventOption) {
+	options = append(options[:len(options):len(options)], trace.WithTimestamp(s.now()))
+	s.Span.AddEvent(name, options...)
+}
+
+// RecordError records an error event with the timestamp set with the clock.
+func (s clockSpan) RecordError(err error, options ...trace.EventOption) {
+	options = append(options[:len(options):len(options)], trace.WithTimestamp(s.now()))
+	s.Span.RecordError(err, options...)
+}
@@ -0,0 +1,158 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceapi "go.opentelemetry.io/otel/trace"
+)
+
+var epoch = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeClock returns a clock advancing by step each time it is read.
+func fakeClock(step time.Duration) func() time.Time {
+	var (
+		mu  sync.Mutex
+		now = epoch
+	)
+	return func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		t := now
+		now = now.Add(step)
+		return t
+	}
+}
+
+// setTracerProvider sets tp as the global TracerProvider for the duration of
+// the test.
+func setTracerProvider(t *testing.T, tp traceapi.TracerProvider) {
+	t.Helper()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+}
+
+func TestWithClockSpanDuration(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	setTracerProvider(t, trace.NewTracerProvider(trace.WithSpanProcessor(sr)))
+
+	handler := NewHandlerWithNamer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceapi.SpanFromContext(r.Context()).AddEvent("handled")
+	}), defaultName, WithClock(fakeClock(time.Second)))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	ended := sr.Ended()
+	require.Len(t, ended, 1)
+	span := ended[0]
+	assert.Equal(t, epoch, span.StartTime())
+	assert.Equal(t, epoch.Add(2*time.Second), span.EndTime())
+	if assert.Len(t, span.Events(), 1) {
+		assert.Equal(t, epoch.Add(time.Second), span.Events()[0].Time)
+	}
+}
+
+func TestWithClockTransport(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	setTracerProvider(t, trace.NewTracerProvider(trace.WithSpanProcessor(sr)))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, WithClock(fakeClock(time.Minute)))}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	ended := sr.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, time.Minute, ended[0].EndTime().Sub(ended[0].StartTime()))
+}
+
+func TestWithClockTracerProviderOption(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	handler := NewHandlerWithNamer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), defaultName,
+		WithClock(fakeClock(time.Second)),
+		WithOTelOpts(otelhttp.WithTracerProvider(tp)),
+	)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	ended := sr.Ended()
+	require.Len(t, ended, 1)
+	assert.NotEqual(t, epoch, ended[0].StartTime(), "passed TracerProvider must set the timestamps")
+}
+
+func TestWithClockMetrics(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+	handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		WithMetrics(mp), WithClock(fakeClock(250*time.Millisecond)))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != serverDurationName {
+			continue
+		}
+		data, ok := m.Data.(metricdata.Histogram[float64])
+		require.True(t, ok)
+		require.Len(t, data.DataPoints, 1)
+		assert.Equal(t, 250.0, data.DataPoints[0].Sum)
+		return
+	}
+	t.Fatalf("%s not recorded", serverDurationName)
+}
+
+func TestClockSpanRecordError(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	setTracerProvider(t, trace.NewTracerProvider(trace.WithSpanProcessor(sr)))
+
+	tracer := clockTracerProvider{now: fakeClock(time.Second)}.Tracer(t.Name())
+	ctx, span := tracer.Start(context.Background(), "parent")
+	assert.IsType(t, clockSpan{}, traceapi.SpanFromContext(ctx), "context must hold the span")
+	span.RecordError(errors.New("failed"))
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	span.End()
+
+	ended := sr.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, ended[1].SpanContext().SpanID(), ended[0].Parent().SpanID())
+	assert.Equal(t, epoch.Add(2*time.Second), ended[0].StartTime())
+	parent := ended[1]
+	assert.Equal(t, epoch, parent.StartTime())
+	assert.Equal(t, epoch.Add(4*time.Second), parent.EndTime())
+	if assert.Len(t, parent.Events(), 1) {
+		assert.Equal(t, epoch.Add(time.Second), parent.Events()[0].Time)
+	}
+}
@@ -15,8 +15,16 @@
 package splunkhttp
 
 import (
+	"net/http"
 	"os"
+	"regexp"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // Environmental variables used for configuration.
@@ -24,19 +32,398 @@ const (
 	envVarTraceResponseHeaderEnabled = "SPLUNK_TRACE_RESPONSE_HEADER_ENABLED" // Adds `Server-Timing` header to HTTP responses
 )
 
+// defaultServerTimingHeader is the default name of the header the trace
+// context is written to and read from.
+const defaultServerTimingHeader = "Server-Timing"
+
 // config represents the available configuration options.
 type config struct {
 	TraceResponseHeaderEnabled bool
+	ServerTimingHeader         string
+	TraceResponseEnabled       bool
+	Filters                    []func(*http.Request) bool
+	RouteFunc                  func(*http.Request) string
+	CapturedRequestHeaders     []string
+	CapturedResponseHeaders    []string
+	SensitiveHeadersCaptured   bool
+	RecoverPanic               bool
+	Repanic                    bool
+	BodySizeCaptured           bool
+	LowCardinalityNamer        func(*http.Request) string
+	MeterProvider              metric.MeterProvider
+	SpanStatusFunc             func(int) codes.Code
+	ForceSampleHeader          string
+	ForceSampleTrusted         func(*http.Request) bool
+	RUMSessionIDKey            string
+	SyntheticDetector          func(*http.Request) bool
+	Clock                      func() time.Time
+	SanitizeURL                bool
+	SanitizeURLKeep            map[string]struct{}
+	ProfilerLabels             bool
+	OTelOpts                   []otelhttp.Option
 }
 
 // newConfig creates a new config struct.
-func newConfig() *config {
+func newConfig(opts ...Option) *config {
 	traceResponseHeaderEnabled := true
 	if v := os.Getenv(envVarTraceResponseHeaderEnabled); strings.EqualFold(v, "false") {
 		traceResponseHeaderEnabled = false
 	}
 
-	return &config{
+	c := &config{
 		TraceResponseHeaderEnabled: traceResponseHeaderEnabled,
+		ServerTimingHeader:         defaultServerTimingHeader,
+	}
+	for _, o := range opts {
+		o.apply(c)
+	}
+	return c
+}
+
+// Option configures the instrumentation.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (fn optionFunc) apply(c *config) {
+	fn(c)
+}
+
+// WithOTelOpts returns an Option that passes the otelhttp options to the
+// otelhttp instrumentation created by this package (i.e. the otelhttp.Handler
+// created by NewHandlerWithNamer and the otelhttp.Transport created by
+// NewTransport).
+func WithOTelOpts(opts ...otelhttp.Option) Option {
+	return optionFunc(func(c *config) {
+		c.OTelOpts = append(c.OTelOpts, opts...)
+	})
+}
+
+// WithServerTimingHeader returns an Option that sets the name of the header
+// the trace context is added to by NewHandler and read from by NewTransport.
+// The header value keeps the Server-Timing traceparent format, and the
+// Access-Control-Expose-Headers response header lists the passed name. This
+// is useful when proxies strip or rewrite the Server-Timing header. An empty
+// name is ignored.
+//
+// By default, the Server-Timing header is used.
+func WithServerTimingHeader(name string) Option {
+	return optionFunc(func(c *config) {
+		if name != "" {
+			c.ServerTimingHeader = http.CanonicalHeaderKey(name)
+		}
+	})
+}
+
+// WithTraceResponseHeader returns an Option that sets whether NewHandler adds
+// the trace context as W3C traceresponse header
+// (https://www.w3.org/TR/trace-context-2/#traceresponse-header) to the HTTP
+// response, in addition to the Server-Timing header. The header is also
+// listed in the Access-Control-Expose-Headers response header.
+//
+// By default, the traceresponse header is not added.
+func WithTraceResponseHeader(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.TraceResponseEnabled = enabled
+	})
+}
+
+// WithFilter returns an Option that excludes the requests for which f returns
+// false from the instrumentation. Multiple filters can be used, a request is
+// instrumented only if all of them return true.
+//
+// Excluded requests are passed to the wrapped handler without any
+// processing (e.g. no Server-Timing header is added). NewHandlerWithNamer and
+// NewServeMuxHandler apply the filters before the server span is started, so
+// the excluded requests are not traced. NewHandler does not start the server
+// spans: pass the same filter to the otelhttp handler wrapping it with
+// otelhttp.WithFilter to not trace the requests. The filter is passed to the
+// otelhttp.Transport created by NewTransport.
+func WithFilter(f func(*http.Request) bool) Option {
+	return optionFunc(func(c *config) {
+		if f != nil {
+			c.Filters = append(c.Filters, f)
+			c.OTelOpts = append(c.OTelOpts, otelhttp.WithFilter(f))
+		}
+	})
+}
+
+// WithRouteFunc returns an Option that sets the http.route attribute of the
+// server span to the route returned by fn for the request. The function is
+// called after the wrapped handler returns, so NewHandler has to be used as
+// middleware of the router for the matched route to be available. An empty
+// route is not recorded.
+//
+// Adapters for routers are provided by the router instrumentation packages
+// (e.g. RoutePattern of
+// github.com/signalfx/splunk-otel-go/instrumentation/github.com/go-chi/chi/splunkchi
+// and
+// github.com/signalfx/splunk-otel-go/instrumentation/github.com/gorilla/mux/splunkmux).
+func WithRouteFunc(fn func(*http.Request) string) Option {
+	return optionFunc(func(c *config) {
+		c.RouteFunc = fn
+	})
+}
+
+// WithCapturedRequestHeaders returns an Option that records the values of the
+// passed request headers as attributes of the server span by NewHandler. Each
+// header is recorded as http.request.header.<name>, where <name> is the
+// lowercased header name with "-" replaced by "_". Multiple values are joined
+// with ",". Headers missing from the request are not recorded.
+//
+// Sensitive headers are ignored unless WithSensitiveHeadersCaptured is used.
+func WithCapturedRequestHeaders(headers []string) Option {
+	return optionFunc(func(c *config) {
+		c.CapturedRequestHeaders = append(c.CapturedRequestHeaders, headers...)
+	})
+}
+
+// WithCapturedResponseHeaders returns an Option that records the values of
+// the passed response headers as attributes of the server span by
+// NewHandler. Each header is recorded as http.response.header.<name>, where
+// <name> is the lowercased header name with "-" replaced by "_". Multiple
+// values are joined with ",". The headers are captured when the response
+// header is written, or when the handler returns if no response was written.
+//
+// Sensitive headers are ignored unless WithSensitiveHeadersCaptured is used.
+func WithCapturedResponseHeaders(headers []string) Option {
+	return optionFunc(func(c *config) {
+		c.CapturedResponseHeaders = append(c.CapturedResponseHeaders, headers...)
+	})
+}
+
+// WithSensitiveHeadersCaptured returns an Option that allows capturing the
+// sensitive headers (Authorization, Proxy-Authorization, Cookie, Set-Cookie,
+// and X-Sf-Token) passed to WithCapturedRequestHeaders and
+// WithCapturedResponseHeaders. Be aware that the captured values (e.g.
+// credentials) are exported with the span.
+func WithSensitiveHeadersCaptured(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.SensitiveHeadersCaptured = enabled
+	})
+}
+
+// WithRecoverPanic returns an Option that recovers panics of the handler
+// wrapped by NewHandler. The panic is recorded as an exception event with the
+// stack trace on the server span, the span status is set to Error, and a 500
+// Internal Server Error response status is written if no response was written
+// yet. If repanic is true, the panic is propagated once recorded, otherwise it
+// is swallowed.
+//
+// Panics are not recovered by default.
+func WithRecoverPanic(repanic bool) Option {
+	return optionFunc(func(c *config) {
+		c.RecoverPanic = true
+		c.Repanic = repanic
+	})
+}
+
+// WithBodySizeCaptured returns an Option that records the number of bytes
+// read from the request body and written to the response body by the handler
+// wrapped by NewHandler. The totals are recorded as the
+// http.request_content_length and http.response_content_length attributes of
+// the server span once the handler returns, regardless of the Content-Length
+// header (e.g. for chunked transfers). Only the bytes the handler read are
+// counted if the request body is partially read. The bodies are not buffered.
+func WithBodySizeCaptured(enabled bool) Option {
+	return optionFunc(func(c *config) {
+		c.BodySizeCaptured = enabled
+	})
+}
+
+// WithLowCardinalityNames returns an Option that renames the server span by
+// NewHandler once the handler returns to the request method followed by the
+// URL path with its identifier segments collapsed to the "{id}" placeholder
+// (e.g. "GET /users/{id}"). It is useful to prevent high cardinality span
+// names.
+//
+// A path segment is collapsed if any of the passed patterns matches the whole
+// segment. If no pattern is passed, numeric and UUID segments are collapsed.
+// See LowCardinalityNamer to use it with NewHandlerWithNamer.
+func WithLowCardinalityNames(patterns ...*regexp.Regexp) Option {
+	return optionFunc(func(c *config) {
+		c.LowCardinalityNamer = LowCardinalityNamer(patterns...)
+	})
+}
+
+// WithMetrics returns an Option that records the HTTP server metrics of the
+// requests handled by NewHandler using the passed MeterProvider. If mp is
+// nil, the global MeterProvider is used.
+//
+// The http.server.duration (in milliseconds), http.server.request.size, and
+// http.server.response.size (in bytes) histograms are recorded with the
+// http.method, http.scheme, http.flavor, and http.status_code attributes
+// defined by the OpenTelemetry semantic conventions. To keep a low
+// cardinality, the URL path is not recorded, methods not defined by the HTTP
+// specification are recorded as "_OTHER", and the http.route attribute is
+// only recorded if a route is returned by the function passed to
+// WithRouteFunc (e.g. the pattern matched by NewServeMuxHandler). The body
+// sizes are the number of bytes read and written by the handler.
+//
+// The Splunk distribution (github.com/signalfx/splunk-otel-go/distro)
+// configures the bucket boundaries of these histograms. By default, the
+// metrics are not recorded.
+func WithMetrics(mp metric.MeterProvider) Option {
+	return optionFunc(func(c *config) {
+		c.MeterProvider = mp
+		if c.MeterProvider == nil {
+			c.MeterProvider = otel.GetMeterProvider()
+		}
+	})
+}
+
+// WithSpanStatusFromResponse returns an Option that sets the status of the
+// server span to the code returned by fn for the HTTP response status code
+// once the handler wrapped by NewHandler returns (e.g. to mark the 429 Too
+// Many Requests responses as errors). A nil fn is ignored.
+//
+// The status set by the otelhttp.Handler (i.e. Error for 5xx responses)
+// takes precedence over Unset. Return codes.Ok for the responses that must
+// not mark the span as error.
+//
+// By default, only the 5xx responses mark the server span as error.
+func WithSpanStatusFromResponse(fn func(status int) codes.Code) Option {
+	return optionFunc(func(c *config) {
+		if fn != nil {
+			c.SpanStatusFunc = fn
+		}
+	})
+}
+
+// WithForceSampleHeader returns an Option that force-samples the requests
+// with the header set to "true" (e.g. "X-Splunk-Force-Sample: true" to debug
+// a request in production) if trusted returns true for the request. The
+// DefaultForceSampleHeader is used if header is empty.
+//
+// The requests are marked in their context (see ForceSampled) before the
+// server span is started, and the spans are only sampled if the Sampler
+// returned by NewForceSampler of the Splunk distribution
+// (github.com/signalfx/splunk-otel-go/distro) is registered with the
+// TracerProvider. The span is then recorded and sampled regardless of the
+// wrapped sampler, even if the trace context propagated by the caller is not
+// sampled. As the spans are started by the otelhttp.Handler, the option is
+// only used by NewHandlerWithNamer and NewServeMuxHandler, which create it.
+//
+// Anyone able to send the header can force the sampling of their requests,
+// increasing the exported telemetry volume. The header is only honored for
+// the requests for which trusted returns true, e.g. the requests from an
+// internal network or authenticated as an operator. The option is ignored if
+// trusted is nil: the header is never trusted by default.
+func WithForceSampleHeader(header string, trusted func(*http.Request) bool) Option {
+	return optionFunc(func(c *config) {
+		if trusted == nil {
+			return
+		}
+		if header == "" {
+			header = DefaultForceSampleHeader
+		}
+		c.ForceSampleHeader = header
+		c.ForceSampleTrusted = trusted
+	})
+}
+
+// WithRUMSessionID returns an Option that records the Splunk RUM session ID
+// sent by the browser agent (e.g. splunk-otel-js-web) as the
+// splunk.rum.session_id attribute of the server span by NewHandler. It
+// connects the frontend and backend traces of a user session.
+//
+// The session ID is read from the request header with the passed key, or
+// from the baggage member with the key if the header is missing. The
+// DefaultRUMSessionIDKey is used if key is empty. By default, the session ID
+// is not recorded.
+func WithRUMSessionID(key string) Option {
+	return optionFunc(func(c *config) {
+		if key == "" {
+			key = DefaultRUMSessionIDKey
+		}
+		c.RUMSessionIDKey = key
+	})
+}
+
+// WithSyntheticDetector returns an Option that records the synthetic=true
+// attribute on the server span of the requests for which detect returns true
+// (e.g. monitoring probes and health checks), to tell them apart from the
+// user traffic. SyntheticUserAgentDetector is used if detect is nil.
+//
+// NewHandlerWithNamer and NewServeMuxHandler also mark the synthetic requests
+// in their context (see Synthetic) before the server span is started, so they
+// are sampled by the synthetic sampler of the Sampler returned by
+// NewSyntheticSampler of the Splunk distribution
+// (github.com/signalfx/splunk-otel-go/distro), if it is registered with the
+// TracerProvider. By default, synthetic requests are not detected.
+func WithSyntheticDetector(detect func(*http.Request) bool) Option {
+	return optionFunc(func(c *config) {
+		if detect == nil {
+			detect = SyntheticUserAgentDetector()
+		}
+		c.SyntheticDetector = detect
+	})
+}
+
+// WithSanitizeURL returns an Option that redacts the query parameter values
+// of the recorded URLs, as the query strings frequently contain tokens and
+// personally identifiable information. The values of all the parameters,
+// except the ones named in keep, are replaced by "REDACTED" (e.g.
+// "/search?q=REDACTED&page=2" if "page" is kept).
+//
+// NewHandler records the sanitized request target as the http.target
+// attribute of the server span, and NewTransport records the sanitized URL
+// as the http.url attribute of the client span in place of the one recorded
+// by the otelhttp.Transport. By default, the query of the URL recorded by
+// the otelhttp.Transport is not redacted.
+func WithSanitizeURL(keep ...string) Option {
+	return optionFunc(func(c *config) {
+		c.SanitizeURL = true
+		if c.SanitizeURLKeep == nil {
+			c.SanitizeURLKeep = make(map[string]struct{}, len(keep))
+		}
+		for _, k := range keep {
+			c.SanitizeURLKeep[k] = struct{}{}
+		}
+	})
+}
+
+// WithProfilerLabels returns an Option that sets the trace_id and span_id
+// runtime/pprof labels of the server span for the duration of the handling
+// of each request, so that the samples of the CPU profiles recorded with the
+// runtime/pprof package (e.g. go tool pprof -tagfocus trace_id=<trace ID>)
+// can be grouped by trace. The labels are also inherited by the goroutines
+// started by the handler. They are not set for the requests that are not
+// traced. By default, no labels are set.
+func WithProfilerLabels() Option {
+	return optionFunc(func(c *config) {
+		c.ProfilerLabels = true
+	})
+}
+
+// WithClock returns an Option that sets the clock used for the timings of the
+// instrumentation, e.g. to get deterministic durations in tests. The clock is
+// used for the durations recorded with the metrics of NewHandler, and for the
+// start, end, and event timestamps of the spans started by
+// NewHandlerWithNamer, NewServeMuxHandler, and NewTransport, unless a
+// TracerProvider is passed with otelhttp.WithTracerProvider (the spans are
+// then started with the TracerProvider of the parent span or the global
+// one). A nil clock is ignored.
+//
+// By default, time.Now is used and the span timestamps are set by the
+// TracerProvider.
+func WithClock(now func() time.Time) Option {
+	return optionFunc(func(c *config) {
+		if now != nil {
+			c.Clock = now
+		}
+	})
+}
+
+// otelOpts returns the otelhttp options passed with WithOTelOpts, preceded by
+// the TracerProvider using the clock passed with WithClock, if any.
+func (c *config) otelOpts() []otelhttp.Option {
+	if c.Clock == nil {
+		return c.OTelOpts
 	}
+	opts := make([]otelhttp.Option, 0, len(c.OTelOpts)+1)
+	opts = append(opts, otelhttp.WithTracerProvider(clockTracerProvider{now: c.Clock}))
+	return append(opts, c.OTelOpts...)
 }
@@ -13,5 +13,5 @@
 // limitations under the License.
 
 // Package splunkhttp provides functions that add additional Splunk specific instrumentation
-// by wrapping existing handlers.
+// by wrapping existing handlers and transports.
 package splunkhttp // import "github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp"
@@ -0,0 +1,50 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// DefaultForceSampleHeader is the header of the requests to force-sample
+// used by WithForceSampleHeader if no header is passed.
+const DefaultForceSampleHeader = "X-Splunk-Force-Sample"
+
+// forceSampleKey is the context key marking the requests to force-sample.
+type forceSampleKey struct{}
+
+// forceSampleMiddleware wraps the passed handler, functioning like
+// middleware. It marks the context of the requests from trusted callers with
+// the header set to "true" before the span is started by the wrapped
+// handler.
+func forceSampleMiddleware(handler http.Handler, header string, trusted func(*http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get(header), "true") && trusted(r) {
+			r = r.WithContext(context.WithValue(r.Context(), forceSampleKey{}, true))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ForceSampled returns if the request handled in ctx is marked to be
+// force-sampled by a handler configured with WithForceSampleHeader. Use it
+// with the sampler of the Splunk distribution (distro.NewForceSampler) to
+// sample the spans of the marked requests.
+func ForceSampled(ctx context.Context) bool {
+	v, _ := ctx.Value(forceSampleKey{}).(bool)
+	return v
+}
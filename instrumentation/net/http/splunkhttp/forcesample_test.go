@@ -0,0 +1,157 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceapi "go.opentelemetry.io/otel/trace"
+)
+
+func defaultName(*http.Request) string { return "" }
+
+// forceSampler samples the spans of the requests marked by the handler, as
+// the sampler of the Splunk distribution does, and otherwise delegates to
+// the wrapped Sampler.
+type forceSampler struct {
+	trace.Sampler
+}
+
+func (s forceSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if ForceSampled(p.ParentContext) {
+		return trace.SamplingResult{
+			Decision:   trace.RecordAndSample,
+			Tracestate: traceapi.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.Sampler.ShouldSample(p)
+}
+
+func TestWithForceSampleHeader(t *testing.T) {
+	trusted := func(r *http.Request) bool { return r.Header.Get("X-Operator") == "alice" }
+
+	testCases := []struct {
+		desc    string
+		opts    []Option
+		headers map[string]string
+		want    bool
+	}{
+		{
+			desc:    "trusted",
+			opts:    []Option{WithForceSampleHeader("", trusted)},
+			headers: map[string]string{"X-Splunk-Force-Sample": "TRUE", "X-Operator": "alice"},
+			want:    true,
+		},
+		{
+			desc:    "untrusted",
+			opts:    []Option{WithForceSampleHeader("", trusted)},
+			headers: map[string]string{"X-Splunk-Force-Sample": "true"},
+			want:    false,
+		},
+		{
+			desc:    "not true",
+			opts:    []Option{WithForceSampleHeader("", trusted)},
+			headers: map[string]string{"X-Splunk-Force-Sample": "yes", "X-Operator": "alice"},
+			want:    false,
+		},
+		{
+			desc:    "custom header",
+			opts:    []Option{WithForceSampleHeader("X-Debug", trusted)},
+			headers: map[string]string{"X-Debug": "true", "X-Operator": "alice"},
+			want:    true,
+		},
+		{
+			desc:    "nil trusted",
+			opts:    []Option{WithForceSampleHeader("", nil)},
+			headers: map[string]string{"X-Splunk-Force-Sample": "true"},
+			want:    false,
+		},
+		{
+			desc:    "default",
+			headers: map[string]string{"X-Splunk-Force-Sample": "true"},
+			want:    false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := trace.NewTracerProvider(
+				trace.WithSampler(forceSampler{trace.NeverSample()}),
+				trace.WithSpanProcessor(sr),
+			)
+			opts := append([]Option{WithOTelOpts(otelhttp.WithTracerProvider(tp))}, tc.opts...)
+			handler := NewHandlerWithNamer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), defaultName, opts...)
+
+			r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if tc.want {
+				assert.Len(t, sr.Ended(), 1, "request must be sampled")
+			} else {
+				assert.Empty(t, sr.Ended(), "request must not be sampled")
+			}
+		})
+	}
+}
+
+func TestWithForceSampleHeaderUnsampledParent(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(
+		trace.WithSampler(forceSampler{trace.ParentBased(trace.AlwaysSample())}),
+		trace.WithSpanProcessor(sr),
+	)
+	handler := NewHandlerWithNamer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), defaultName,
+		WithOTelOpts(
+			otelhttp.WithTracerProvider(tp),
+			otelhttp.WithPropagators(propagation.TraceContext{}),
+		),
+		WithForceSampleHeader("", func(*http.Request) bool { return true }),
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	r.Header.Set("traceparent", "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-00")
+	r.Header.Set("X-Splunk-Force-Sample", "true")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if assert.Len(t, sr.Ended(), 1, "request must be sampled") {
+		sc := sr.Ended()[0].SpanContext()
+		assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", sc.TraceID().String(), "trace must be continued")
+		assert.True(t, sc.IsSampled())
+	}
+}
+
+func TestForceSampled(t *testing.T) {
+	assert.False(t, ForceSampled(context.Background()))
+
+	var got bool
+	handler := forceSampleMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ForceSampled(r.Context())
+	}), DefaultForceSampleHeader, func(*http.Request) bool { return true })
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	r.Header.Set(DefaultForceSampleHeader, "true")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, got, "request must be marked")
+}
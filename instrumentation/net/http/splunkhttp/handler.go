@@ -15,39 +15,561 @@
 package splunkhttp
 
 import (
+	"bufio"
+	"context"
 	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/felixge/httpsnoop"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// sensitiveHeaders are the headers that are not captured unless
+// WithSensitiveHeadersCaptured is used.
+var sensitiveHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"Cookie":              {},
+	"Set-Cookie":          {},
+	"X-Sf-Token":          {},
+}
+
 // NewHandler wraps the passed handler in a span named after the operation and with any provided Options.
 // This will also enable all the Splunk specific defaults for HTTP tracing.
-func NewHandler(handler http.Handler) http.Handler {
-	cfg := newConfig()
+func NewHandler(handler http.Handler, opts ...Option) http.Handler {
+	cfg := newConfig(opts...)
+	next := handler
+	if cfg.RecoverPanic {
+		handler = recoverMiddleware(handler, cfg.Repanic)
+	}
+	// The trace context headers are added before the response headers are
+	// captured.
+	var serverTimingName string
 	if cfg.TraceResponseHeaderEnabled {
-		handler = traceResponseHeaderMiddleware(handler)
+		serverTimingName = cfg.ServerTimingHeader
+	}
+	if serverTimingName != "" || cfg.TraceResponseEnabled {
+		handler = traceResponseHeaderMiddleware(handler, serverTimingName, cfg.TraceResponseEnabled)
+	}
+	if cfg.BodySizeCaptured {
+		handler = bodySizeMiddleware(handler)
+	}
+	if cfg.SpanStatusFunc != nil {
+		handler = spanStatusMiddleware(handler, cfg.SpanStatusFunc)
+	}
+	if cfg.LowCardinalityNamer != nil {
+		handler = renameMiddleware(handler, cfg.LowCardinalityNamer)
+	}
+	if cfg.RouteFunc != nil {
+		handler = routeMiddleware(handler, cfg.RouteFunc)
+	}
+	if headers := capturedHeaders(cfg.CapturedRequestHeaders, cfg.SensitiveHeadersCaptured); len(headers) > 0 {
+		handler = captureRequestHeadersMiddleware(handler, headers)
+	}
+	if cfg.RUMSessionIDKey != "" {
+		handler = rumSessionMiddleware(handler, cfg.RUMSessionIDKey)
+	}
+	if cfg.SyntheticDetector != nil {
+		handler = syntheticMiddleware(handler, cfg.SyntheticDetector)
+	}
+	if cfg.SanitizeURL {
+		handler = sanitizeURLMiddleware(handler, cfg.SanitizeURLKeep)
+	}
+	if headers := capturedHeaders(cfg.CapturedResponseHeaders, cfg.SensitiveHeadersCaptured); len(headers) > 0 {
+		handler = captureResponseHeadersMiddleware(handler, headers)
+	}
+	if cfg.MeterProvider != nil {
+		handler = metricsMiddleware(handler, cfg.MeterProvider, cfg.RouteFunc, cfg.now())
+	}
+	if cfg.ProfilerLabels {
+		handler = profilerLabelsMiddleware(handler)
+	}
+	if len(cfg.Filters) > 0 {
+		handler = filterMiddleware(handler, next, cfg.Filters)
+	}
+	return handler
+}
+
+// recoverMiddleware wraps the passed handler, functioning like middleware.
+// It recovers a panic of the handler, records it on the span in the request
+// context, and writes a 500 response status if no response was written yet.
+// The panic is propagated once recorded if repanic is true.
+func recoverMiddleware(handler http.Handler, repanic bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var wroteHeader bool
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					wroteHeader = true
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					wroteHeader = true
+					return next(b)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					wroteHeader = true
+					return next(src)
+				}
+			},
+		})
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler { //nolint:errorlint,goerr113 // The sentinel value is panicked as is.
+				// The server aborts the response silently.
+				panic(rec)
+			}
+
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+			span := trace.SpanFromContext(r.Context())
+			span.RecordError(err, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, err.Error())
+
+			if !wroteHeader {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			if repanic {
+				panic(rec)
+			}
+		}()
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// bodySizeMiddleware wraps the passed handler, functioning like middleware.
+// It counts the bytes of the request body read by the handler and of the
+// response body written by the handler, and records them on the span in the
+// request context once the handler returns.
+func bodySizeMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var read, wrote int64
+		if r.Body != nil && r.Body != http.NoBody {
+			r2 := *r
+			r2.Body = &countingReadCloser{ReadCloser: r.Body, n: &read}
+			r = &r2
+		}
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					n, err := next(b)
+					atomic.AddInt64(&wrote, int64(n))
+					return n, err
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					n, err := next(src)
+					atomic.AddInt64(&wrote, n)
+					return n, err
+				}
+			},
+		})
+
+		handler.ServeHTTP(w, r)
+
+		trace.SpanFromContext(r.Context()).SetAttributes(
+			semconv.HTTPRequestContentLength(int(atomic.LoadInt64(&read))),
+			semconv.HTTPResponseContentLength(int(atomic.LoadInt64(&wrote))),
+		)
+	})
+}
+
+// countingReadCloser counts the bytes read from the wrapped io.ReadCloser.
+type countingReadCloser struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+// NewHandlerWithNamer wraps the passed handler with an otelhttp.Handler and
+// the Splunk specific instrumentation of NewHandler. The span of each request
+// is named by namer. The name is computed when the span starts, and once more
+// when the handler returns to use the information set while handling the
+// request (e.g. the route matched by a router). If namer returns an empty
+// string, the span is named "HTTP " followed by the request method.
+//
+// The otelhttp options passed with WithOTelOpts are used to create the
+// otelhttp.Handler. The requests excluded with WithFilter are passed to the
+// wrapped handler before any span is started.
+func NewHandlerWithNamer(handler http.Handler, namer func(*http.Request) string, opts ...Option) http.Handler {
+	cfg := newConfig(opts...)
+	next := handler
+	handler = NewHandler(handler, opts...)
+	handler = renameMiddleware(handler, namer)
+
+	otelOpts := append([]otelhttp.Option{
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if name := namer(r); name != "" {
+				return name
+			}
+			return "HTTP " + r.Method
+		}),
+	}, cfg.otelOpts()...)
+	handler = otelhttp.NewHandler(handler, "", otelOpts...)
+	if cfg.ForceSampleTrusted != nil {
+		// The request has to be marked before the span is started.
+		handler = forceSampleMiddleware(handler, cfg.ForceSampleHeader, cfg.ForceSampleTrusted)
+	}
+	if cfg.SyntheticDetector != nil {
+		// The request has to be marked before the span is started.
+		handler = markSyntheticMiddleware(handler, cfg.SyntheticDetector)
+	}
+	if len(cfg.Filters) > 0 {
+		// The excluded requests must not start a span.
+		handler = filterMiddleware(handler, next, cfg.Filters)
 	}
 	return handler
 }
 
+// renameMiddleware wraps the passed handler, functioning like middleware.
+// It renames the span in the request context with the name returned by namer
+// once the handler returns, unless it is empty.
+func renameMiddleware(handler http.Handler, namer func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			if name := namer(r); name != "" {
+				span.SetName(name)
+			}
+		}
+	})
+}
+
+// routeMiddleware wraps the passed handler, functioning like middleware.
+// It sets the http.route attribute of the span in the request context to the
+// route returned by fn once the handler returns.
+func routeMiddleware(handler http.Handler, fn func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			if route := fn(r); route != "" {
+				span.SetAttributes(semconv.HTTPRouteKey.String(route))
+			}
+		}
+	})
+}
+
+// spanStatusMiddleware wraps the passed handler, functioning like
+// middleware. It sets the status of the span in the request context to the
+// code returned by fn for the response status code once the handler returns.
+func spanStatusMiddleware(handler http.Handler, fn func(int) codes.Code) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		var wroteHeader bool
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					if !wroteHeader {
+						wroteHeader = true
+						status = code
+					}
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					wroteHeader = true
+					return next(b)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					wroteHeader = true
+					return next(src)
+				}
+			},
+		})
+
+		handler.ServeHTTP(w, r)
+
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			if code := fn(status); code != codes.Unset {
+				span.SetStatus(code, "")
+			}
+		}
+	})
+}
+
+// filterMiddleware calls the instrumented handler if all the filters return
+// true for the request. Otherwise, the request is passed to next.
+func filterMiddleware(instrumented, next http.Handler, filters []func(*http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, f := range filters {
+			if !f(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		instrumented.ServeHTTP(w, r)
+	})
+}
+
 // traceResponseHeaderMiddleware wraps the passed handler, functioning like middleware.
 // It adds trace context in traceparent form (https://www.w3.org/TR/trace-context/#traceparent-header)
 // as Server-Timing header (https://www.w3.org/TR/server-timing/)
-// to the HTTP response.
-func traceResponseHeaderMiddleware(handler http.Handler) http.Handler {
+// with the passed name to the HTTP response, unless the name is empty.
+// If traceResponse is true, it also adds the trace context as traceresponse
+// header (https://www.w3.org/TR/trace-context-2/#traceresponse-header).
+// The headers are added when the response header is written, unless they are
+// suppressed using SuppressServerTiming.
+func traceResponseHeaderMiddleware(handler http.Handler, serverTimingName string, traceResponse bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.IsValid() {
-			w.Header().Add("Access-Control-Expose-Headers", "Server-Timing")
+		spanCtx := trace.SpanContextFromContext(r.Context())
+		if !spanCtx.IsValid() {
+			handler.ServeHTTP(w, r)
+			return
+		}
 
-			traceID := spanCtx.TraceID()
-			hexTraceID := hex.EncodeToString(traceID[:])
-			spanID := spanCtx.SpanID()
-			hexSpanID := hex.EncodeToString(spanID[:])
-			traceParent := "traceparent;desc=\"00-" + hexTraceID + "-" + hexSpanID + "-01\""
-			w.Header().Add("Server-Timing", traceParent)
+		state := &serverTimingState{}
+		r = r.WithContext(context.WithValue(r.Context(), serverTimingKey{}, state))
+
+		// The headers are added once the response header is written so the
+		// handler is able to suppress them with SuppressServerTiming.
+		addHeaders := func() {
+			if state.write() {
+				addTraceResponseHeaders(w.Header(), spanCtx, serverTimingName, traceResponse)
+			}
 		}
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					addHeaders()
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					addHeaders()
+					return next(b)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					addHeaders()
+					return next(src)
+				}
+			},
+			Flush: func(next httpsnoop.FlushFunc) httpsnoop.FlushFunc {
+				return func() {
+					addHeaders()
+					next()
+				}
+			},
+		})
 
 		handler.ServeHTTP(w, r)
+		addHeaders()
 	})
 }
+
+func addTraceResponseHeaders(h http.Header, spanCtx trace.SpanContext, serverTimingName string, traceResponse bool) {
+	traceID := spanCtx.TraceID()
+	hexTraceID := hex.EncodeToString(traceID[:])
+	spanID := spanCtx.SpanID()
+	hexSpanID := hex.EncodeToString(spanID[:])
+
+	if serverTimingName != "" {
+		h.Add("Access-Control-Expose-Headers", serverTimingName)
+		traceParent := "traceparent;desc=\"00-" + hexTraceID + "-" + hexSpanID + "-01\""
+		h.Add(serverTimingName, traceParent)
+	}
+	if traceResponse {
+		h.Add("Access-Control-Expose-Headers", "traceresponse")
+		flags := spanCtx.TraceFlags()
+		h.Add("traceresponse", "00-"+hexTraceID+"-"+hexSpanID+"-"+hex.EncodeToString([]byte{byte(flags)}))
+	}
+}
+
+// serverTimingKey is the context key of the serverTimingState of a request.
+type serverTimingKey struct{}
+
+// serverTimingState tracks whether the trace context response headers of a
+// request are suppressed or already written.
+type serverTimingState struct {
+	mu         sync.Mutex
+	written    bool
+	suppressed bool
+}
+
+// write marks the headers as written. It returns true if the headers have to
+// be added, i.e. if they were neither written nor suppressed before.
+func (s *serverTimingState) write() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.written {
+		return false
+	}
+	s.written = true
+	return !s.suppressed
+}
+
+// suppress marks the headers as suppressed. It returns false if the headers
+// were already written.
+func (s *serverTimingState) suppress() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.written {
+		return false
+	}
+	s.suppressed = true
+	return true
+}
+
+// SuppressServerTiming prevents NewHandler from adding the trace context
+// response headers (i.e. Server-Timing, or the header set with
+// WithServerTimingHeader, and traceresponse) and the related
+// Access-Control-Expose-Headers values to the response of the request with
+// the passed context. This is useful for responses to untrusted clients, to
+// which the trace IDs should not be exposed.
+//
+// It has to be called with the context of the request passed to the wrapped
+// handler before the response header is written. It returns false if the
+// response header is already written or if ctx is not the context of a
+// request handled by NewHandler with the headers enabled.
+func SuppressServerTiming(ctx context.Context) bool {
+	state, ok := ctx.Value(serverTimingKey{}).(*serverTimingState)
+	if !ok {
+		return false
+	}
+	return state.suppress()
+}
+
+// capturedHeaders returns the canonical names of the headers to capture.
+// Sensitive headers are excluded unless allowSensitive is true.
+func capturedHeaders(names []string, allowSensitive bool) []string {
+	var headers []string
+	for _, h := range names {
+		h = http.CanonicalHeaderKey(strings.TrimSpace(h))
+		if h == "" {
+			continue
+		}
+		if _, ok := sensitiveHeaders[h]; ok && !allowSensitive {
+			continue
+		}
+		headers = append(headers, h)
+	}
+	return headers
+}
+
+// captureRequestHeadersMiddleware wraps the passed handler, functioning like middleware.
+// It records the values of the passed request headers as
+// http.request.header.<name> attributes of the span in the request context.
+func captureRequestHeadersMiddleware(handler http.Handler, headers []string) http.Handler {
+	keys := headerKeys("http.request.header.", headers)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			setHeaderAttributes(span, r.Header, headers, keys)
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// captureResponseHeadersMiddleware wraps the passed handler, functioning like middleware.
+// It records the values of the passed response headers as
+// http.response.header.<name> attributes of the span in the request context.
+// The headers are captured when they are written, or when the handler
+// returns if it did not write a response. The optional interfaces of the
+// http.ResponseWriter (e.g. http.Flusher and http.Hijacker) are preserved.
+func captureResponseHeadersMiddleware(handler http.Handler, headers []string) http.Handler {
+	keys := headerKeys("http.response.header.", headers)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		if !span.IsRecording() {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		var once sync.Once
+		capture := func() {
+			once.Do(func() { setHeaderAttributes(span, w.Header(), headers, keys) })
+		}
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					capture()
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					capture()
+					return next(b)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					capture()
+					return next(src)
+				}
+			},
+			Flush: func(next httpsnoop.FlushFunc) httpsnoop.FlushFunc {
+				return func() {
+					capture()
+					next()
+				}
+			},
+			Hijack: func(next httpsnoop.HijackFunc) httpsnoop.HijackFunc {
+				return func() (net.Conn, *bufio.ReadWriter, error) {
+					capture()
+					return next()
+				}
+			},
+		})
+
+		handler.ServeHTTP(w, r)
+		capture()
+	})
+}
+
+// headerKeys returns the attribute keys of the headers. The header names are
+// lowercased and "-" is replaced by "_".
+func headerKeys(prefix string, headers []string) []attribute.Key {
+	keys := make([]attribute.Key, len(headers))
+	for i, h := range headers {
+		keys[i] = attribute.Key(prefix + strings.ReplaceAll(strings.ToLower(h), "-", "_"))
+	}
+	return keys
+}
+
+// setHeaderAttributes records the values of the headers present in h as the
+// attributes with the corresponding keys.
+func setHeaderAttributes(span trace.Span, h http.Header, headers []string, keys []attribute.Key) {
+	for i, name := range headers {
+		if values := h.Values(name); len(values) > 0 {
+			span.SetAttributes(keys[i].String(strings.Join(values, ",")))
+		}
+	}
+}
@@ -15,14 +15,24 @@
 package splunkhttp
 
 import (
+	"bufio"
+	"context"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 )
 
 func TestNewHandlerDefault(t *testing.T) {
@@ -44,12 +54,625 @@ func TestNewHandlerTraceResponseHeaderDisabled(t *testing.T) {
 	assert.NotRegexp(t, "^traceparent;desc=\"00-[0-9a-f]{32}-[0-9a-f]{16}-01\"$", resp.Header.Get("Server-Timing"), "should not add traceID to Server-Timing header")
 }
 
-func responseForHandler() *http.Response {
+func TestNewHandlerCapturedRequestHeaders(t *testing.T) {
+	testCases := []struct {
+		desc string
+		opts []Option
+		want []attribute.KeyValue
+	}{
+		{
+			desc: "default",
+		},
+		{
+			desc: "captured",
+			opts: []Option{WithCapturedRequestHeaders([]string{"X-Request-ID", "x-tenant", "X-Missing", "Authorization"})},
+			want: []attribute.KeyValue{
+				attribute.String("http.request.header.x_request_id", "42"),
+				attribute.String("http.request.header.x_tenant", "a,b"),
+			},
+		},
+		{
+			desc: "sensitive allowed",
+			opts: []Option{
+				WithCapturedRequestHeaders([]string{"Authorization"}),
+				WithSensitiveHeadersCaptured(true),
+			},
+			want: []attribute.KeyValue{
+				attribute.String("http.request.header.authorization", "Bearer secret"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler = NewHandler(handler, tc.opts...)
+			handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))))
+
+			req := httptest.NewRequest("", "/", http.NoBody)
+			req.Header.Set("X-Request-ID", "42")
+			req.Header.Add("X-Tenant", "a")
+			req.Header.Add("X-Tenant", "b")
+			req.Header.Set("Authorization", "Bearer secret")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Len(t, sr.Ended(), 1)
+			var got []attribute.KeyValue
+			for _, kv := range sr.Ended()[0].Attributes() {
+				if strings.HasPrefix(string(kv.Key), "http.request.header.") {
+					got = append(got, kv)
+				}
+			}
+			assert.ElementsMatch(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewHandlerCapturedResponseHeaders(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		handler http.HandlerFunc
+		want    []attribute.KeyValue
+	}{
+		{
+			desc: "WriteHeader",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-ID", "42")
+				w.Header().Add("X-Tenant", "a")
+				w.Header().Add("X-Tenant", "b")
+				w.Header().Set("Set-Cookie", "secret")
+				w.WriteHeader(http.StatusAccepted)
+				// Headers set after WriteHeader are not sent.
+				w.Header().Set("X-Late", "late")
+			},
+			want: []attribute.KeyValue{
+				attribute.String("http.response.header.x_request_id", "42"),
+				attribute.String("http.response.header.x_tenant", "a,b"),
+			},
+		},
+		{
+			desc: "Write",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-ID", "42")
+				w.Write([]byte("Any content")) //nolint:errcheck // no need to check the error
+			},
+			want: []attribute.KeyValue{
+				attribute.String("http.response.header.x_request_id", "42"),
+			},
+		},
+		{
+			desc: "no response written",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Late", "late")
+			},
+			want: []attribute.KeyValue{
+				attribute.String("http.response.header.x_late", "late"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			var handler http.Handler = tc.handler
+			handler = NewHandler(handler, WithCapturedResponseHeaders([]string{"X-Request-ID", "X-Tenant", "X-Late", "X-Missing", "Set-Cookie"}))
+			handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("", "/", http.NoBody))
+
+			require.Len(t, sr.Ended(), 1)
+			var got []attribute.KeyValue
+			for _, kv := range sr.Ended()[0].Attributes() {
+				if strings.HasPrefix(string(kv.Key), "http.response.header.") {
+					got = append(got, kv)
+				}
+			}
+			assert.ElementsMatch(t, tc.want, got)
+		})
+	}
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
+
+func TestNewHandlerCapturedResponseHeadersInterfaces(t *testing.T) {
+	var flusher, hijacker bool
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var f http.Flusher
+		f, flusher = w.(http.Flusher)
+		var h http.Hijacker
+		h, hijacker = w.(http.Hijacker)
+		if flusher && hijacker {
+			f.Flush()
+			h.Hijack() //nolint:errcheck // no need to check the error
+		}
+	})
+	handler = NewHandler(handler, WithCapturedResponseHeaders([]string{"X-Request-ID"}))
+	handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider()))
+
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(w, httptest.NewRequest("", "/", http.NoBody))
+
+	assert.True(t, flusher, "should preserve http.Flusher")
+	assert.True(t, hijacker, "should preserve http.Hijacker")
+	assert.True(t, w.Flushed, "should flush the wrapped ResponseWriter")
+	assert.True(t, w.hijacked, "should hijack the wrapped ResponseWriter")
+}
+
+func TestNewHandlerServerTimingHeader(t *testing.T) {
+	resp := responseForHandler(WithServerTimingHeader("x-trace-timing")) //nolint:bodyclose // Body is not used
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "should return OK status code")
+	assert.Equal(t, []string{"X-Trace-Timing"}, resp.Header["Access-Control-Expose-Headers"], "should expose the custom header")
+	assert.Regexp(t, "^traceparent;desc=\"00-[0-9a-f]{32}-[0-9a-f]{16}-01\"$", resp.Header.Get("X-Trace-Timing"), "should return properly formated custom header")
+	assert.Empty(t, resp.Header.Get("Server-Timing"), "should not set Server-Timing header")
+}
+
+func TestNewHandlerTraceResponseHeader(t *testing.T) {
+	resp := responseForHandler(WithTraceResponseHeader(true)) //nolint:bodyclose // Body is not used
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "should return OK status code")
+	assert.ElementsMatch(t, []string{"Server-Timing", "traceresponse"}, resp.Header["Access-Control-Expose-Headers"], "should expose both headers")
+	assert.Regexp(t, "^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$", resp.Header.Get("traceresponse"), "should return properly formated traceresponse header")
+	assert.Regexp(t, "^traceparent;desc=\"00-[0-9a-f]{32}-[0-9a-f]{16}-01\"$", resp.Header.Get("Server-Timing"), "should still return Server-Timing header")
+
+	traceparent := strings.TrimSuffix(strings.TrimPrefix(resp.Header.Get("Server-Timing"), `traceparent;desc="`), `"`)
+	assert.Equal(t, traceparent, resp.Header.Get("traceresponse"), "should return the same trace context")
+}
+
+func TestNewHandlerTraceResponseHeaderOnly(t *testing.T) {
+	t.Setenv("SPLUNK_TRACE_RESPONSE_HEADER_ENABLED", "false")
+
+	resp := responseForHandler(WithTraceResponseHeader(true)) //nolint:bodyclose // Body is not used
+
+	assert.Equal(t, []string{"traceresponse"}, resp.Header["Access-Control-Expose-Headers"], "should only expose traceresponse header")
+	assert.Regexp(t, "^00-[0-9a-f]{32}-[0-9a-f]{16}-01$", resp.Header.Get("traceresponse"), "should return properly formated traceresponse header")
+	assert.Empty(t, resp.Header.Get("Server-Timing"), "should not set Server-Timing header")
+}
+
+func TestSuppressServerTiming(t *testing.T) {
+	var suppressed, late bool
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suppressed = SuppressServerTiming(r.Context())
+		w.WriteHeader(http.StatusOK)
+		late = SuppressServerTiming(r.Context())
+	})
+	handler = NewHandler(handler, WithTraceResponseHeader(true))
+	handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider()))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("", "/", http.NoBody))
+	resp := w.Result() //nolint:bodyclose // Body is not used
+
+	assert.True(t, suppressed, "should suppress the headers")
+	assert.False(t, late, "should not suppress the headers once written")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "should return OK status code")
+	assert.Empty(t, resp.Header.Values("Server-Timing"), "should not return Server-Timing header")
+	assert.Empty(t, resp.Header.Values("traceresponse"), "should not return traceresponse header")
+	assert.Empty(t, resp.Header.Values("Access-Control-Expose-Headers"), "should not expose any header")
+}
+
+func TestSuppressServerTimingWithoutHandler(t *testing.T) {
+	assert.False(t, SuppressServerTiming(context.Background()), "should not suppress the headers of a request not handled by NewHandler")
+}
+
+func TestNewHandlerWithFilter(t *testing.T) {
+	filter := func(r *http.Request) bool { return r.URL.Path != "/healthz" }
+
+	sr := tracetest.NewSpanRecorder()
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler = NewHandler(handler, WithFilter(filter))
+	handler = otelhttp.NewHandler(handler, "server",
+		otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))),
+		otelhttp.WithFilter(filter),
+	)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("", "/healthz", http.NoBody))
+	assert.Empty(t, sr.Ended(), "should not create a span for an excluded path")
+	assert.Empty(t, w.Header().Get("Server-Timing"), "should not set Server-Timing header for an excluded path")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("", "/api", http.NoBody))
+	assert.Len(t, sr.Ended(), 1, "should create a span")
+	assert.Regexp(t, "^traceparent;desc=\"00-[0-9a-f]{32}-[0-9a-f]{16}-01\"$", w.Header().Get("Server-Timing"), "should set Server-Timing header")
+}
+
+func TestNewHandlerWithFilterSkipsProcessing(t *testing.T) {
+	// The filter must also skip the Splunk specific processing when the
+	// request is traced.
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler = NewHandler(handler, WithFilter(func(r *http.Request) bool { return false }))
+	handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider()))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("", "/healthz", http.NoBody))
+	assert.Empty(t, w.Header().Get("Server-Timing"), "should not set Server-Timing header")
+}
+
+func TestNewHandlerWithNamerWithFilter(t *testing.T) {
+	filter := func(r *http.Request) bool { return r.URL.Path != "/healthz" }
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := []struct {
+		desc    string
+		handler func(...Option) http.Handler
+	}{
+		{
+			desc: "NewHandlerWithNamer",
+			handler: func(opts ...Option) http.Handler {
+				return NewHandlerWithNamer(mux, defaultName, opts...)
+			},
+		},
+		{
+			desc: "NewServeMuxHandler",
+			handler: func(opts ...Option) http.Handler {
+				return NewServeMuxHandler(mux, opts...)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+			handler := tc.handler(
+				WithOTelOpts(otelhttp.WithTracerProvider(tp)),
+				WithForceSampleHeader("", func(*http.Request) bool { return true }),
+				WithFilter(filter),
+			)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody)
+			r.Header.Set(DefaultForceSampleHeader, "true")
+			handler.ServeHTTP(w, r)
+			assert.Empty(t, sr.Started(), "should not start a span for an excluded path")
+			assert.Empty(t, w.Header().Get("Server-Timing"), "should not set Server-Timing header for an excluded path")
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", http.NoBody))
+			assert.Len(t, sr.Ended(), 1, "should create a span")
+		})
+	}
+}
+
+func TestNewHandlerWithRouteFunc(t *testing.T) {
+	type routeKey struct{}
+	routeFunc := func(r *http.Request) string {
+		if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+			return *route
+		}
+		return ""
+	}
+
+	// Simulate a router storing the route matched while running its
+	// middleware in a route context of the request, like github.com/go-chi/chi
+	// does.
+	router := func(middleware func(http.Handler) http.Handler, endpoint http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := new(string)
+			r = r.WithContext(context.WithValue(r.Context(), routeKey{}, route))
+			middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				*route = "/users/{id}"
+				endpoint.ServeHTTP(w, r)
+			})).ServeHTTP(w, r)
+		})
+	}
+	middleware := func(h http.Handler) http.Handler {
+		return NewHandler(h, WithRouteFunc(routeFunc))
+	}
+
+	testCases := []struct {
+		desc    string
+		handler func(http.Handler) http.Handler
+		want    []attribute.KeyValue
+	}{
+		{
+			desc: "route",
+			handler: func(h http.Handler) http.Handler {
+				return router(middleware, h)
+			},
+			want: []attribute.KeyValue{attribute.String("http.route", "/users/{id}")},
+		},
+		{
+			desc:    "no route",
+			handler: middleware,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			handler := tc.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("", "/users/42", http.NoBody))
+
+			require.Len(t, sr.Ended(), 1)
+			var got []attribute.KeyValue
+			for _, kv := range sr.Ended()[0].Attributes() {
+				if kv.Key == "http.route" {
+					got = append(got, kv)
+				}
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewHandlerWithNamer(t *testing.T) {
+	type routeKey struct{}
+	namer := func(r *http.Request) string {
+		if r.URL.Path == "/" {
+			return ""
+		}
+		if route, ok := r.Context().Value(routeKey{}).(*string); ok && *route != "" {
+			return r.Method + " " + *route
+		}
+		return r.Method + " " + r.URL.Path
+	}
+
+	testCases := []struct {
+		desc    string
+		path    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			desc:    "name",
+			path:    "/users",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			want:    "GET /users",
+		},
+		{
+			desc: "name set while handling",
+			path: "/users/42",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+					*route = "/users/{id}"
+				}
+			},
+			want: "GET /users/{id}",
+		},
+		{
+			desc:    "default",
+			path:    "/",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			want:    "HTTP GET",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			handler := NewHandlerWithNamer(tc.handler, namer,
+				WithOTelOpts(otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr)))),
+			)
+			// Simulate a router storing the matched route in the request context.
+			handler = func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, new(string))))
+				})
+			}(handler)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, http.NoBody))
+
+			require.Len(t, sr.Ended(), 1)
+			assert.Equal(t, tc.want, sr.Ended()[0].Name())
+			assert.NotEmpty(t, w.Header().Get("Server-Timing"), "should enable the Splunk specific instrumentation")
+		})
+	}
+}
+
+func TestWithRecoverPanic(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		handler    http.HandlerFunc
+		repanic    bool
+		wantStatus int
+	}{
+		{
+			desc:       "swallow",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			desc:       "repanic",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			repanic:    true,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			desc: "response written",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("boom")
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			handler := NewHandler(tc.handler, WithRecoverPanic(tc.repanic))
+			handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))))
+
+			w := httptest.NewRecorder()
+			serve := func() { handler.ServeHTTP(w, httptest.NewRequest("", "/", http.NoBody)) }
+			if tc.repanic {
+				assert.PanicsWithValue(t, "boom", serve)
+			} else {
+				assert.NotPanics(t, serve)
+			}
+			assert.Equal(t, tc.wantStatus, w.Code)
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, codes.Error, spans[0].Status().Code)
+			// The SDK also records the panic if the span ends while panicking.
+			events := spans[0].Events()
+			require.NotEmpty(t, events)
+			assert.Equal(t, "exception", events[0].Name)
+			assert.Contains(t, events[0].Attributes, attribute.String("exception.message", "boom"))
+			var stacktrace string
+			for _, a := range events[0].Attributes {
+				if a.Key == "exception.stacktrace" {
+					stacktrace = a.Value.AsString()
+				}
+			}
+			assert.Contains(t, stacktrace, "panic")
+		})
+	}
+}
+
+func TestWithRecoverPanicDefault(t *testing.T) {
+	handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("", "/", http.NoBody))
+	}, "panics should not be recovered by default")
+}
+
+func TestWithBodySizeCaptured(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		handler  http.HandlerFunc
+		wantRead int
+		chunked  bool
+	}{
+		{
+			desc: "streamed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(io.Discard, r.Body)
+				for _, chunk := range []string{"Hello", ", ", "World!"} {
+					_, _ = io.WriteString(w, chunk)
+					w.(http.Flusher).Flush()
+				}
+			},
+			wantRead: 13,
+			chunked:  true,
+		},
+		{
+			desc: "partially read",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.ReadFull(r.Body, make([]byte, 4))
+				_, _ = io.WriteString(w, "Hello, World!")
+			},
+			wantRead: 4,
+		},
+		{
+			desc: "read from",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(io.Discard, r.Body)
+				_, _ = w.(io.ReaderFrom).ReadFrom(strings.NewReader("Hello, World!"))
+			},
+			wantRead: 13,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			handler := NewHandler(tc.handler, WithBodySizeCaptured(true))
+			handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))))
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			// Hide the length of the body so it is sent using chunked transfer.
+			body := struct{ io.Reader }{strings.NewReader("Hello, World!")}
+			resp, err := http.Post(srv.URL, "text/plain", body) //nolint:noctx // no need for a context
+			require.NoError(t, err)
+			if tc.chunked {
+				assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+			}
+			got, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			assert.Equal(t, "Hello, World!", string(got))
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			attrs := spans[0].Attributes()
+			assert.Contains(t, attrs, semconv.HTTPRequestContentLength(tc.wantRead))
+			assert.Contains(t, attrs, semconv.HTTPResponseContentLength(len(got)))
+		})
+	}
+}
+
+func TestWithBodySizeCapturedDefault(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "Hello, World!")
+	}))
+	handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("", "/", http.NoBody))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	for _, a := range spans[0].Attributes() {
+		assert.NotEqual(t, semconv.HTTPResponseContentLengthKey, a.Key, "body size should not be captured by default")
+	}
+}
+
+func TestWithSpanStatusFromResponse(t *testing.T) {
+	statusFunc := func(status int) codes.Code {
+		switch {
+		case status == http.StatusTooManyRequests, status == 499:
+			return codes.Error
+		case status == http.StatusServiceUnavailable:
+			return codes.Ok
+		case status >= http.StatusInternalServerError:
+			return codes.Error
+		default:
+			return codes.Unset
+		}
+	}
+
+	custom := []Option{WithSpanStatusFromResponse(statusFunc)}
+	testCases := []struct {
+		desc       string
+		status     int
+		opts       []Option
+		wantStatus codes.Code
+	}{
+		{desc: "custom error", status: http.StatusTooManyRequests, opts: custom, wantStatus: codes.Error},
+		{desc: "custom client closed request", status: 499, opts: custom, wantStatus: codes.Error},
+		{desc: "custom unset", status: http.StatusNotFound, opts: custom, wantStatus: codes.Unset},
+		{desc: "custom success", status: http.StatusOK, opts: custom, wantStatus: codes.Unset},
+		{desc: "custom server error", status: http.StatusInternalServerError, opts: custom, wantStatus: codes.Error},
+		{desc: "custom ok", status: http.StatusServiceUnavailable, opts: custom, wantStatus: codes.Ok},
+		{desc: "default client error", status: http.StatusTooManyRequests, wantStatus: codes.Unset},
+		{desc: "default server error", status: http.StatusInternalServerError, wantStatus: codes.Error},
+		{desc: "nil", status: http.StatusTooManyRequests, opts: []Option{WithSpanStatusFromResponse(nil)}, wantStatus: codes.Unset},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}), tc.opts...)
+			handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("", "/", http.NoBody))
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tc.wantStatus, spans[0].Status().Code)
+		})
+	}
+}
+
+func responseForHandler(opts ...Option) *http.Response {
 	content := []byte("Any content")
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write(content) //nolint:errcheck // no need to check the error
 	})
-	handler = NewHandler(handler)
+	handler = NewHandler(handler, opts...)
 	handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider()))
 
 	w := httptest.NewRecorder()
@@ -0,0 +1,200 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// instrumentationName is the name of the meter used by this package, and of
+// the tracer of the spans started with StartSpan.
+const instrumentationName = "github.com/signalfx/splunk-otel-go/instrumentation/net/http/splunkhttp"
+
+// Names of the HTTP server metrics.
+const (
+	serverDurationName     = "http.server.duration"
+	serverRequestSizeName  = "http.server.request.size"
+	serverResponseSizeName = "http.server.response.size"
+)
+
+// knownMethods are the HTTP methods recorded as is. Other methods are
+// recorded as otherMethod to keep a low cardinality.
+var knownMethods = map[string]struct{}{
+	http.MethodConnect: {},
+	http.MethodDelete:  {},
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+	http.MethodPatch:   {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodTrace:   {},
+}
+
+const otherMethod = "_OTHER"
+
+// serverMetrics are the instruments recording the HTTP server metrics.
+type serverMetrics struct {
+	duration     metric.Float64Histogram
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+}
+
+func newServerMetrics(mp metric.MeterProvider) (*serverMetrics, error) {
+	meter := mp.Meter(instrumentationName, metric.WithSchemaURL(semconv.SchemaURL))
+
+	var (
+		m   serverMetrics
+		err error
+	)
+	m.duration, err = meter.Float64Histogram(
+		serverDurationName,
+		metric.WithUnit("ms"),
+		metric.WithDescription("The duration of the inbound HTTP requests"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.requestSize, err = meter.Int64Histogram(
+		serverRequestSizeName,
+		metric.WithUnit("By"),
+		metric.WithDescription("The size of the HTTP request bodies"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.responseSize, err = meter.Int64Histogram(
+		serverResponseSizeName,
+		metric.WithUnit("By"),
+		metric.WithDescription("The size of the HTTP response bodies"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// metricsMiddleware wraps the passed handler, functioning like middleware.
+// It records the duration of the request and the number of bytes of the
+// request body read by the handler and of the response body written by the
+// handler once the handler returns. The route returned by routeFunc, if any,
+// is recorded instead of the URL path to keep a low cardinality. The duration
+// is measured with now.
+func metricsMiddleware(handler http.Handler, mp metric.MeterProvider, routeFunc func(*http.Request) string, now func() time.Time) http.Handler {
+	m, err := newServerMetrics(mp)
+	if err != nil {
+		otel.Handle(err)
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := now()
+
+		var read, wrote int64
+		if r.Body != nil && r.Body != http.NoBody {
+			r2 := *r
+			r2.Body = &countingReadCloser{ReadCloser: r.Body, n: &read}
+			r = &r2
+		}
+		status := http.StatusOK
+		var wroteHeader bool
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					if !wroteHeader {
+						wroteHeader = true
+						status = code
+					}
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					wroteHeader = true
+					n, err := next(b)
+					atomic.AddInt64(&wrote, int64(n))
+					return n, err
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					wroteHeader = true
+					n, err := next(src)
+					atomic.AddInt64(&wrote, n)
+					return n, err
+				}
+			},
+		})
+
+		handler.ServeHTTP(w, r)
+
+		var route string
+		if routeFunc != nil {
+			route = routeFunc(r)
+		}
+		opt := metric.WithAttributes(serverMetricAttributes(r, status, route)...)
+		ctx := r.Context()
+		m.duration.Record(ctx, float64(now().Sub(start))/float64(time.Millisecond), opt)
+		m.requestSize.Record(ctx, atomic.LoadInt64(&read), opt)
+		m.responseSize.Record(ctx, atomic.LoadInt64(&wrote), opt)
+	})
+}
+
+// serverMetricAttributes returns the low cardinality attributes of the HTTP
+// server metrics defined by the semantic conventions. The route is not
+// recorded if it is empty.
+func serverMetricAttributes(r *http.Request, status int, route string) []attribute.KeyValue {
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	} else if _, ok := knownMethods[method]; !ok {
+		method = otherMethod
+	}
+	scheme := semconv.HTTPSchemeHTTP
+	if r.TLS != nil {
+		scheme = semconv.HTTPSchemeHTTPS
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPMethod(method),
+		scheme,
+		semconv.HTTPStatusCode(status),
+	}
+	switch r.ProtoMajor {
+	case 1:
+		if r.ProtoMinor == 0 {
+			attrs = append(attrs, semconv.HTTPFlavorHTTP10)
+		} else {
+			attrs = append(attrs, semconv.HTTPFlavorHTTP11)
+		}
+	case 2:
+		attrs = append(attrs, semconv.HTTPFlavorHTTP20)
+	case 3:
+		attrs = append(attrs, semconv.HTTPFlavorHTTP30)
+	}
+	if route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+	}
+	return attrs
+}
@@ -0,0 +1,169 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// histograms returns the histogram data points recorded by reader by metric
+// name.
+func histograms(t *testing.T, reader metric.Reader) map[string][]metricdata.HistogramDataPoint[int64] {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	got := make(map[string][]metricdata.HistogramDataPoint[int64])
+	for _, sm := range rm.ScopeMetrics {
+		assert.Equal(t, instrumentationName, sm.Scope.Name)
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[int64]:
+				got[m.Name] = data.DataPoints
+			case metricdata.Histogram[float64]:
+				// Only the duration is recorded as float64. Convert the data
+				// points for the assertions of the count and attributes.
+				for _, dp := range data.DataPoints {
+					got[m.Name] = append(got[m.Name], metricdata.HistogramDataPoint[int64]{
+						Attributes: dp.Attributes,
+						Count:      dp.Count,
+					})
+				}
+			default:
+				t.Fatalf("unexpected %s metric data: %T", m.Name, m.Data)
+			}
+		}
+	}
+	return got
+}
+
+func TestWithMetrics(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+
+	routeFunc := func(r *http.Request) string {
+		if strings.HasPrefix(r.URL.Path, "/users/") {
+			return "/users/{id}"
+		}
+		return ""
+	}
+	handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, "Hello, World!")
+	}), WithMetrics(mp), WithRouteFunc(routeFunc))
+
+	for _, path := range []string{"/users/1", "/users/2"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, strings.NewReader("body")))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", http.NoBody))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("CUSTOM", "/users/3", http.NoBody))
+
+	usersAttrs := attribute.NewSet(
+		semconv.HTTPMethod("POST"),
+		semconv.HTTPSchemeHTTP,
+		semconv.HTTPFlavorHTTP11,
+		semconv.HTTPStatusCode(http.StatusOK),
+		semconv.HTTPRoute("/users/{id}"),
+	)
+	missingAttrs := attribute.NewSet(
+		semconv.HTTPMethod("GET"),
+		semconv.HTTPSchemeHTTP,
+		semconv.HTTPFlavorHTTP11,
+		semconv.HTTPStatusCode(http.StatusNotFound),
+	)
+	otherAttrs := attribute.NewSet(
+		semconv.HTTPMethod("_OTHER"),
+		semconv.HTTPSchemeHTTP,
+		semconv.HTTPFlavorHTTP11,
+		semconv.HTTPStatusCode(http.StatusOK),
+		semconv.HTTPRoute("/users/{id}"),
+	)
+
+	got := histograms(t, reader)
+	require.Len(t, got, 3)
+
+	duration := got["http.server.duration"]
+	require.Len(t, duration, 3)
+	counts := make(map[attribute.Distinct]uint64)
+	for _, dp := range duration {
+		counts[dp.Attributes.Equivalent()] = dp.Count
+	}
+	assert.Equal(t, map[attribute.Distinct]uint64{
+		usersAttrs.Equivalent():   2,
+		missingAttrs.Equivalent(): 1,
+		otherAttrs.Equivalent():   1,
+	}, counts)
+
+	for name, wantSum := range map[string]map[attribute.Distinct]int64{
+		"http.server.request.size": {
+			usersAttrs.Equivalent():   8,
+			missingAttrs.Equivalent(): 0,
+			otherAttrs.Equivalent():   0,
+		},
+		"http.server.response.size": {
+			usersAttrs.Equivalent():   26,
+			missingAttrs.Equivalent(): int64(len("404 page not found\n")),
+			otherAttrs.Equivalent():   13,
+		},
+	} {
+		sums := make(map[attribute.Distinct]int64)
+		for _, dp := range got[name] {
+			sums[dp.Attributes.Equivalent()] = dp.Sum
+		}
+		assert.Equal(t, wantSum, sums, name)
+	}
+}
+
+func TestWithMetricsStatusCode(t *testing.T) {
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+
+	handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.WriteHeader(http.StatusInternalServerError) // Ignored.
+	}), WithMetrics(mp))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", http.NoBody))
+
+	got := histograms(t, reader)["http.server.duration"]
+	require.Len(t, got, 1)
+	attrs := got[0].Attributes
+	v, ok := attrs.Value(semconv.HTTPStatusCodeKey)
+	assert.True(t, ok)
+	assert.Equal(t, int64(http.StatusAccepted), v.AsInt64())
+	_, ok = attrs.Value(semconv.HTTPRouteKey)
+	assert.False(t, ok, "the URL path should not be recorded as route")
+}
+
+func TestWithMetricsDefault(t *testing.T) {
+	cfg := newConfig()
+	assert.Nil(t, cfg.MeterProvider, "metrics should not be recorded by default")
+}
@@ -0,0 +1,74 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// idPlaceholder replaces the collapsed path segments.
+const idPlaceholder = "{id}"
+
+// defaultIDPatterns match the numeric and UUID path segments.
+var defaultIDPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^[0-9]+$`),
+	regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+}
+
+// LowCardinalityNamer returns a span namer, which can be passed to
+// NewHandlerWithNamer, naming the span after the request method followed by
+// the URL path with its identifier segments collapsed to the "{id}"
+// placeholder (e.g. "GET /users/{id}/orders/{id}").
+//
+// A path segment is collapsed if any of the passed patterns matches the whole
+// segment. If no pattern is passed, numeric and UUID segments are collapsed.
+func LowCardinalityNamer(patterns ...*regexp.Regexp) func(*http.Request) string {
+	if len(patterns) == 0 {
+		patterns = defaultIDPatterns
+	} else {
+		// Only match whole segments.
+		anchored := make([]*regexp.Regexp, len(patterns))
+		for i, p := range patterns {
+			anchored[i] = regexp.MustCompile(`^(?:` + p.String() + `)$`)
+		}
+		patterns = anchored
+	}
+	return func(r *http.Request) string {
+		return r.Method + " " + collapsePath(r.URL.Path, patterns)
+	}
+}
+
+// collapsePath returns the path with the segments matched by any of the
+// patterns replaced by idPlaceholder.
+func collapsePath(path string, patterns []*regexp.Regexp) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if s != "" && matchesAny(s, patterns) {
+			segments[i] = idPlaceholder
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func matchesAny(s string, patterns []*regexp.Regexp) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestLowCardinalityNamer(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		patterns []*regexp.Regexp
+		method   string
+		path     string
+		want     string
+	}{
+		{
+			desc:   "numeric ID",
+			method: http.MethodGet,
+			path:   "/users/42",
+			want:   "GET /users/{id}",
+		},
+		{
+			desc:   "UUID",
+			method: http.MethodDelete,
+			path:   "/orders/123e4567-e89b-12d3-a456-426614174000",
+			want:   "DELETE /orders/{id}",
+		},
+		{
+			desc:   "mixed",
+			method: http.MethodGet,
+			path:   "/users/42/orders/123E4567-E89B-12D3-A456-426614174000/items",
+			want:   "GET /users/{id}/orders/{id}/items",
+		},
+		{
+			desc:   "no ID",
+			method: http.MethodGet,
+			path:   "/users/v2/me/",
+			want:   "GET /users/v2/me/",
+		},
+		{
+			desc:   "partial numeric",
+			method: http.MethodGet,
+			path:   "/files/report2023",
+			want:   "GET /files/report2023",
+		},
+		{
+			desc:     "custom patterns",
+			patterns: []*regexp.Regexp{regexp.MustCompile(`u-[a-z]+`), regexp.MustCompile(`[0-9]+`)},
+			method:   http.MethodPut,
+			path:     "/users/u-bob/items/7/u-bob2",
+			want:     "PUT /users/{id}/items/{id}/u-bob2",
+		},
+		{
+			desc:     "custom patterns replace defaults",
+			patterns: []*regexp.Regexp{regexp.MustCompile(`u-[a-z]+`)},
+			method:   http.MethodGet,
+			path:     "/users/u-bob/items/7",
+			want:     "GET /users/{id}/items/7",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			namer := LowCardinalityNamer(tc.patterns...)
+			assert.Equal(t, tc.want, namer(httptest.NewRequest(tc.method, tc.path, http.NoBody)))
+		})
+	}
+}
+
+func TestWithLowCardinalityNames(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), WithLowCardinalityNames())
+	handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42/orders/123e4567-e89b-12d3-a456-426614174000", http.NoBody))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /users/{id}/orders/{id}", spans[0].Name())
+}
@@ -0,0 +1,48 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Keys of the pprof labels set by WithProfilerLabels.
+const (
+	traceIDLabel = "trace_id"
+	spanIDLabel  = "span_id"
+)
+
+// profilerLabelsMiddleware wraps the passed handler, functioning like
+// middleware. It sets the trace_id and span_id pprof labels of the span in
+// the request context on the goroutine serving the request for the duration
+// of the handler. The request context holds the labels. The labels are not
+// set if there is no valid span in the request context.
+func profilerLabelsMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc := trace.SpanContextFromContext(r.Context())
+		if !sc.IsValid() {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		labels := pprof.Labels(traceIDLabel, sc.TraceID().String(), spanIDLabel, sc.SpanID().String())
+		pprof.Do(r.Context(), labels, func(ctx context.Context) {
+			handler.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// handlerLabels returns a handler storing the pprof labels of the request
+// context in got.
+func handlerLabels(got map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pprof.ForLabels(r.Context(), func(key, value string) bool {
+			got[key] = value
+			return true
+		})
+	})
+}
+
+func TestWithProfilerLabels(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	got := map[string]string{}
+	handler := NewHandlerWithNamer(handlerLabels(got), defaultName,
+		WithOTelOpts(otelhttp.WithTracerProvider(tp)),
+		WithProfilerLabels(),
+	)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	require.Len(t, sr.Ended(), 1)
+	sc := sr.Ended()[0].SpanContext()
+	want := map[string]string{
+		"trace_id": sc.TraceID().String(),
+		"span_id":  sc.SpanID().String(),
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestWithProfilerLabelsDisabled(t *testing.T) {
+	tp := trace.NewTracerProvider()
+	got := map[string]string{}
+	handler := NewHandlerWithNamer(handlerLabels(got), defaultName, WithOTelOpts(otelhttp.WithTracerProvider(tp)))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	assert.Empty(t, got)
+}
+
+func TestProfilerLabelsMiddlewareNotTraced(t *testing.T) {
+	got := map[string]string{}
+	handler := profilerLabelsMiddleware(handlerLabels(got))
+
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody).WithContext(context.Background())
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Empty(t, got)
+}
@@ -0,0 +1,72 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultRUMSessionIDKey is the header and baggage key of the Splunk RUM
+// session ID used by WithRUMSessionID if no key is passed.
+const DefaultRUMSessionIDKey = "splunk.rumSessionId"
+
+// rumSessionIDAttrKey is the attribute key of the Splunk RUM session ID
+// recorded on the server span.
+const rumSessionIDAttrKey = attribute.Key("splunk.rum.session_id")
+
+// rumSessionMiddleware wraps the passed handler, functioning like
+// middleware. It records the Splunk RUM session ID sent by the browser agent
+// with the key as header or baggage member on the span in the request
+// context.
+func rumSessionMiddleware(handler http.Handler, key string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			if id := rumSessionID(r, key); id != "" {
+				span.SetAttributes(rumSessionIDAttrKey.String(id))
+			}
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// rumSessionID returns the Splunk RUM session ID of the request. The header
+// takes precedence over the baggage extracted in the request context, which
+// takes precedence over the baggage header (i.e. if the baggage is not
+// extracted by the configured propagator).
+func rumSessionID(r *http.Request, key string) string {
+	if id := r.Header.Get(key); id != "" {
+		return id
+	}
+	if id := baggage.FromContext(r.Context()).Member(key).Value(); id != "" {
+		return id
+	}
+	for _, h := range r.Header.Values("baggage") {
+		// Invalid members make the whole header invalid, as for the
+		// propagation.Baggage propagator.
+		b, err := baggage.Parse(h)
+		if err != nil {
+			continue
+		}
+		if id := b.Member(key).Value(); id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithRUMSessionID(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		opts       []Option
+		propagator propagation.TextMapPropagator
+		headers    map[string]string
+		want       string
+	}{
+		{
+			desc:    "header",
+			opts:    []Option{WithRUMSessionID("")},
+			headers: map[string]string{"splunk.rumSessionId": "abc"},
+			want:    "abc",
+		},
+		{
+			desc:       "baggage",
+			opts:       []Option{WithRUMSessionID("")},
+			propagator: propagation.Baggage{},
+			headers:    map[string]string{"baggage": "splunk.rumSessionId=abc,other=1"},
+			want:       "abc",
+		},
+		{
+			desc:    "baggage not extracted",
+			opts:    []Option{WithRUMSessionID("")},
+			headers: map[string]string{"baggage": "other=1,splunk.rumSessionId=abc"},
+			want:    "abc",
+		},
+		{
+			desc: "header precedence",
+			opts: []Option{WithRUMSessionID("")},
+			headers: map[string]string{
+				"splunk.rumSessionId": "header",
+				"baggage":             "splunk.rumSessionId=baggage",
+			},
+			want: "header",
+		},
+		{
+			desc:    "custom key header",
+			opts:    []Option{WithRUMSessionID("X-Session")},
+			headers: map[string]string{"X-Session": "abc", "splunk.rumSessionId": "default"},
+			want:    "abc",
+		},
+		{
+			desc:       "custom key baggage",
+			opts:       []Option{WithRUMSessionID("session")},
+			propagator: propagation.Baggage{},
+			headers:    map[string]string{"baggage": "session=abc"},
+			want:       "abc",
+		},
+		{
+			desc:    "invalid baggage",
+			opts:    []Option{WithRUMSessionID("")},
+			headers: map[string]string{"baggage": "splunk.rumSessionId=abc,="},
+		},
+		{
+			desc: "missing",
+			opts: []Option{WithRUMSessionID("")},
+		},
+		{
+			desc:    "disabled",
+			headers: map[string]string{"splunk.rumSessionId": "abc"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			propagator := tc.propagator
+			if propagator == nil {
+				propagator = propagation.TraceContext{}
+			}
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler = NewHandler(handler, tc.opts...)
+			handler = otelhttp.NewHandler(handler, "server",
+				otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr))),
+				otelhttp.WithPropagators(propagator),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Len(t, sr.Ended(), 1)
+			var got []attribute.KeyValue
+			for _, kv := range sr.Ended()[0].Attributes() {
+				if kv.Key == "splunk.rum.session_id" {
+					got = append(got, kv)
+				}
+			}
+			if tc.want == "" {
+				assert.Empty(t, got)
+			} else {
+				assert.Equal(t, []attribute.KeyValue{attribute.String("splunk.rum.session_id", tc.want)}, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redactedQueryValue replaces the values of the query parameters not kept by
+// WithSanitizeURL.
+const redactedQueryValue = "REDACTED"
+
+// sanitizeQuery returns the raw query with the values of the parameters not
+// in keep replaced by redactedQueryValue. The order of the parameters and the
+// encoding of the kept ones are preserved. The parameters without a value are
+// kept as is.
+func sanitizeQuery(rawQuery string, keep map[string]struct{}) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, p := range params {
+		k, _, hasValue := strings.Cut(p, "=")
+		if !hasValue {
+			continue
+		}
+		name, err := url.QueryUnescape(k)
+		if err != nil {
+			name = k
+		}
+		if _, ok := keep[name]; !ok {
+			params[i] = k + "=" + redactedQueryValue
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// sanitizedTarget returns the request target of r (i.e. the path and query)
+// with the query sanitized.
+func sanitizedTarget(r *http.Request, keep map[string]struct{}) string {
+	u := *r.URL
+	u.RawQuery = sanitizeQuery(u.RawQuery, keep)
+	return u.RequestURI()
+}
+
+// sanitizedURL returns the URL of r without the user information, as
+// recorded by otelhttp, and with the query sanitized.
+func sanitizedURL(r *http.Request, keep map[string]struct{}) string {
+	u := *r.URL
+	u.User = nil
+	u.RawQuery = sanitizeQuery(u.RawQuery, keep)
+	return u.String()
+}
+
+// sanitizeURLMiddleware wraps the passed handler, functioning like
+// middleware. It records the request target with the query sanitized as the
+// http.target attribute of the span in the request context, replacing the
+// one recorded when the span was started, if any.
+func sanitizeURLMiddleware(handler http.Handler, keep map[string]struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			span.SetAttributes(semconv.HTTPTargetKey.String(sanitizedTarget(r, keep)))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// sanitizeURLTransport records the URL of the request with the query
+// sanitized as the http.url attribute of the span of the request, replacing
+// the one recorded by the otelhttp.Transport.
+type sanitizeURLTransport struct {
+	base http.RoundTripper
+	keep map[string]struct{}
+}
+
+func (t *sanitizeURLTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+		span.SetAttributes(semconv.HTTPURLKey.String(sanitizedURL(r, t.keep)))
+	}
+	return t.base.RoundTrip(r)
+}
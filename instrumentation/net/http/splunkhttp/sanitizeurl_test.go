@@ -0,0 +1,172 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+func TestSanitizeQuery(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		query string
+		keep  []string
+		want  string
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:  "redact all",
+			query: "token=secret&email=bob%40example.com",
+			want:  "token=REDACTED&email=REDACTED",
+		},
+		{
+			desc:  "allowlist",
+			query: "q=shoes&page=2&token=secret",
+			keep:  []string{"page", "q"},
+			want:  "q=shoes&page=2&token=REDACTED",
+		},
+		{
+			desc:  "encoded name",
+			query: "sort%5Bby%5D=name&api_key=secret",
+			keep:  []string{"sort[by]"},
+			want:  "sort%5Bby%5D=name&api_key=REDACTED",
+		},
+		{
+			desc:  "repeated",
+			query: "id=1&id=2",
+			want:  "id=REDACTED&id=REDACTED",
+		},
+		{
+			desc:  "no value",
+			query: "debug&token=",
+			want:  "debug&token=REDACTED",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			keep := make(map[string]struct{}, len(tc.keep))
+			for _, k := range tc.keep {
+				keep[k] = struct{}{}
+			}
+			assert.Equal(t, tc.want, sanitizeQuery(tc.query, keep))
+		})
+	}
+}
+
+// attrValue returns the value of the attribute with key, or false if it is
+// not recorded.
+func attrValue(attrs []attribute.KeyValue, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestWithSanitizeURLHandler(t *testing.T) {
+	testCases := []struct {
+		desc string
+		opts []Option
+		want string
+	}{
+		{
+			desc: "default",
+			opts: []Option{WithSanitizeURL()},
+			want: "/search?q=REDACTED&page=REDACTED&token=REDACTED",
+		},
+		{
+			desc: "allowlist",
+			opts: []Option{WithSanitizeURL("q"), WithSanitizeURL("page")},
+			want: "/search?q=shoes&page=2&token=REDACTED",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+			opts := append([]Option{WithOTelOpts(otelhttp.WithTracerProvider(tp))}, tc.opts...)
+			handler := NewHandlerWithNamer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), defaultName, opts...)
+
+			r := httptest.NewRequest(http.MethodGet, "/search?q=shoes&page=2&token=secret", http.NoBody)
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			ended := sr.Ended()
+			require.Len(t, ended, 1)
+			got, ok := attrValue(ended[0].Attributes(), semconv.HTTPTargetKey)
+			require.True(t, ok, "http.target must be recorded")
+			assert.Equal(t, tc.want, got.AsString())
+		})
+	}
+}
+
+func TestWithSanitizeURLTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.URL.Query().Get("token"), "the request must not be modified")
+	}))
+	defer srv.Close()
+
+	sr := tracetest.NewSpanRecorder()
+	client := &http.Client{Transport: NewTransport(nil,
+		WithSanitizeURL("page"),
+		WithOTelOpts(otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr)))),
+	)}
+
+	resp, err := client.Get(srv.URL + "/search?page=2&token=secret")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	ended := sr.Ended()
+	require.Len(t, ended, 1)
+	var urls []string
+	for _, kv := range ended[0].Attributes() {
+		if kv.Key == semconv.HTTPURLKey {
+			urls = append(urls, kv.Value.AsString())
+		}
+	}
+	assert.Equal(t, []string{srv.URL + "/search?page=2&token=REDACTED"}, urls, "http.url must be replaced")
+}
+
+func TestWithoutSanitizeURLTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	sr := tracetest.NewSpanRecorder()
+	client := &http.Client{Transport: NewTransport(nil,
+		WithOTelOpts(otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr)))),
+	)}
+
+	resp, err := client.Get(srv.URL + "/search?token=secret")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	ended := sr.Ended()
+	require.Len(t, ended, 1)
+	got, ok := attrValue(ended[0].Attributes(), semconv.HTTPURLKey)
+	require.True(t, ok)
+	assert.Equal(t, srv.URL+"/search?token=secret", got.AsString())
+}
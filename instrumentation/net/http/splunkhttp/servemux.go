@@ -0,0 +1,86 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// patternKey is the context key of the pattern matched by the ServeMux
+// wrapped by NewServeMuxHandler.
+type patternKey struct{}
+
+// NewServeMuxHandler wraps the passed mux with an otelhttp.Handler and the
+// Splunk specific instrumentation of NewHandler. The span of each request is
+// named after the request method and the route of the pattern matched by mux
+// (e.g. "GET /users/{id}"), and its http.route attribute is set to the route.
+// The route is the path of the pattern, i.e. without its method and host. If
+// no pattern is matched (e.g. a 404 Not Found response), the span is named
+// "HTTP " followed by the request method and the http.route attribute is not
+// set. The pattern is not matched if the Go 1.21 ServeMux behavior is
+// enabled with the GODEBUG httpmuxgo121=1 setting (e.g. by default if the
+// main module declares a Go version older than 1.22).
+//
+// The otelhttp options passed with WithOTelOpts are used to create the
+// otelhttp.Handler. This function requires Go 1.23 or later.
+func NewServeMuxHandler(mux *http.ServeMux, opts ...Option) http.Handler {
+	// The mux sets the pattern of the request it receives, which can be a
+	// copy of the one handled by the instrumentation. Store it in the
+	// request context instead.
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if p, ok := r.Context().Value(patternKey{}).(*string); ok {
+			*p = r.Pattern
+		}
+	}))
+	opts = append([]Option{WithRouteFunc(serveMuxRoute)}, opts...)
+	handler = NewHandlerWithNamer(handler, serveMuxSpanName, opts...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), patternKey{}, new(string)))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// serveMuxSpanName returns the request method followed by the route of the
+// pattern matched by the ServeMux, or an empty string if there is none.
+func serveMuxSpanName(r *http.Request) string {
+	if route := serveMuxRoute(r); route != "" {
+		return r.Method + " " + route
+	}
+	return ""
+}
+
+// serveMuxRoute returns the route of the pattern matched by the ServeMux, or
+// an empty string if there is none. A pattern has the
+// "[METHOD ][HOST]/[PATH]" form, the route is its path.
+func serveMuxRoute(r *http.Request) string {
+	p, ok := r.Context().Value(patternKey{}).(*string)
+	if !ok || *p == "" {
+		return ""
+	}
+	route := *p
+	if i := strings.IndexAny(route, " \t"); i >= 0 {
+		route = strings.TrimLeft(route[i:], " \t")
+	}
+	if i := strings.IndexByte(route, '/'); i >= 0 {
+		return route[i:]
+	}
+	return ""
+}
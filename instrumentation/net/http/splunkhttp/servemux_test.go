@@ -0,0 +1,127 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+
+// The module declares an older Go version, which defaults to the Go 1.21
+// ServeMux behavior without pattern matching.
+//
+//go:debug httpmuxgo121=0
+
+package splunkhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+func TestNewServeMuxHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	mux.HandleFunc("GET /users/{id}", noop)
+	mux.HandleFunc("/static/", noop)
+	mux.HandleFunc("POST example.com/items/{id...}", noop)
+
+	testCases := []struct {
+		desc      string
+		method    string
+		target    string
+		wantName  string
+		wantRoute []attribute.KeyValue
+	}{
+		{
+			desc:      "method pattern",
+			method:    http.MethodGet,
+			target:    "/users/42",
+			wantName:  "GET /users/{id}",
+			wantRoute: []attribute.KeyValue{attribute.String("http.route", "/users/{id}")},
+		},
+		{
+			desc:      "pattern without method",
+			method:    http.MethodPut,
+			target:    "/static/app.js",
+			wantName:  "PUT /static/",
+			wantRoute: []attribute.KeyValue{attribute.String("http.route", "/static/")},
+		},
+		{
+			desc:      "host pattern",
+			method:    http.MethodPost,
+			target:    "http://example.com/items/a/b",
+			wantName:  "POST /items/{id...}",
+			wantRoute: []attribute.KeyValue{attribute.String("http.route", "/items/{id...}")},
+		},
+		{
+			desc:     "unmatched",
+			method:   http.MethodGet,
+			target:   "/unknown",
+			wantName: "HTTP GET",
+		},
+		{
+			desc:     "method not allowed",
+			method:   http.MethodDelete,
+			target:   "/users/42",
+			wantName: "HTTP DELETE",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			handler := NewServeMuxHandler(mux,
+				WithOTelOpts(otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr)))),
+			)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tc.method, tc.target, http.NoBody))
+
+			require.Len(t, sr.Ended(), 1)
+			span := sr.Ended()[0]
+			assert.Equal(t, tc.wantName, span.Name())
+			var got []attribute.KeyValue
+			for _, kv := range span.Attributes() {
+				if kv.Key == "http.route" {
+					got = append(got, kv)
+				}
+			}
+			assert.Equal(t, tc.wantRoute, got)
+			assert.NotEmpty(t, w.Header().Get("Server-Timing"), "should enable the Splunk specific instrumentation")
+		})
+	}
+}
+
+func TestNewServeMuxHandlerWithMetrics(t *testing.T) {
+	reader := metric.NewManualReader()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	handler := NewServeMuxHandler(mux, WithMetrics(metric.NewMeterProvider(metric.WithReader(reader))))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", http.NoBody))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", http.NoBody))
+
+	var routes []string
+	for _, dp := range histograms(t, reader)["http.server.duration"] {
+		v, _ := dp.Attributes.Value(semconv.HTTPRouteKey)
+		routes = append(routes, v.AsString())
+	}
+	assert.ElementsMatch(t, []string{"/users/{id}", ""}, routes)
+}
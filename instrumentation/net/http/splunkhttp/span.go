@@ -0,0 +1,39 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartSpan starts a span named name in ctx, e.g. to trace a section of a
+// handler as a child of the span of the request. The span is started with
+// the tracer named after this package, from the TracerProvider of the span
+// in ctx (i.e. the one used by the handler returned by NewHandler), or the
+// global TracerProvider if ctx has no valid span. The span must be ended by
+// the caller.
+//
+//	ctx, span := splunkhttp.StartSpan(r.Context(), "load user")
+//	defer span.End()
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tp := otel.GetTracerProvider()
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		tp = span.TracerProvider()
+	}
+	return tp.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
@@ -0,0 +1,67 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceapi "go.opentelemetry.io/otel/trace"
+)
+
+func TestStartSpan(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+	handler := NewHandlerWithNamer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := StartSpan(r.Context(), "section", traceapi.WithAttributes(attribute.String("key", "value")))
+		span.End()
+	}), defaultName, WithOTelOpts(otelhttp.WithTracerProvider(tp)))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "section", child.Name())
+	assert.Equal(t, instrumentationName, child.InstrumentationScope().Name)
+	assert.Equal(t, traceapi.SpanKindInternal, child.SpanKind())
+	assert.Contains(t, child.Attributes(), attribute.String("key", "value"))
+	assert.Equal(t, traceapi.SpanKindServer, server.SpanKind())
+	assert.Equal(t, server.SpanContext().TraceID(), child.SpanContext().TraceID())
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID(), "section must be a child of the server span")
+}
+
+func TestStartSpanGlobalTracerProvider(t *testing.T) {
+	orig := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(orig) })
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr)))
+
+	ctx, span := StartSpan(context.Background(), "root")
+	span.End()
+
+	assert.True(t, traceapi.SpanFromContext(ctx).SpanContext().Equal(span.SpanContext()), "span must be in the returned context")
+	require.Len(t, sr.Ended(), 1)
+	assert.False(t, sr.Ended()[0].Parent().IsValid(), "root span")
+}
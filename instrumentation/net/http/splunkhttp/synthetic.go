@@ -0,0 +1,110 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// syntheticAttr is the attribute recorded on the server span of synthetic
+// requests.
+var syntheticAttr = attribute.Bool("synthetic", true)
+
+// defaultSyntheticUserAgents are the User-Agent substrings of common
+// monitoring probes and health checkers detected by
+// SyntheticUserAgentDetector if none is passed.
+var defaultSyntheticUserAgents = []string{
+	"Splunk Synthetics",
+	"Pingdom",
+	"UptimeRobot",
+	"StatusCake",
+	"Site24x7",
+	"Datadog/Synthetics",
+	"NewRelicPinger",
+	"kube-probe",
+	"ELB-HealthChecker",
+	"GoogleHC",
+	"Consul Health Check",
+}
+
+// SyntheticUserAgentDetector returns a function detecting the requests with a
+// User-Agent header containing any of the passed substrings, ignoring case.
+// If no substring is passed, the User-Agents of common monitoring probes and
+// health checkers (e.g. Splunk Synthetics, Pingdom, and kube-probe) are
+// detected.
+func SyntheticUserAgentDetector(substrings ...string) func(*http.Request) bool {
+	if len(substrings) == 0 {
+		substrings = defaultSyntheticUserAgents
+	}
+	lower := make([]string, len(substrings))
+	for i, s := range substrings {
+		lower[i] = strings.ToLower(s)
+	}
+	return func(r *http.Request) bool {
+		ua := strings.ToLower(r.UserAgent())
+		if ua == "" {
+			return false
+		}
+		for _, s := range lower {
+			if s != "" && strings.Contains(ua, s) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// syntheticKey is the context key marking the synthetic requests.
+type syntheticKey struct{}
+
+// markSyntheticMiddleware wraps the passed handler, functioning like
+// middleware. It marks the context of the requests detected as synthetic
+// before the span is started by the wrapped handler.
+func markSyntheticMiddleware(handler http.Handler, detect func(*http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if detect(r) {
+			r = r.WithContext(context.WithValue(r.Context(), syntheticKey{}, true))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// syntheticMiddleware wraps the passed handler, functioning like middleware.
+// It records the synthetic attribute on the span in the request context if
+// the request is marked or detected as synthetic.
+func syntheticMiddleware(handler http.Handler, detect func(*http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			if Synthetic(r.Context()) || detect(r) {
+				span.SetAttributes(syntheticAttr)
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Synthetic returns if the request handled in ctx is marked as synthetic by
+// a handler configured with WithSyntheticDetector. Use it with the sampler of
+// the Splunk distribution (distro.NewSyntheticSampler) to sample the spans of
+// the synthetic requests differently.
+func Synthetic(ctx context.Context) bool {
+	v, _ := ctx.Value(syntheticKey{}).(bool)
+	return v
+}
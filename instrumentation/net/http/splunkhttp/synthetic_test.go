@@ -0,0 +1,157 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// syntheticSampler delegates the decision for the spans of the requests
+// marked as synthetic by the handler to synthetic, as the sampler of the
+// Splunk distribution does, and otherwise to the wrapped Sampler.
+type syntheticSampler struct {
+	trace.Sampler
+	synthetic trace.Sampler
+}
+
+func (s syntheticSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if Synthetic(p.ParentContext) {
+		return s.synthetic.ShouldSample(p)
+	}
+	return s.Sampler.ShouldSample(p)
+}
+
+func TestWithSyntheticDetector(t *testing.T) {
+	byHeader := func(r *http.Request) bool { return r.Header.Get("X-Synthetic") != "" }
+
+	testCases := []struct {
+		desc      string
+		opts      []Option
+		userAgent string
+		headers   map[string]string
+		want      bool
+	}{
+		{
+			desc:      "default probe",
+			opts:      []Option{WithSyntheticDetector(nil)},
+			userAgent: "kube-probe/1.27",
+			want:      true,
+		},
+		{
+			desc:      "default case insensitive",
+			opts:      []Option{WithSyntheticDetector(nil)},
+			userAgent: "Mozilla/5.0 (compatible; pingdom.com_bot_version_1.4)",
+			want:      true,
+		},
+		{
+			desc:      "default user",
+			opts:      []Option{WithSyntheticDetector(nil)},
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64) Firefox/115.0",
+		},
+		{
+			desc:      "custom user agents",
+			opts:      []Option{WithSyntheticDetector(SyntheticUserAgentDetector("my-probe"))},
+			userAgent: "my-probe/1.0",
+			want:      true,
+		},
+		{
+			desc:      "custom user agents ignore default",
+			opts:      []Option{WithSyntheticDetector(SyntheticUserAgentDetector("my-probe"))},
+			userAgent: "kube-probe/1.27",
+		},
+		{
+			desc:    "custom predicate",
+			opts:    []Option{WithSyntheticDetector(byHeader)},
+			headers: map[string]string{"X-Synthetic": "1"},
+			want:    true,
+		},
+		{
+			desc:      "disabled",
+			userAgent: "kube-probe/1.27",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := trace.NewTracerProvider(trace.WithSpanProcessor(sr))
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler = NewHandler(handler, tc.opts...)
+			handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(tp))
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("User-Agent", tc.userAgent)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Len(t, sr.Ended(), 1)
+			attrs := sr.Ended()[0].Attributes()
+			if tc.want {
+				assert.Contains(t, attrs, attribute.Bool("synthetic", true))
+			} else {
+				for _, kv := range attrs {
+					assert.NotEqual(t, attribute.Key("synthetic"), kv.Key, "synthetic attribute must not be set")
+				}
+			}
+		})
+	}
+}
+
+func TestWithSyntheticDetectorSampler(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(
+		trace.WithSampler(syntheticSampler{Sampler: trace.AlwaysSample(), synthetic: trace.NeverSample()}),
+		trace.WithSpanProcessor(sr),
+	)
+	handler := NewHandlerWithNamer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), defaultName,
+		WithOTelOpts(otelhttp.WithTracerProvider(tp)),
+		WithSyntheticDetector(nil),
+	)
+
+	for _, ua := range []string{"kube-probe/1.27", "curl/8.0", "ELB-HealthChecker/2.0"} {
+		r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		r.Header.Set("User-Agent", ua)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	require.Len(t, sr.Ended(), 1, "only the user request must be sampled")
+	for _, kv := range sr.Ended()[0].Attributes() {
+		assert.NotEqual(t, attribute.Key("synthetic"), kv.Key, "synthetic attribute must not be set")
+	}
+}
+
+func TestSynthetic(t *testing.T) {
+	assert.False(t, Synthetic(context.Background()))
+
+	var got bool
+	handler := markSyntheticMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = Synthetic(r.Context())
+	}), SyntheticUserAgentDetector())
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	r.Header.Set("User-Agent", "kube-probe/1.27")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, got, "request must be marked")
+}
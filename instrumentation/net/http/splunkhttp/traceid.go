@@ -0,0 +1,58 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDFromRequest returns the hex-encoded ID of the trace of the span
+// recording the request, e.g. to include it in an error response so that the
+// request can be looked up. It returns false if there is no recording span in
+// the request context (e.g. the request is not traced or not sampled).
+func TraceIDFromRequest(r *http.Request) (string, bool) {
+	span := trace.SpanFromContext(r.Context())
+	if !span.IsRecording() {
+		return "", false
+	}
+	return span.SpanContext().TraceID().String(), true
+}
+
+// errorBody is the JSON body written by JSONError.
+type errorBody struct {
+	Error   string `json:"error"`
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+// JSONError replies to the request with the code and a JSON body holding the
+// error message and, if the request is recorded by a span, the trace ID
+// returned by TraceIDFromRequest (e.g. {"error":"internal error",
+// "trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}). As for http.Error, the
+// handler should not write anything else to w.
+func JSONError(w http.ResponseWriter, r *http.Request, msg string, code int) {
+	body := errorBody{Error: msg}
+	body.TraceID, _ = TraceIDFromRequest(r)
+
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	// The error can only be a write error, which cannot be reported.
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,83 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace"
+	traceapi "go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceIDFromRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	_, ok := TraceIDFromRequest(r)
+	assert.False(t, ok, "request without span")
+
+	sc := traceapi.NewSpanContext(traceapi.SpanContextConfig{
+		TraceID:    traceapi.TraceID{0x01},
+		SpanID:     traceapi.SpanID{0x01},
+		TraceFlags: traceapi.FlagsSampled,
+	})
+	r = r.WithContext(traceapi.ContextWithSpanContext(context.Background(), sc))
+	_, ok = TraceIDFromRequest(r)
+	assert.False(t, ok, "non-recording span")
+
+	ctx, span := trace.NewTracerProvider().Tracer(t.Name()).Start(context.Background(), "span")
+	defer span.End()
+	got, ok := TraceIDFromRequest(r.WithContext(ctx))
+	require.True(t, ok, "recording span")
+	assert.Equal(t, span.SpanContext().TraceID().String(), got)
+}
+
+func TestJSONError(t *testing.T) {
+	ctx, span := trace.NewTracerProvider().Tracer(t.Name()).Start(context.Background(), "span")
+	defer span.End()
+
+	testCases := []struct {
+		desc string
+		ctx  context.Context
+		want string
+	}{
+		{
+			desc: "traced",
+			ctx:  ctx,
+			want: `{"error":"internal error","trace_id":"` + span.SpanContext().TraceID().String() + `"}`,
+		},
+		{
+			desc: "not traced",
+			ctx:  context.Background(),
+			want: `{"error":"internal error"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", http.NoBody).WithContext(tc.ctx)
+			w := httptest.NewRecorder()
+			w.Header().Set("Content-Length", "42")
+			JSONError(w, r, "internal error", http.StatusInternalServerError)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+			assert.Empty(t, w.Header().Get("Content-Length"))
+			assert.JSONEq(t, tc.want, w.Body.String())
+		})
+	}
+}
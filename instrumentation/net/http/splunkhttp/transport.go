@@ -0,0 +1,125 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of the server trace context read from the Server-Timing
+// response header.
+const (
+	linkTraceIDKey = attribute.Key("link.traceId")
+	linkSpanIDKey  = attribute.Key("link.spanId")
+)
+
+// NewTransport wraps the passed RoundTripper with an otelhttp.Transport
+// configured with the otelhttp options passed using WithOTelOpts. If base is
+// nil, http.DefaultTransport is used.
+//
+// The trace context returned by the server in traceparent form as
+// Server-Timing response header (e.g. by a handler wrapped with NewHandler,
+// see WithServerTimingHeader to use a custom header)
+// is recorded as the link.traceId and link.spanId attributes of the client
+// span. This allows correlating the client and server traces when the trace
+// context is not propagated end-to-end.
+func NewTransport(base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cfg := newConfig(opts...)
+	var rt http.RoundTripper = &serverTimingTransport{base: base, header: cfg.ServerTimingHeader}
+	if cfg.SanitizeURL {
+		rt = &sanitizeURLTransport{base: rt, keep: cfg.SanitizeURLKeep}
+	}
+	return otelhttp.NewTransport(rt, cfg.otelOpts()...)
+}
+
+// serverTimingTransport records the server trace context from the
+// Server-Timing response header on the span of the request.
+type serverTimingTransport struct {
+	base   http.RoundTripper
+	header string
+}
+
+func (t *serverTimingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		return resp, err
+	}
+
+	span := trace.SpanFromContext(r.Context())
+	if !span.IsRecording() {
+		return resp, nil
+	}
+	if sc, ok := serverTimingTraceParent(resp.Header.Values(t.header)); ok {
+		span.SetAttributes(
+			linkTraceIDKey.String(sc.TraceID().String()),
+			linkSpanIDKey.String(sc.SpanID().String()),
+		)
+	}
+	return resp, nil
+}
+
+// serverTimingTraceParent returns the span context of the traceparent metric
+// of the Server-Timing header values (https://www.w3.org/TR/server-timing/).
+func serverTimingTraceParent(values []string) (trace.SpanContext, bool) {
+	for _, v := range values {
+		for _, metric := range strings.Split(v, ",") {
+			params := strings.Split(metric, ";")
+			if strings.TrimSpace(params[0]) != "traceparent" {
+				continue
+			}
+			for _, p := range params[1:] {
+				name, value, ok := strings.Cut(strings.TrimSpace(p), "=")
+				if !ok || strings.TrimSpace(name) != "desc" {
+					continue
+				}
+				if sc, ok := parseTraceParent(strings.Trim(strings.TrimSpace(value), `"`)); ok {
+					return sc, true
+				}
+			}
+		}
+	}
+	return trace.SpanContext{}, false
+}
+
+// parseTraceParent parses the version 00 traceparent
+// (https://www.w3.org/TR/trace-context/#traceparent-header).
+func parseTraceParent(s string) (trace.SpanContext, bool) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return trace.SpanContext{}, false
+	}
+	traceID, err := trace.TraceIDFromHex(parts[1])
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	spanID, err := trace.SpanIDFromHex(parts[2])
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	})
+	return sc, sc.IsValid()
+}
@@ -0,0 +1,140 @@
+// Copyright Splunk Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTransportServerTiming(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		testNewTransportServerTiming(t)
+	})
+	t.Run("WithServerTimingHeader", func(t *testing.T) {
+		testNewTransportServerTiming(t, WithServerTimingHeader("X-Trace-Timing"))
+	})
+}
+
+func testNewTransportServerTiming(t *testing.T, opts ...Option) {
+	serverSR := tracetest.NewSpanRecorder()
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler = NewHandler(handler, opts...)
+	handler = otelhttp.NewHandler(handler, "server", otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(serverSR))))
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	clientSR := tracetest.NewSpanRecorder()
+	opts = append(opts, WithOTelOpts(
+		otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(clientSR))),
+	))
+	client := &http.Client{Transport: NewTransport(nil, opts...)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Len(t, serverSR.Ended(), 1)
+	serverSpan := serverSR.Ended()[0].SpanContext()
+	require.Len(t, clientSR.Ended(), 1, "WithOTelOpts should set the TracerProvider")
+	attrs := clientSR.Ended()[0].Attributes()
+	assert.Contains(t, attrs, attribute.String("link.traceId", serverSpan.TraceID().String()))
+	assert.Contains(t, attrs, attribute.String("link.spanId", serverSpan.SpanID().String()))
+}
+
+func TestServerTimingTraceParent(t *testing.T) {
+	const (
+		traceID = "0af7651916cd43dd8448eb211c80319c"
+		spanID  = "b7ad6b7169203331"
+	)
+	testCases := []struct {
+		desc   string
+		values []string
+		ok     bool
+	}{
+		{
+			desc:   "traceparent",
+			values: []string{`traceparent;desc="00-` + traceID + `-` + spanID + `-01"`},
+			ok:     true,
+		},
+		{
+			desc:   "multiple metrics",
+			values: []string{`cache;desc="Cache Read";dur=23.2, traceparent;desc="00-` + traceID + `-` + spanID + `-01"`},
+			ok:     true,
+		},
+		{
+			desc:   "multiple headers",
+			values: []string{"total;dur=123.4", `traceparent;desc=00-` + traceID + `-` + spanID + `-00`},
+			ok:     true,
+		},
+		{
+			desc: "missing",
+		},
+		{
+			desc:   "no traceparent",
+			values: []string{"total;dur=123.4"},
+		},
+		{
+			desc:   "unsupported version",
+			values: []string{`traceparent;desc="01-` + traceID + `-` + spanID + `-01"`},
+		},
+		{
+			desc:   "invalid trace ID",
+			values: []string{`traceparent;desc="00-00000000000000000000000000000000-` + spanID + `-01"`},
+		},
+		{
+			desc:   "malformed",
+			values: []string{`traceparent;desc="00-` + traceID + `"`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sc, ok := serverTimingTraceParent(tc.values)
+			require.Equal(t, tc.ok, ok)
+			if ok {
+				assert.Equal(t, traceID, sc.TraceID().String())
+				assert.Equal(t, spanID, sc.SpanID().String())
+			}
+		})
+	}
+}
+
+func TestNewTransportWithFilter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	sr := tracetest.NewSpanRecorder()
+	client := &http.Client{Transport: NewTransport(nil,
+		WithFilter(func(r *http.Request) bool { return r.URL.Path != "/healthz" }),
+		WithOTelOpts(otelhttp.WithTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(sr)))),
+	)}
+
+	resp, err := client.Get(srv.URL + "/healthz")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Empty(t, sr.Ended(), "should not create a span for an excluded path")
+
+	resp, err = client.Get(srv.URL + "/api")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Len(t, sr.Ended(), 1, "should create a span")
+}